@@ -0,0 +1,138 @@
+package httpx
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const responseKey = "httpx.response"
+
+// AccessLogEntry is a request logged by AccessLog
+type AccessLogEntry struct {
+	Time      time.Time
+	Method    string
+	Path      string
+	Route     string
+	Status    int
+	Bytes     int64
+	Latency   time.Duration
+	RemoteIP  string
+	UserAgent string
+	RequestID string
+	// ErrorCode is the code of the ErrorJSONCode returned by the handler, if any
+	ErrorCode string
+}
+
+// AccessLogField is a field of AccessLogEntry that SlogSink logs
+type AccessLogField string
+
+// Fields of the access log
+const (
+	FieldMethod    AccessLogField = "method"
+	FieldPath      AccessLogField = "path"
+	FieldRoute     AccessLogField = "route"
+	FieldStatus    AccessLogField = "status"
+	FieldBytes     AccessLogField = "bytes"
+	FieldLatency   AccessLogField = "latency"
+	FieldRemoteIP  AccessLogField = "remote_ip"
+	FieldUserAgent AccessLogField = "user_agent"
+	FieldRequestID AccessLogField = "request_id"
+	FieldErrorCode AccessLogField = "error_code"
+)
+
+// AccessLog returns a middleware that sends an AccessLogEntry to sink after each request. It wraps http.Handlers
+// so it sees every response, including the ones of httpx handlers and routers like Mux.
+// Example:
+//
+//	http.ListenAndServe(":8080", httpx.AccessLog(httpx.SlogSink(slog.Default()))(mux))
+func AccessLog(sink func(AccessLogEntry)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			r = withValues(r)
+			sw := wrapWriter(w)
+			next.ServeHTTP(sw, r)
+
+			entry := AccessLogEntry{
+				Time:      start,
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Status:    sw.status,
+				Bytes:     sw.written,
+				Latency:   time.Since(start),
+				RemoteIP:  ClientIP(r, TrustedProxies...),
+				UserAgent: r.UserAgent(),
+				RequestID: RequestID(r),
+			}
+
+			if res, ok := Get[*Response](r, responseKey); ok {
+				entry.ErrorCode = res.ErrorCode()
+			}
+
+			entry.Route = Route(r)
+			sink(entry)
+		})
+	}
+}
+
+// SlogSink returns an access log sink that logs the fields with l, all of them if none is passed.
+// Failed requests are logged with the warn level if they are client errors and error level if they are server errors.
+func SlogSink(l *slog.Logger, fields ...AccessLogField) func(AccessLogEntry) {
+	if len(fields) == 0 {
+		fields = []AccessLogField{
+			FieldMethod, FieldPath, FieldRoute, FieldStatus, FieldBytes, FieldLatency,
+			FieldRemoteIP, FieldUserAgent, FieldRequestID, FieldErrorCode,
+		}
+	}
+
+	return func(e AccessLogEntry) {
+		attrs := make([]slog.Attr, 0, len(fields))
+		for _, field := range fields {
+			if attr := e.attr(field); attr.Key != "" {
+				attrs = append(attrs, attr)
+			}
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case e.Status >= 500:
+			level = slog.LevelError
+		case e.Status >= 400:
+			level = slog.LevelWarn
+		}
+
+		l.LogAttrs(context.Background(), level, "request", attrs...)
+	}
+}
+
+// attr returns the field as an attribute, or an empty one if it has no value
+func (e AccessLogEntry) attr(field AccessLogField) slog.Attr {
+	key := string(field)
+	switch field {
+	case FieldMethod:
+		return slog.String(key, e.Method)
+	case FieldPath:
+		return slog.String(key, e.Path)
+	case FieldStatus:
+		return slog.Int(key, e.Status)
+	case FieldBytes:
+		return slog.Int64(key, e.Bytes)
+	case FieldLatency:
+		return slog.Duration(key, e.Latency)
+	}
+
+	value := map[AccessLogField]string{
+		FieldRoute:     e.Route,
+		FieldRemoteIP:  e.RemoteIP,
+		FieldUserAgent: e.UserAgent,
+		FieldRequestID: e.RequestID,
+		FieldErrorCode: e.ErrorCode,
+	}[field]
+	if value == "" {
+		return slog.Attr{}
+	}
+
+	return slog.String(key, value)
+}
@@ -0,0 +1,84 @@
+package httpx
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const afterKey = "httpx.after"
+
+// AfterInfo describes a finished request to the after hooks
+type AfterInfo struct {
+	// Status is the status sent to the client, and Bytes the length of the body sent
+	Status int
+	Bytes  int64
+	// Duration is the time since the request reached the handler
+	Duration time.Duration
+	// Err is the error returned by the handler, nil when it succeeded. Use errors.Is and errors.As to inspect it.
+	Err error
+	// Response is the response written for Err, nil when there's no error
+	Response *Response
+	// Suppressed is the number of identical errors that SampleErrors didn't pass to the hook before this one
+	Suppressed int64
+}
+
+// AfterFunc is a hook that runs once the response of a httpx handler is written, see OnAfter
+type AfterFunc func(w http.ResponseWriter, r *http.Request, info AfterInfo)
+
+var afterHooks atomic.Pointer[[]AfterFunc]
+
+// OnAfter adds hooks that run after every httpx handler, successful or not, in the order they were added
+// and after DefaultAfterMiddleware. Unlike DefaultAfterMiddleware, logging and metrics can each add their own.
+// Example:
+//
+//	httpx.OnAfter(func(w http.ResponseWriter, r *http.Request, info httpx.AfterInfo) {
+//		requestDuration.WithLabelValues(httpx.Route(r), strconv.Itoa(info.Status)).Observe(info.Duration.Seconds())
+//	})
+func OnAfter(fns ...AfterFunc) {
+	addHooks(&afterHooks, fns)
+}
+
+// WithAfter adds hooks that only run after the handlers of a group or route, after the OnAfter ones.
+// Hooks of outer groups run before the ones of inner groups and routes.
+// Example:
+//
+//	api := httpx.NewGroup(mux, "/v1", httpx.WithAfter(auditLog))
+func WithAfter(fns ...AfterFunc) Option {
+	return WithMiddleware(func(next Handler) Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			return next(w, After(r, fns...))
+		}
+	})
+}
+
+// After adds hooks that only run after the handler of this request, like WithAfter does for a route.
+// It's meant for middleware that need to see the written response, use the returned request.
+func After(r *http.Request, fns ...AfterFunc) *http.Request {
+	hooks, _ := Get[[]AfterFunc](r, afterKey)
+	return Set(r, afterKey, append(hooks[:len(hooks):len(hooks)], fns...))
+}
+
+// fireAfter runs the after hooks of the request
+func fireAfter(sw *statusWriter, r *http.Request, err error, start time.Time) {
+	global := afterHooks.Load()
+	local, _ := Get[[]AfterFunc](r, afterKey)
+	if global == nil && len(local) == 0 {
+		return
+	}
+
+	info := AfterInfo{Status: sw.status, Bytes: sw.written, Duration: time.Since(start), Err: err}
+	if err != nil {
+		info.Response, _ = Get[*Response](r, responseKey)
+	}
+
+	if global != nil {
+		for _, fn := range *global {
+			fn(sw, r, info)
+		}
+	}
+
+	for _, fn := range local {
+		fn(sw, r, info)
+	}
+}
@@ -0,0 +1,97 @@
+// package audit records who did what on compliance-sensitive endpoints, optionally with a redacted copy
+// of the request and response bodies.
+package audit
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gabivlj/httpx"
+)
+
+// Entry is an audited request
+type Entry struct {
+	Time      time.Time
+	Method    string
+	Path      string
+	Route     string
+	RequestID string
+	// Principal is the httpx.Principal of the request, nil if it wasn't authenticated
+	Principal any
+	Status    int
+	ErrorCode string
+	Duration  time.Duration
+	// Bodies are the redacted bodies when Config.Bodies is set, see httpx.CaptureBodies
+	Bodies httpx.Bodies
+}
+
+// Config configures the audit middleware
+type Config struct {
+	// Sink receives the entries, it's called after the response is written
+	Sink func(Entry)
+	// Bodies records the request and response bodies, redacted like Capture configures them
+	Bodies bool
+	// Capture configures how bodies are captured, its Sink is ignored. Card numbers are masked
+	// unless Capture is set.
+	Capture *httpx.CaptureOptions
+}
+
+// Auditor records the requests to a sink
+type Auditor struct {
+	cfg Config
+}
+
+// New returns an Auditor for the config, it panics without a Sink
+func New(cfg Config) *Auditor {
+	if cfg.Sink == nil {
+		panic("httpx/audit: Config.Sink is required")
+	}
+
+	if cfg.Capture == nil {
+		cfg.Capture = &httpx.CaptureOptions{Cards: true}
+	}
+
+	return &Auditor{cfg: cfg}
+}
+
+// Middleware sends an Entry to the sink once the response of each request is written. Put it after the
+// authentication middleware so the entries have the principal.
+// Example:
+//
+//	auditor := audit.New(audit.Config{Sink: auditLog, Bodies: true})
+//	admin := mux.Group("/admin", httpx.WithMiddleware(httpx.BearerAuth(validate), auditor.Middleware))
+func (a *Auditor) Middleware(next httpx.Handler) httpx.Handler {
+	if a.cfg.Bodies {
+		capture := *a.cfg.Capture
+		capture.Sink = a.record
+		return httpx.CaptureBodies(capture)(next)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		inner := r
+		return next(w, httpx.After(r, func(_ http.ResponseWriter, _ *http.Request, info httpx.AfterInfo) {
+			a.record(inner, info, httpx.Bodies{})
+		}))
+	}
+}
+
+// record sends the entry of the request to the sink, r is the request the middleware got so it has the principal
+func (a *Auditor) record(r *http.Request, info httpx.AfterInfo, bodies httpx.Bodies) {
+	entry := Entry{
+		Time:      time.Now().Add(-info.Duration),
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Route:     httpx.Route(r),
+		RequestID: httpx.RequestID(r),
+		Principal: httpx.Principal(r),
+		Status:    info.Status,
+		Duration:  info.Duration,
+		Bodies:    bodies,
+	}
+
+	if info.Response != nil {
+		entry.ErrorCode = info.Response.ErrorCode()
+	}
+
+	a.cfg.Sink(entry)
+}
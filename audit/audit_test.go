@@ -0,0 +1,121 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gabivlj/httpx"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       Config
+		token     string
+		body      string
+		principal any
+		status    int
+		errorCode string
+		request   string
+		response  string
+	}{
+		{name: "authenticated", token: "alice", body: `{"name": "b"}`, principal: "alice", status: http.StatusOK},
+		{name: "rejected by the handler", token: "alice", body: `{"name": ""}`, principal: "alice", status: http.StatusBadRequest, errorCode: httpx.ErrBadRequest.Code},
+		{name: "unauthenticated", status: http.StatusUnauthorized},
+		{
+			name:      "bodies",
+			cfg:       Config{Bodies: true},
+			token:     "alice",
+			body:      `{"name": "b", "password": "hunter2", "card": "4111 1111 1111 1111"}`,
+			principal: "alice",
+			status:    http.StatusOK,
+			request:   `{"card":"************1111","name":"b","password":"[REDACTED]"}`,
+			response:  `{"name":"b","token":"[REDACTED]"}`,
+		},
+		{
+			name:      "bodies with custom redaction",
+			cfg:       Config{Bodies: true, Capture: &httpx.CaptureOptions{Redact: []string{"name"}}},
+			token:     "alice",
+			body:      `{"name": "b", "card": "4111111111111111"}`,
+			principal: "alice",
+			status:    http.StatusOK,
+			request:   `{"card":"4111111111111111","name":"[REDACTED]"}`,
+			response:  `{"name":"[REDACTED]","token":"t"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entries []Entry
+			tt.cfg.Sink = func(e Entry) { entries = append(entries, e) }
+			auditor := New(tt.cfg)
+			auth := httpx.BearerAuth(func(token string) (any, error) {
+				if token == "" {
+					return nil, errors.New("no token")
+				}
+
+				return token, nil
+			})
+
+			mux := httpx.NewMux(httpx.WithMiddleware(auth, auditor.Middleware))
+			mux.POST("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+				var user struct {
+					Name string `json:"name"`
+				}
+
+				if err := json.NewDecoder(r.Body).Decode(&user); err != nil || user.Name == "" {
+					return httpx.ErrBadRequest.JSON()
+				}
+
+				return httpx.OK(map[string]string{"name": user.Name, "token": "t"})
+			})
+
+			r := httptest.NewRequest(http.MethodPost, "/users/1", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+			if tt.status == http.StatusUnauthorized {
+				if len(entries) != 0 {
+					t.Fatalf("expected no entry for requests rejected before the auditor, got %+v", entries)
+				}
+
+				return
+			}
+
+			if len(entries) != 1 {
+				t.Fatalf("expected an entry, got %d", len(entries))
+			}
+
+			e := entries[0]
+			if e.Method != http.MethodPost || e.Path != "/users/1" || e.Route != "/users/{id}" || e.Principal != tt.principal {
+				t.Fatalf("expected the request and its principal, got %+v", e)
+			}
+
+			if e.Status != tt.status || e.ErrorCode != tt.errorCode || e.Time.IsZero() {
+				t.Fatalf("expected %d %q, got %d %q", tt.status, tt.errorCode, e.Status, e.ErrorCode)
+			}
+
+			if string(e.Bodies.Request) != tt.request || string(e.Bodies.Response) != tt.response {
+				t.Fatalf("expected the bodies %s and %s, got %s and %s", tt.request, tt.response, e.Bodies.Request, e.Bodies.Response)
+			}
+		})
+	}
+}
+
+func TestNewRequiresSink(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected New to panic")
+		}
+	}()
+
+	New(Config{})
+}
@@ -0,0 +1,127 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// UnauthorizedBasic returns ErrUnauthorized as JSON with a Basic challenge for the realm (RFC 7617)
+func UnauthorizedBasic(realm string) *Response {
+	challenge := "Basic realm=" + quote(realm) + `, charset="UTF-8"`
+	return Unauthorized().Headers(map[string]string{"WWW-Authenticate": challenge})
+}
+
+// UnauthorizedBearer returns a Bearer challenge for the realm (RFC 6750). errCode is one of "invalid_request",
+// "invalid_token" or "insufficient_scope", or empty when the request had no token, and desc is a human readable description.
+// Like the RFC says, invalid_request returns ErrBadRequest, insufficient_scope ErrForbidden and the rest ErrUnauthorized.
+func UnauthorizedBearer(realm, errCode, desc string) *Response {
+	params := []string{}
+	if realm != "" {
+		params = append(params, "realm="+quote(realm))
+	}
+
+	if errCode != "" {
+		params = append(params, "error="+quote(errCode))
+	}
+
+	if desc != "" {
+		params = append(params, "error_description="+quote(desc))
+	}
+
+	challenge := "Bearer"
+	if len(params) > 0 {
+		challenge += " " + strings.Join(params, ", ")
+	}
+
+	code := ErrUnauthorized
+	switch errCode {
+	case "invalid_request":
+		code = ErrBadRequest
+	case "insufficient_scope":
+		code = ErrForbidden
+	}
+
+	var extra []any
+	if desc != "" {
+		extra = append(extra, desc)
+	}
+
+	return code.JSON(extra...).Headers(map[string]string{"WWW-Authenticate": challenge})
+}
+
+// quote returns s as a quoted-string
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// AuthRealm is the realm of the challenges sent by BasicAuth and BearerAuth
+var AuthRealm = "httpx"
+
+type principalKey struct{}
+
+// Principal returns the principal stored by BasicAuth (the username) or BearerAuth (the value returned by validate)
+func Principal(r *http.Request) any {
+	return r.Context().Value(principalKey{})
+}
+
+// BasicAuth returns a middleware that checks the Basic credentials of the request with validate, storing the
+// username as the Principal. Requests without valid credentials get UnauthorizedBasic, unless validate returns a httpx.Response.
+func BasicAuth(validate func(user, pass string) error) Middleware {
+	return func(next Handler) Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			user, pass, ok := r.BasicAuth()
+			if !ok {
+				return UnauthorizedBasic(AuthRealm)
+			}
+
+			if err := validate(user, pass); err != nil {
+				if res, ok := err.(*Response); ok {
+					return res
+				}
+
+				return UnauthorizedBasic(AuthRealm)
+			}
+
+			return next(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, user)))
+		}
+	}
+}
+
+// BearerAuth returns a middleware that checks the Bearer token of the request with validate, storing
+// the value it returns as the Principal. Requests without valid tokens get UnauthorizedBearer, unless validate returns a httpx.Response.
+// Example:
+//
+//	auth := httpx.BearerAuth(func(token string) (any, error) {
+//		return sessions.User(token)
+//	})
+//	mux.GET("/me", func(w http.ResponseWriter, r *http.Request) error {
+//		return httpx.OK(httpx.Principal(r).(*User))
+//	}, httpx.WithMiddleware(auth))
+func BearerAuth(validate func(token string) (any, error)) Middleware {
+	return func(next Handler) Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			authorization := r.Header.Get("Authorization")
+			if authorization == "" {
+				return UnauthorizedBearer(AuthRealm, "", "")
+			}
+
+			scheme, token, ok := strings.Cut(authorization, " ")
+			token = strings.TrimSpace(token)
+			if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+				return UnauthorizedBearer(AuthRealm, "invalid_request", "expected a Bearer token")
+			}
+
+			principal, err := validate(token)
+			if err != nil {
+				if res, ok := err.(*Response); ok {
+					return res
+				}
+
+				return UnauthorizedBearer(AuthRealm, "invalid_token", "")
+			}
+
+			return next(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+		}
+	}
+}
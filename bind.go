@@ -0,0 +1,298 @@
+package httpx
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidParameters is the code returned by BindQuery and BindPath, its extra payload lists each invalid parameter.
+var ErrInvalidParameters = NewCode("INVALID_PARAMETERS", http.StatusBadRequest)
+
+// ParameterError is an invalid parameter found binding a request
+type ParameterError struct {
+	Name    string `json:"name"`
+	In      string `json:"in"`
+	Message string `json:"message"`
+}
+
+// BindQuery returns a T with the fields tagged with `query:"name"` set from the query parameters. Values are converted
+// to the type of the field: strings, numbers, bools, time.Time (RFC 3339), time.Duration, encoding.TextUnmarshaler,
+// pointers to them and slices of them (from repeated or comma separated parameters).
+// Missing parameters take the value of the `default:"value"` tag if there's one.
+// Example:
+//
+//	type Search struct {
+//		Query string    `query:"q"`
+//		Page  int       `query:"page" default:"1"`
+//		Tags  []string  `query:"tag"`
+//		Since time.Time `query:"since"`
+//	}
+//
+//	search, err := httpx.BindQuery[Search](r)
+//	if err != nil {
+//		return err // 400 {"code": "INVALID_PARAMETERS", "extra": [{"name": "page", "in": "query", "message": "..."}]}
+//	}
+func BindQuery[T any](r *http.Request) (T, error) {
+	query := r.URL.Query()
+	return bind[T]("query", func(name string) []string { return query[name] })
+}
+
+// BindPath is like BindQuery for the path parameters of Param, with fields tagged with `path:"name"`.
+func BindPath[T any](r *http.Request) (T, error) {
+	return bind[T]("path", func(name string) []string {
+		if value := Param(r, name); value != "" {
+			return []string{value}
+		}
+
+		return nil
+	})
+}
+
+func bind[T any](in string, lookup func(name string) []string) (T, error) {
+	var dst T
+	rv := reflect.ValueOf(&dst).Elem()
+	if err := checkBindable(in, rv.Type()); err != nil {
+		return dst, ErrInternal.JSON(err.Error())
+	}
+
+	var errs []ParameterError
+	for _, field := range reflect.VisibleFields(rv.Type()) {
+		name := field.Tag.Get(in)
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		values := lookup(name)
+		if len(values) == 0 {
+			defaultValue, ok := field.Tag.Lookup("default")
+			if !ok {
+				continue
+			}
+
+			values = []string{defaultValue}
+		}
+
+		if err := setField(fieldByIndex(rv, field.Index), values); err != nil {
+			errs = append(errs, ParameterError{Name: name, In: in, Message: err.Error()})
+		}
+	}
+
+	if len(errs) > 0 {
+		return dst, ErrInvalidParameters.JSON(errs)
+	}
+
+	return dst, nil
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+type bindKey struct {
+	in string
+	t  reflect.Type
+}
+
+// bindChecks caches the result of checkBindable for each type
+var bindChecks sync.Map
+
+// checkBindable returns an error if t isn't a struct whose tagged fields can be bound, it's checked once per type
+// so a struct that can't be bound fails every request with a 500 instead of panicking
+func checkBindable(in string, t reflect.Type) error {
+	key := bindKey{in: in, t: t}
+	if err, ok := bindChecks.Load(key); ok {
+		err, _ := err.(error)
+		return err
+	}
+
+	var err error
+	if t.Kind() != reflect.Struct {
+		err = fmt.Errorf("httpx: can't bind %s parameters into %s, it must be a struct", in, t)
+	} else {
+		for _, field := range reflect.VisibleFields(t) {
+			name := field.Tag.Get(in)
+			if name == "" || name == "-" || !field.IsExported() {
+				continue
+			}
+
+			if !bindableField(field.Type) {
+				err = fmt.Errorf("httpx: can't bind %s parameters into the field %s of type %s", in, field.Name, field.Type)
+				break
+			}
+
+			if !settablePath(t, field.Index) {
+				err = fmt.Errorf("httpx: can't bind %s parameters into the field %s, it's promoted through an unexported embedded pointer", in, field.Name)
+				break
+			}
+		}
+	}
+
+	bindChecks.Store(key, err)
+	return err
+}
+
+// settablePath returns false if the field at index is promoted through an unexported embedded pointer, which
+// fieldByIndex can't allocate
+func settablePath(t reflect.Type, index []int) bool {
+	for _, i := range index[:len(index)-1] {
+		field := t.Field(i)
+		t = field.Type
+		if t.Kind() == reflect.Pointer {
+			if !field.IsExported() {
+				return false
+			}
+
+			t = t.Elem()
+		}
+	}
+
+	return true
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, allocating the nil embedded pointers on the way
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v
+}
+
+// bindableField returns true if setField can set fields of type t
+func bindableField(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice && !reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return bindableValue(t.Elem())
+	}
+
+	return bindableValue(t)
+}
+
+// bindableValue returns true if setValue can set values of type t
+func bindableValue(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType || t == durationType || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+func setField(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Slice && !reflect.PointerTo(field.Type()).Implements(textUnmarshalerType) {
+		var items []string
+		for _, value := range values {
+			items = append(items, strings.Split(value, ",")...)
+		}
+
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(slice.Index(i), strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+
+		field.Set(slice)
+		return nil
+	}
+
+	return setValue(field, values[len(values)-1])
+}
+
+func setValue(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+		if err := setValue(ptr.Elem(), s); err != nil {
+			return err
+		}
+
+		v.Set(ptr)
+		return nil
+	}
+
+	switch v.Type() {
+	case timeType:
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return fmt.Errorf("expected a RFC 3339 time, got %q", s)
+		}
+
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("expected a duration like 1m30s, got %q", s)
+		}
+
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("expected a boolean, got %q", s)
+		}
+
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected an integer, got %q", s)
+		}
+
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected a positive integer, got %q", s)
+		}
+
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected a number, got %q", s)
+		}
+
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("can't bind parameters into fields of type %s", v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package httpx
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// BindInner is exported so bindEmbedded can allocate it, like the embedded structs of other packages
+type BindInner struct {
+	X int `query:"x"`
+}
+
+type bindEmbedded struct {
+	*BindInner
+}
+
+type bindSearch struct {
+	Query string        `query:"q"`
+	Page  int           `query:"page" default:"1"`
+	Tags  []string      `query:"tag"`
+	Since time.Time     `query:"since"`
+	Wait  time.Duration `query:"wait"`
+	Limit *uint8        `query:"limit"`
+	IPs   []net.IP      `query:"ip"`
+	IP    net.IP        `query:"addr"`
+}
+
+type bindUnsupported struct {
+	C chan int `query:"c"`
+}
+
+type bindHidden struct {
+	*bindUnexportedInner
+}
+
+type bindUnexportedInner struct {
+	X int `query:"x"`
+}
+
+func TestBindQuery(t *testing.T) {
+	limit := uint8(10)
+	tests := []struct {
+		name  string
+		query string
+		want  bindSearch
+		code  *ErrorJSONCode
+	}{
+		{name: "empty", query: "", want: bindSearch{Page: 1}},
+		{
+			name:  "all",
+			query: "q=go&page=3&tag=a,b&tag=c&since=2024-01-02T03:04:05Z&wait=1m30s&limit=10&ip=1.2.3.4,::1&addr=5.6.7.8",
+			want: bindSearch{
+				Query: "go",
+				Page:  3,
+				Tags:  []string{"a", "b", "c"},
+				Since: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				Wait:  90 * time.Second,
+				Limit: &limit,
+				IPs:   []net.IP{net.ParseIP("1.2.3.4"), net.ParseIP("::1")},
+				IP:    net.ParseIP("5.6.7.8"),
+			},
+		},
+		{name: "last value wins", query: "page=2&page=4", want: bindSearch{Page: 4}},
+		{name: "invalid int", query: "page=x", code: ErrInvalidParameters},
+		{name: "overflow", query: "limit=300", code: ErrInvalidParameters},
+		{name: "invalid time", query: "since=yesterday", code: ErrInvalidParameters},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BindQuery[bindSearch](httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil))
+			if tt.code != nil {
+				if code := responseOf(err).ErrorCode(); code != tt.code.Code {
+					t.Fatalf("expected %s, got %v", tt.code.Code, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBindQueryEmbeddedPointer(t *testing.T) {
+	got, err := BindQuery[bindEmbedded](httptest.NewRequest(http.MethodGet, "/?x=1", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.BindInner == nil || got.X != 1 {
+		t.Fatalf("expected x to be set through the embedded pointer, got %+v", got.BindInner)
+	}
+
+	got, err = BindQuery[bindEmbedded](httptest.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.BindInner != nil {
+		t.Fatalf("expected the embedded pointer to stay nil without parameters, got %+v", got.BindInner)
+	}
+}
+
+func TestBindQueryUnbindableTypes(t *testing.T) {
+	for name, bind := range map[string]func(r *http.Request) error{
+		"not a struct":        func(r *http.Request) error { _, err := BindQuery[int](r); return err },
+		"unsupported field":   func(r *http.Request) error { _, err := BindQuery[bindUnsupported](r); return err },
+		"unexported embedded": func(r *http.Request) error { _, err := BindQuery[bindHidden](r); return err },
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := bind(httptest.NewRequest(http.MethodGet, "/?x=1&c=1", nil))
+			var res *Response
+			if !errors.As(err, &res) || res.Status() != http.StatusInternalServerError {
+				t.Fatalf("expected a 500, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBindPath(t *testing.T) {
+	type path struct {
+		ID int64 `path:"id"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	r.SetPathValue("id", "42")
+	got, err := BindPath[path](r)
+	if err != nil || got.ID != 42 {
+		t.Fatalf("expected id 42, got %d, %v", got.ID, err)
+	}
+}
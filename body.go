@@ -0,0 +1,223 @@
+package httpx
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// MaxBufferedBody is the size up to which JSON bodies are buffered before being written,
+// so Content-Length can be set for them. Bigger bodies are streamed without Content-Length.
+var MaxBufferedBody = 64 << 10
+
+// Bytes sets b as the body of the response, Content-Length is set for you.
+func (r *Response) Bytes(b []byte) *Response {
+	r = r.mutable()
+	r.resetBody(int64(len(b)))
+	r.Copy = func(w io.Writer) error {
+		_, err := w.Write(b)
+		return err
+	}
+
+	return r
+}
+
+// Blob sets b as the body of the response with the content type, for images, PDFs, protobuf messages...
+// Example:
+//
+//	return httpx.Code(http.StatusOK).Blob("image/png", png)
+func (r *Response) Blob(contentType string, b []byte) *Response {
+	r = r.Bytes(b)
+	r.setHeader("Content-Type", contentType)
+	return r
+}
+
+// BodyEncoder encodes a value into a body of its content type, it's used to support
+// more encodings than JSON, like the ones of the xmsgpack and xcbor packages.
+// Encoders chosen by content negotiation can implement Vary() []string, the request headers
+// they depend on are added to the Vary header of the response.
+type BodyEncoder interface {
+	ContentType() string
+	Encode(w io.Writer) error
+}
+
+// Encode sets the body of the response to the encoding of e and its Content-Type.
+// The value is encoded lazily when the body is copied, the same way JSON does.
+func (r *Response) Encode(e BodyEncoder) *Response {
+	r = r.mutable()
+	r.resetBody(-1)
+	r.setHeader("Content-Type", e.ContentType())
+	r.buffered, r.rendered = true, &renderedBody{}
+	r.Copy = e.Encode
+	if v, ok := e.(interface{ Vary() []string }); ok {
+		r.setHeader("Vary", mergeVary(r.headers["Vary"], v.Vary()...))
+	}
+
+	return r
+}
+
+// Chunked stops setting Content-Length and buffering the body, so it's streamed to the client
+// as it's written.
+func (r *Response) Chunked() *Response {
+	r = r.mutable()
+	r.chunked = true
+	return r
+}
+
+// spillWriter buffers up to limit bytes before writing the header, so Content-Length can be set
+// when the whole body fits in the buffer
+type spillWriter struct {
+	w       http.ResponseWriter
+	code    int
+	limit   int
+	buf     *bytes.Buffer
+	spilled bool
+}
+
+func (s *spillWriter) Write(p []byte) (int, error) {
+	if s.spilled {
+		return s.w.Write(p)
+	}
+
+	if s.buf == nil {
+		s.buf = getBuffer()
+	}
+
+	if s.buf.Len()+len(p) <= s.limit {
+		return s.buf.Write(p)
+	}
+
+	s.spilled = true
+	s.w.WriteHeader(s.code)
+	_, err := s.buf.WriteTo(s.w)
+	s.release()
+	if err != nil {
+		return 0, err
+	}
+
+	return s.w.Write(p)
+}
+
+// Close writes the buffered body with its Content-Length if it fit in the buffer
+func (s *spillWriter) Close() error {
+	if s.spilled {
+		return nil
+	}
+
+	defer s.release()
+	n := 0
+	if s.buf != nil {
+		n = s.buf.Len()
+	}
+
+	s.w.Header().Set("Content-Length", strconv.Itoa(n))
+	s.w.WriteHeader(s.code)
+	if n == 0 {
+		return nil
+	}
+
+	_, err := s.buf.WriteTo(s.w)
+	return err
+}
+
+// release returns the buffer to the pool
+func (s *spillWriter) release() {
+	if s.buf != nil {
+		putBuffer(s.buf)
+		s.buf = nil
+	}
+}
+
+// maxPooledBuffer is the capacity above which buffers aren't returned to the pool,
+// so a single huge body doesn't stay in memory forever
+const maxPooledBuffer = 1 << 20
+
+var bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBuffer {
+		return
+	}
+
+	b.Reset()
+	bufferPool.Put(b)
+}
+
+// renderedBody memoizes the rendering of a body that can be copied many times, like JSON,
+// so calling Error or Body and then writing the response only encodes it once
+type renderedBody struct {
+	once sync.Once
+	done atomic.Bool
+	body []byte
+	err  error
+}
+
+// render copies the body once and returns the result of that copy
+func (m *renderedBody) render(copyBody func(io.Writer) error) ([]byte, error) {
+	m.once.Do(func() {
+		b := getBuffer()
+		defer putBuffer(b)
+		m.err = copyBody(b)
+		m.body = bytes.Clone(b.Bytes())
+		m.done.Store(m.err == nil)
+	})
+
+	return m.body, m.err
+}
+
+// load returns the rendered body if it was already rendered
+func (m *renderedBody) load() ([]byte, bool) {
+	if m == nil || !m.done.Load() {
+		return nil, false
+	}
+
+	return m.body, true
+}
+
+// ErrBodyConsumed is the error of copying a body that can only be read once, like Reader, after it was read
+var ErrBodyConsumed = errors.New("httpx: body already consumed")
+
+// onceBody guards bodies that can only be copied once. Error and Body read them into memory, so the
+// client still gets the body after they are logged.
+type onceBody struct {
+	memo renderedBody
+	used atomic.Bool
+}
+
+// guard returns copyBody failing with ErrBodyConsumed after its first call
+func (o *onceBody) guard(copyBody func(io.Writer) error) func(io.Writer) error {
+	return func(w io.Writer) error {
+		if o.used.Swap(true) {
+			return ErrBodyConsumed
+		}
+
+		return copyBody(w)
+	}
+}
+
+// load returns the body if it was read into memory
+func (o *onceBody) load() ([]byte, bool) {
+	if o == nil {
+		return nil, false
+	}
+
+	return o.memo.load()
+}
+
+// consumed returns true if the body was copied without keeping it in memory
+func (o *onceBody) consumed() bool {
+	if o == nil {
+		return false
+	}
+
+	_, ok := o.memo.load()
+	return !ok && o.used.Load()
+}
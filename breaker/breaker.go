@@ -0,0 +1,251 @@
+// package breaker has a per route circuit breaker middleware for httpx handlers.
+package breaker
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gabivlj/httpx"
+)
+
+// ErrOpen is the code returned while the circuit of the route is open
+var ErrOpen = httpx.NewCode("CIRCUIT_OPEN", http.StatusServiceUnavailable)
+
+// State is the state of a circuit
+type State int
+
+const (
+	// Closed lets every request through
+	Closed State = iota
+	// Open rejects every request with ErrOpen
+	Open
+	// HalfOpen lets a few requests through to check if the route recovered
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+
+	return "closed"
+}
+
+// Config configures a Breaker. The circuit of a route opens when any of the policies trips,
+// with neither set it opens after 5 consecutive failures.
+type Config struct {
+	// ConsecutiveFailures opens the circuit after that many failures in a row
+	ConsecutiveFailures int
+	// FailureRate opens the circuit when the fraction of failed requests during Window reaches it,
+	// once the window had MinRequests
+	FailureRate float64
+	// MinRequests is the number of requests a window needs to apply FailureRate, 20 by default
+	MinRequests int
+	// Window is the period FailureRate is measured over, 10 seconds by default
+	Window time.Duration
+	// OpenTimeout is how long the circuit stays open before letting requests through again, 30 seconds by default
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of requests let through at the same time while half open, 1 by default.
+	// The circuit closes if they succeed and opens again if any fails.
+	HalfOpenRequests int
+	// IsFailure returns whether a request failed from the status of its response, 5xx by default
+	IsFailure func(status int) bool
+	// OnStateChange is called when the circuit of a route changes its state, for example to export it as a metric.
+	// It's called with the Breaker locked, so it can't call its methods.
+	OnStateChange func(route string, from, to State)
+	// Code is the code rejected requests get, ErrOpen by default
+	Code *httpx.ErrorJSONCode
+}
+
+// Breaker keeps a circuit per route
+type Breaker struct {
+	cfg      Config
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	state       State
+	openedAt    time.Time
+	consecutive int
+	windowStart time.Time
+	requests    int
+	failures    int
+	trials      int
+	// generation changes with the state, so requests that started before can't count as trials
+	generation uint64
+}
+
+// New returns a Breaker for the config
+func New(cfg Config) *Breaker {
+	if cfg.ConsecutiveFailures <= 0 && cfg.FailureRate <= 0 {
+		cfg.ConsecutiveFailures = 5
+	}
+
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = 20
+	}
+
+	if cfg.Window <= 0 {
+		cfg.Window = 10 * time.Second
+	}
+
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+
+	if cfg.HalfOpenRequests <= 0 {
+		cfg.HalfOpenRequests = 1
+	}
+
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = func(status int) bool { return status >= http.StatusInternalServerError }
+	}
+
+	if cfg.Code == nil {
+		cfg.Code = ErrOpen
+	}
+
+	return &Breaker{cfg: cfg, circuits: map[string]*circuit{}}
+}
+
+// Middleware rejects the requests of routes whose circuit is open with a 503 ErrOpen and a Retry-After
+// of the time left until it lets requests through again.
+// Example:
+//
+//	b := breaker.New(breaker.Config{FailureRate: 0.5})
+//	mux.GET("/quotes", quotes, httpx.WithMiddleware(b.Middleware))
+func (b *Breaker) Middleware(next httpx.Handler) httpx.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		route := r.Method + " " + httpx.Route(r)
+		wait, generation, ok := b.allow(route, time.Now())
+		if !ok {
+			return b.cfg.Code.JSON().RetryAfter(wait)
+		}
+
+		failed := true
+		defer func() { b.done(route, generation, failed, time.Now()) }()
+		err := next(w, r)
+		failed = b.cfg.IsFailure(statusOf(err))
+		return err
+	}
+}
+
+// State returns the state of the circuit of the route, like "GET /users/{id}"
+func (b *Breaker) State(route string) State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.circuits[route]; ok {
+		return c.state
+	}
+
+	return Closed
+}
+
+// allow returns whether a request of the route can run and the generation of the circuit it runs in,
+// or how long until the circuit lets requests through
+func (b *Breaker) allow(route string, now time.Time) (time.Duration, uint64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[route]
+	if !ok {
+		c = &circuit{windowStart: now}
+		b.circuits[route] = c
+	}
+
+	if c.state == Open {
+		if wait := c.openedAt.Add(b.cfg.OpenTimeout).Sub(now); wait > 0 {
+			return wait, 0, false
+		}
+
+		b.setState(route, c, HalfOpen, now)
+	}
+
+	if c.state == HalfOpen {
+		if c.trials >= b.cfg.HalfOpenRequests {
+			return b.cfg.OpenTimeout, 0, false
+		}
+
+		c.trials++
+	}
+
+	return 0, c.generation, true
+}
+
+// done records the result of a request of the route that started in the generation of the circuit
+func (b *Breaker) done(route string, generation uint64, failed bool, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuits[route]
+	if generation != c.generation {
+		// a request that started before the circuit changed its state
+		return
+	}
+
+	if c.state == HalfOpen {
+		c.trials--
+		if failed {
+			b.setState(route, c, Open, now)
+		} else if c.trials == 0 {
+			b.setState(route, c, Closed, now)
+		}
+
+		return
+	}
+
+	if now.Sub(c.windowStart) >= b.cfg.Window {
+		c.windowStart, c.requests, c.failures = now, 0, 0
+	}
+
+	c.requests++
+	if !failed {
+		c.consecutive = 0
+		return
+	}
+
+	c.failures++
+	c.consecutive++
+	if b.cfg.ConsecutiveFailures > 0 && c.consecutive >= b.cfg.ConsecutiveFailures {
+		b.setState(route, c, Open, now)
+		return
+	}
+
+	if b.cfg.FailureRate > 0 && c.requests >= b.cfg.MinRequests && float64(c.failures)/float64(c.requests) >= b.cfg.FailureRate {
+		b.setState(route, c, Open, now)
+	}
+}
+
+func (b *Breaker) setState(route string, c *circuit, state State, now time.Time) {
+	from := c.state
+	c.state, c.trials = state, 0
+	c.generation++
+	switch state {
+	case Open:
+		c.openedAt = now
+	case Closed:
+		c.consecutive, c.requests, c.failures, c.windowStart = 0, 0, 0, now
+	}
+
+	if b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(route, from, state)
+	}
+}
+
+// statusOf returns the status of the response of a handler that returned err, errors that aren't
+// responses are unexpected so they count as 500
+func statusOf(err error) int {
+	switch err := err.(type) {
+	case nil:
+		return http.StatusOK
+	case *httpx.Response:
+		return err.Code
+	case *httpx.ErrorJSONCode:
+		return err.Status
+	}
+
+	return http.StatusInternalServerError
+}
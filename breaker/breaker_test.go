@@ -0,0 +1,218 @@
+package breaker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gabivlj/httpx"
+)
+
+const route = "GET /quotes"
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type request struct {
+	generation uint64
+}
+
+func allow(t *testing.T, b *Breaker, now time.Time) request {
+	t.Helper()
+	_, generation, ok := b.allow(route, now)
+	if !ok {
+		t.Fatalf("expected the request to be allowed in state %s", b.State(route))
+	}
+
+	return request{generation: generation}
+}
+
+func reject(t *testing.T, b *Breaker, now time.Time) {
+	t.Helper()
+	if _, _, ok := b.allow(route, now); ok {
+		t.Fatalf("expected the request to be rejected in state %s", b.State(route))
+	}
+}
+
+func run(t *testing.T, b *Breaker, now time.Time, failed bool) {
+	t.Helper()
+	req := allow(t, b, now)
+	b.done(route, req.generation, failed, now)
+}
+
+func expectState(t *testing.T, b *Breaker, state State) {
+	t.Helper()
+	if got := b.State(route); got != state {
+		t.Fatalf("expected the circuit to be %s, got %s", state, got)
+	}
+}
+
+func TestBreakerStates(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		run  func(t *testing.T, b *Breaker)
+		want State
+	}{
+		{
+			name: "consecutive failures open",
+			cfg:  Config{ConsecutiveFailures: 3},
+			run: func(t *testing.T, b *Breaker) {
+				for range 3 {
+					run(t, b, start, true)
+				}
+			},
+			want: Open,
+		},
+		{
+			name: "a success resets the consecutive failures",
+			cfg:  Config{ConsecutiveFailures: 3},
+			run: func(t *testing.T, b *Breaker) {
+				run(t, b, start, true)
+				run(t, b, start, true)
+				run(t, b, start, false)
+				run(t, b, start, true)
+			},
+			want: Closed,
+		},
+		{
+			name: "failure rate opens after min requests",
+			cfg:  Config{FailureRate: 0.5, MinRequests: 4},
+			run: func(t *testing.T, b *Breaker) {
+				run(t, b, start, false)
+				run(t, b, start, true)
+				run(t, b, start, false)
+				expectState(t, b, Closed)
+				run(t, b, start, true)
+			},
+			want: Open,
+		},
+		{
+			name: "failure rate window resets",
+			cfg:  Config{FailureRate: 0.5, MinRequests: 2, Window: time.Second},
+			run: func(t *testing.T, b *Breaker) {
+				run(t, b, start, true)
+				run(t, b, start.Add(2*time.Second), false)
+				run(t, b, start.Add(2*time.Second), false)
+			},
+			want: Closed,
+		},
+		{
+			name: "open rejects until the timeout",
+			cfg:  Config{ConsecutiveFailures: 1, OpenTimeout: time.Minute},
+			run: func(t *testing.T, b *Breaker) {
+				run(t, b, start, true)
+				reject(t, b, start.Add(59*time.Second))
+				allow(t, b, start.Add(time.Minute))
+				reject(t, b, start.Add(time.Minute))
+			},
+			want: HalfOpen,
+		},
+		{
+			name: "successful trials close",
+			cfg:  Config{ConsecutiveFailures: 1, OpenTimeout: time.Minute, HalfOpenRequests: 2},
+			run: func(t *testing.T, b *Breaker) {
+				run(t, b, start, true)
+				now := start.Add(time.Minute)
+				first, second := allow(t, b, now), allow(t, b, now)
+				b.done(route, first.generation, false, now)
+				expectState(t, b, HalfOpen)
+				b.done(route, second.generation, false, now)
+			},
+			want: Closed,
+		},
+		{
+			name: "a failed trial opens again",
+			cfg:  Config{ConsecutiveFailures: 1, OpenTimeout: time.Minute},
+			run: func(t *testing.T, b *Breaker) {
+				run(t, b, start, true)
+				run(t, b, start.Add(time.Minute), true)
+				reject(t, b, start.Add(time.Minute+time.Second))
+			},
+			want: Open,
+		},
+		{
+			name: "concurrent failed trials don't leak",
+			cfg:  Config{ConsecutiveFailures: 1, OpenTimeout: time.Minute, HalfOpenRequests: 2},
+			run: func(t *testing.T, b *Breaker) {
+				run(t, b, start, true)
+				now := start.Add(time.Minute)
+				first, second := allow(t, b, now), allow(t, b, now)
+				b.done(route, first.generation, true, now)
+				b.done(route, second.generation, true, now)
+
+				now = now.Add(time.Minute)
+				first, second = allow(t, b, now), allow(t, b, now)
+				b.done(route, first.generation, false, now)
+				b.done(route, second.generation, false, now)
+			},
+			want: Closed,
+		},
+		{
+			name: "requests from before opening don't count as trials",
+			cfg:  Config{ConsecutiveFailures: 1, OpenTimeout: time.Minute},
+			run: func(t *testing.T, b *Breaker) {
+				slow := allow(t, b, start)
+				run(t, b, start, true)
+				now := start.Add(time.Minute)
+				trial := allow(t, b, now)
+				b.done(route, slow.generation, false, now)
+				expectState(t, b, HalfOpen)
+				b.done(route, trial.generation, false, now)
+			},
+			want: Closed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(tt.cfg)
+			tt.run(t, b)
+			expectState(t, b, tt.want)
+		})
+	}
+}
+
+func TestBreakerOnStateChange(t *testing.T) {
+	var changes []State
+	b := New(Config{ConsecutiveFailures: 1, OpenTimeout: time.Minute, OnStateChange: func(route string, from, to State) {
+		changes = append(changes, to)
+	}})
+
+	run(t, b, start, true)
+	run(t, b, start.Add(time.Minute), false)
+	want := []State{Open, HalfOpen, Closed}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %v, got %v", want, changes)
+	}
+
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, changes)
+		}
+	}
+}
+
+func TestBreakerMiddleware(t *testing.T) {
+	b := New(Config{ConsecutiveFailures: 2})
+	handler := httpx.H(b.Middleware(func(w http.ResponseWriter, r *http.Request) error {
+		return httpx.ErrInternal
+	}))
+
+	statuses := make([]int, 3)
+	for i := range statuses {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/quotes", nil))
+		statuses[i] = w.Code
+		if i == 2 && w.Header().Get("Retry-After") == "" {
+			t.Fatal("expected a Retry-After header while open")
+		}
+	}
+
+	want := []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusServiceUnavailable}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, statuses)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package httpx
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheDirective is a single Cache-Control directive, use the Cache* values and constructors to build them.
+type CacheDirective string
+
+const (
+	// CacheNoStore forbids any cache from storing the response. It overrides every other directive.
+	CacheNoStore CacheDirective = "no-store"
+	// CacheNoCache lets caches store the response but forces them to revalidate it before each reuse.
+	CacheNoCache CacheDirective = "no-cache"
+	// CachePrivate only allows the browser to cache the response. It takes priority over CachePublic.
+	CachePrivate CacheDirective = "private"
+	// CachePublic allows shared caches to store the response.
+	CachePublic CacheDirective = "public"
+	// CacheImmutable tells the client that the response won't change while it's fresh.
+	CacheImmutable CacheDirective = "immutable"
+	// CacheMustRevalidate forbids serving the response stale once it expires.
+	CacheMustRevalidate CacheDirective = "must-revalidate"
+	// CacheNoTransform forbids intermediaries from transforming the body.
+	CacheNoTransform CacheDirective = "no-transform"
+)
+
+// CacheMaxAge returns the max-age directive, rounded down to seconds.
+func CacheMaxAge(d time.Duration) CacheDirective {
+	return cacheSeconds("max-age", d)
+}
+
+// CacheSMaxAge returns the s-maxage directive, which only applies to shared caches.
+func CacheSMaxAge(d time.Duration) CacheDirective {
+	return cacheSeconds("s-maxage", d)
+}
+
+// CacheStaleWhileRevalidate returns the stale-while-revalidate directive.
+func CacheStaleWhileRevalidate(d time.Duration) CacheDirective {
+	return cacheSeconds("stale-while-revalidate", d)
+}
+
+// CacheStaleIfError returns the stale-if-error directive.
+func CacheStaleIfError(d time.Duration) CacheDirective {
+	return cacheSeconds("stale-if-error", d)
+}
+
+func cacheSeconds(name string, d time.Duration) CacheDirective {
+	seconds := int64(d / time.Second)
+	if seconds < 0 {
+		seconds = 0
+	}
+
+	return CacheDirective(name + "=" + strconv.FormatInt(seconds, 10))
+}
+
+// name returns the directive without its argument
+func (c CacheDirective) name() string {
+	name, _, _ := strings.Cut(string(c), "=")
+	return name
+}
+
+// Cache sets the Cache-Control header composed from the passed directives.
+// Repeated directives are deduplicated keeping the last one, "private" wins over "public"
+// and "no-store" drops every directive that would let the response be stored.
+// Example:
+//
+//	return httpx.Code(http.StatusOK).Cache(httpx.CachePublic, httpx.CacheMaxAge(time.Hour)).JSON(v)
+func (r *Response) Cache(directives ...CacheDirective) *Response {
+	r = r.mutable()
+	r.setHeader("Cache-Control", cacheControl(directives))
+	return r
+}
+
+func cacheControl(directives []CacheDirective) string {
+	seen := make(map[string]int, len(directives))
+	composed := make([]CacheDirective, 0, len(directives))
+	for _, directive := range directives {
+		if directive == "" {
+			continue
+		}
+
+		if i, ok := seen[directive.name()]; ok {
+			composed[i] = directive
+			continue
+		}
+
+		seen[directive.name()] = len(composed)
+		composed = append(composed, directive)
+	}
+
+	_, noStore := seen[string(CacheNoStore)]
+	_, private := seen[string(CachePrivate)]
+	values := make([]string, 0, len(composed))
+	for _, directive := range composed {
+		switch {
+		case noStore && directive.storable():
+			continue
+		case private && directive == CachePublic:
+			continue
+		}
+
+		values = append(values, string(directive))
+	}
+
+	return strings.Join(values, ", ")
+}
+
+// storable returns true when the directive only makes sense for responses caches can store
+func (c CacheDirective) storable() bool {
+	switch c.name() {
+	case "public", "max-age", "s-maxage", "immutable", "stale-while-revalidate", "stale-if-error":
+		return true
+	}
+
+	return false
+}
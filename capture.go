@@ -0,0 +1,273 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Redacted replaces the values masked by the redaction rules of CaptureBodies
+const Redacted = "[REDACTED]"
+
+// DefaultRedact are the fields redacted by CaptureBodies when CaptureOptions.Redact is nil
+var DefaultRedact = []string{"password", "token", "secret", "authorization"}
+
+// CaptureOptions configures CaptureBodies
+type CaptureOptions struct {
+	// MaxBytes is the size of the bodies captured, 64KB by default. Bigger bodies are left out.
+	MaxBytes int64
+	// ContentTypes are the media types of the bodies captured, application/json by default
+	ContentTypes []string
+	// Redact are the JSON fields whose values are replaced with Redacted, DefaultRedact if it's nil.
+	// A name like "password" matches the field at any depth, a path like "user.password" only from the root
+	// and "*" matches any field or array index, like "cards.*.number". Names are compared without case.
+	Redact []string
+	// Cards masks the strings that look like card numbers but their last 4 digits
+	Cards bool
+	// Sink receives the bodies once the response is written, r is the request the middleware got
+	Sink func(r *http.Request, info AfterInfo, bodies Bodies)
+}
+
+// Bodies are the request and response bodies captured by CaptureBodies, redacted. They're nil when the
+// body is empty, bigger than CaptureOptions.MaxBytes, of another content type or can't be parsed to redact it.
+type Bodies struct {
+	Request  []byte
+	Response []byte
+}
+
+// CaptureBodies returns a middleware that tees the request body as the handler reads it and the response body,
+// the one written to the http.ResponseWriter or the one of the returned Response if it's buffered like JSON,
+// handing them redacted to the sink. It's meant for audit logs and debugging.
+// Example:
+//
+//	debug := httpx.CaptureBodies(httpx.CaptureOptions{Cards: true, Sink: func(r *http.Request, info httpx.AfterInfo, bodies httpx.Bodies) {
+//		httpx.Logger(r).Debug("bodies", "request", bodies.Request, "response", bodies.Response)
+//	}})
+func CaptureBodies(opts CaptureOptions) Middleware {
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = 64 << 10
+	}
+
+	if len(opts.ContentTypes) == 0 {
+		opts.ContentTypes = []string{"application/json"}
+	}
+
+	if opts.Redact == nil {
+		opts.Redact = DefaultRedact
+	}
+
+	rules := make([][]string, len(opts.Redact))
+	for i, rule := range opts.Redact {
+		rules[i] = strings.Split(strings.ToLower(rule), ".")
+	}
+
+	c := &bodyCapturer{opts: opts, rules: rules}
+	return func(next Handler) Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			var reqBody *capture
+			if r.Body != nil && c.allowed(r.Header.Get("Content-Type")) {
+				reqBody = &capture{limit: opts.MaxBytes}
+				r.Body = &teeBody{ReadCloser: r.Body, capture: reqBody}
+			}
+
+			resBody := &capture{limit: opts.MaxBytes}
+			w = &teeWriter{ResponseWriter: w, capture: resBody}
+			inner := r
+			r = After(r, func(w http.ResponseWriter, _ *http.Request, info AfterInfo) {
+				bodies := Bodies{Request: c.redact(reqBody)}
+				if c.allowed(w.Header().Get("Content-Type")) {
+					bodies.Response = c.responseBody(info, resBody)
+				}
+
+				opts.Sink(inner, info, bodies)
+			})
+
+			return next(w, r)
+		}
+	}
+}
+
+type bodyCapturer struct {
+	opts  CaptureOptions
+	rules [][]string
+}
+
+// responseBody returns the redacted response body, the one written by the handler or the one of its Response
+func (c *bodyCapturer) responseBody(info AfterInfo, written *capture) []byte {
+	if info.Response == nil {
+		return c.redact(written)
+	}
+
+	if !info.Response.buffered {
+		return nil
+	}
+
+	body, err := info.Response.Body()
+	if err != nil {
+		return nil
+	}
+
+	captured := &capture{limit: c.opts.MaxBytes}
+	_, _ = captured.Write(body)
+	return c.redact(captured)
+}
+
+// allowed returns true if the body with the content type can be captured
+func (c *bodyCapturer) allowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && slices.Contains(c.opts.ContentTypes, mediaType)
+}
+
+// redact returns the captured body with the redaction rules applied, bodies that can't be parsed are dropped
+func (c *bodyCapturer) redact(captured *capture) []byte {
+	if captured == nil || captured.truncated || captured.buf.Len() == 0 {
+		return nil
+	}
+
+	var v any
+	if err := json.Unmarshal(captured.buf.Bytes(), &v); err != nil {
+		return nil
+	}
+
+	redacted, err := json.Marshal(c.redactValue(v, nil))
+	if err != nil {
+		return nil
+	}
+
+	return redacted
+}
+
+// redactValue applies the rules to v, which is at path
+func (c *bodyCapturer) redactValue(v any, path []string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			v[key] = c.redactField(value, append(path, strings.ToLower(key)))
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = c.redactField(value, append(path, "*"))
+		}
+	case string:
+		if c.opts.Cards && isCardNumber(v) {
+			digits := strings.Map(keepDigits, v)
+			return strings.Repeat("*", len(digits)-4) + digits[len(digits)-4:]
+		}
+	}
+
+	return v
+}
+
+func (c *bodyCapturer) redactField(v any, path []string) any {
+	for _, rule := range c.rules {
+		if matchRule(rule, path) {
+			return Redacted
+		}
+	}
+
+	return c.redactValue(v, path[:len(path):len(path)])
+}
+
+// matchRule returns true if the path of a field matches the rule, rules of a single name match the last field
+func matchRule(rule, path []string) bool {
+	if len(rule) == 1 {
+		return rule[0] == path[len(path)-1]
+	}
+
+	if len(rule) != len(path) {
+		return false
+	}
+
+	for i := range rule {
+		if rule[i] != "*" && rule[i] != path[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isCardNumber returns true if s has 13 to 19 digits, optionally separated by spaces or dashes, passing the Luhn check
+func isCardNumber(s string) bool {
+	digits := 0
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '-':
+			continue
+		case c < '0' || c > '9':
+			return false
+		}
+
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum, double = sum+d, !double
+		digits++
+	}
+
+	return digits >= 13 && digits <= 19 && sum%10 == 0
+}
+
+func keepDigits(r rune) rune {
+	if r >= '0' && r <= '9' {
+		return r
+	}
+
+	return -1
+}
+
+// capture keeps the first limit bytes written to it
+type capture struct {
+	buf       bytes.Buffer
+	limit     int64
+	truncated bool
+}
+
+func (c *capture) Write(p []byte) (int, error) {
+	if room := c.limit - int64(c.buf.Len()); int64(len(p)) > room {
+		c.truncated = true
+		p = p[:max(room, 0)]
+	}
+
+	c.buf.Write(p)
+	return len(p), nil
+}
+
+// teeBody captures the request body as the handler reads it
+type teeBody struct {
+	io.ReadCloser
+	capture *capture
+}
+
+func (t *teeBody) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	_, _ = t.capture.Write(p[:n])
+	return n, err
+}
+
+// teeWriter captures what the handler writes to the http.ResponseWriter
+type teeWriter struct {
+	http.ResponseWriter
+	capture *capture
+}
+
+func (t *teeWriter) Write(p []byte) (int, error) {
+	_, _ = t.capture.Write(p)
+	return t.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the features of the underlying writer
+func (t *teeWriter) Unwrap() http.ResponseWriter {
+	return t.ResponseWriter
+}
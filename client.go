@@ -0,0 +1,150 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// DefaultClient is the client used by Do
+var DefaultClient = http.DefaultClient
+
+// maxErrorBody is the size of the error bodies Do reads looking for an error code
+const maxErrorBody = 1 << 20
+
+var codes = struct {
+	mu sync.RWMutex
+	m  map[string]*ErrorJSONCode
+}{m: map[string]*ErrorJSONCode{}}
+
+// registerCode registers e, the first code registered with the same name is kept
+func registerCode(e *ErrorJSONCode) {
+	codes.mu.Lock()
+	defer codes.mu.Unlock()
+	if _, ok := codes.m[e.Code]; !ok {
+		codes.m[e.Code] = e
+	}
+}
+
+// LookupCode returns the code registered with NewCode with that name
+func LookupCode(code string) (*ErrorJSONCode, bool) {
+	codes.mu.RLock()
+	defer codes.mu.RUnlock()
+	e, ok := codes.m[code]
+	return e, ok
+}
+
+// Codes returns the codes registered with NewCode sorted by name, with their metadata
+func Codes() []*ErrorJSONCode {
+	codes.mu.RLock()
+	defer codes.mu.RUnlock()
+	list := make([]*ErrorJSONCode, 0, len(codes.m))
+	for _, e := range codes.m {
+		list = append(list, e)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// CodeError is the error returned by Do when the response is an error code.
+// errors.Is matches it with the code, even if the code wasn't registered in this process.
+type CodeError struct {
+	// Code is the registered code, or a code created from the response if there's none registered
+	Code *ErrorJSONCode
+	// Status is the status of the response, it can differ from the status of Code
+	Status int
+	// Extra is the raw JSON of the extra field, empty if the response didn't have one
+	Extra json.RawMessage
+}
+
+func (e *CodeError) Error() string {
+	if len(e.Extra) > 0 {
+		return fmt.Sprintf("%d %s: %s", e.Status, e.Code.Code, e.Extra)
+	}
+
+	return fmt.Sprintf("%d %s", e.Status, e.Code.Code)
+}
+
+// Is matches codes with the same name
+func (e *CodeError) Is(target error) bool {
+	code, ok := target.(*ErrorJSONCode)
+	return ok && code.Code == e.Code.Code
+}
+
+// Unwrap returns the code
+func (e *CodeError) Unwrap() error {
+	return e.Code
+}
+
+// DecodeExtra decodes the extra field into v
+func (e *CodeError) DecodeExtra(v any) error {
+	if len(e.Extra) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(e.Extra, v)
+}
+
+// Do sends the request with DefaultClient. When the response is an error code of this package it's
+// converted into a *CodeError, and the response is returned with its body already read and closed.
+// Example:
+//
+//	res, err := httpx.Do(req)
+//	if errors.Is(err, httpx.ErrNotFound) {
+//		return ErrUserNotFound.JSON()
+//	}
+func Do(req *http.Request) (*http.Response, error) {
+	res, err := DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, DecodeError(res)
+}
+
+// DecodeError returns a *CodeError if res is an error code response, reading and closing its body.
+// Other responses are left untouched and nil is returned.
+func DecodeError(res *http.Response) error {
+	if res.StatusCode < 400 {
+		return nil
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mediaType != "application/json" {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxErrorBody))
+	if err != nil {
+		res.Body.Close()
+		return err
+	}
+
+	var envelope struct {
+		Code  string          `json:"code"`
+		Extra json.RawMessage `json:"extra"`
+	}
+
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.Code == "" {
+		// bodies larger than maxErrorBody aren't valid JSON at the limit, the rest is read after what was read
+		res.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), res.Body), res.Body}
+		return nil
+	}
+
+	res.Body.Close()
+
+	code, ok := LookupCode(envelope.Code)
+	if !ok {
+		code = &ErrorJSONCode{Code: envelope.Code, Status: res.StatusCode}
+	}
+
+	return &CodeError{Code: code, Status: res.StatusCode, Extra: envelope.Extra}
+}
@@ -0,0 +1,82 @@
+package httpx
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type trackedBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestDecodeError(t *testing.T) {
+	large := `{"items": "` + strings.Repeat("x", 2*maxErrorBody) + `"}`
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		code        *ErrorJSONCode
+		extra       string
+	}{
+		{name: "success", status: http.StatusOK, contentType: "application/json", body: `{"code": "NOT_FOUND"}`},
+		{name: "registered code", status: http.StatusNotFound, contentType: "application/json", body: `{"code": "NOT_FOUND"}`, code: ErrNotFound},
+		{name: "extra", status: http.StatusNotFound, contentType: "application/json; charset=utf-8", body: `{"code": "NOT_FOUND", "extra": {"id": 1}}`, code: ErrNotFound, extra: `{"id": 1}`},
+		{name: "unregistered code", status: http.StatusTeapot, contentType: "application/json", body: `{"code": "UNREGISTERED_TEAPOT"}`, code: &ErrorJSONCode{Code: "UNREGISTERED_TEAPOT"}},
+		{name: "not JSON", status: http.StatusBadGateway, contentType: "text/html", body: "<h1>bad gateway</h1>"},
+		{name: "JSON without a code", status: http.StatusBadRequest, contentType: "application/json", body: `{"error": "bad"}`},
+		{name: "invalid JSON", status: http.StatusBadRequest, contentType: "application/json", body: `{"error"`},
+		{name: "larger than the limit", status: http.StatusInternalServerError, contentType: "application/json", body: large},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &trackedBody{Reader: strings.NewReader(tt.body)}
+			res := &http.Response{StatusCode: tt.status, Header: http.Header{"Content-Type": {tt.contentType}}, Body: body}
+			err := DecodeError(res)
+			if tt.code == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+
+				if body.closed {
+					t.Fatal("expected the body to stay open")
+				}
+
+				got, _ := io.ReadAll(res.Body)
+				if string(got) != tt.body {
+					t.Fatalf("expected the body to be left untouched, got %d bytes out of %d", len(got), len(tt.body))
+				}
+
+				res.Body.Close()
+				if !body.closed {
+					t.Fatal("expected closing the body to close the original one")
+				}
+
+				return
+			}
+
+			var codeErr *CodeError
+			if !errors.As(err, &codeErr) || !errors.Is(err, tt.code) {
+				t.Fatalf("expected %s, got %v", tt.code.Code, err)
+			}
+
+			if codeErr.Status != tt.status || string(codeErr.Extra) != tt.extra {
+				t.Fatalf("expected %d with extra %q, got %d with %q", tt.status, tt.extra, codeErr.Status, codeErr.Extra)
+			}
+
+			if !body.closed {
+				t.Fatal("expected the body to be closed")
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package httpx
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// TrustedProxies are the proxies whose forwarding headers the access log and rate limiter trust to find the client IP
+var TrustedProxies []netip.Prefix
+
+// ClientIP returns the IP of the client. Forwarded, X-Forwarded-For and X-Real-IP are only read when the peer
+// is one of the trusted proxies, walking the chain of proxies from the closest one until an untrusted address,
+// so clients can't spoof their IP by sending those headers. X-Real-IP is only read without a chain, and the
+// peer is returned when an address of the chain can't be parsed.
+// Example:
+//
+//	var proxies = []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+//
+//	ip := httpx.ClientIP(r, proxies...)
+func ClientIP(r *http.Request, trustProxies ...netip.Prefix) string {
+	peer, ok := parseIP(r.RemoteAddr)
+	if !ok {
+		return r.RemoteAddr
+	}
+
+	trusted := func(addr netip.Addr) bool {
+		for _, prefix := range trustProxies {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if !trusted(peer) {
+		return peer.String()
+	}
+
+	chain := forwardedChain(r)
+	for i := len(chain) - 1; i >= 0; i-- {
+		addr, ok := parseIP(chain[i])
+		if !ok {
+			// an obfuscated or garbled hop, the addresses before it can't be trusted and neither can X-Real-IP
+			return peer.String()
+		}
+
+		if !trusted(addr) || i == 0 {
+			return addr.String()
+		}
+	}
+
+	if addr, ok := parseIP(r.Header.Get("X-Real-IP")); ok {
+		return addr.String()
+	}
+
+	return peer.String()
+}
+
+// forwardedChain returns the forwarded addresses from Forwarded, or X-Forwarded-For if it's not set, client first
+func forwardedChain(r *http.Request) []string {
+	var chain []string
+	for _, forwarded := range r.Header.Values("Forwarded") {
+		for _, element := range strings.Split(forwarded, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				key, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+				if strings.EqualFold(key, "for") {
+					chain = append(chain, strings.Trim(value, `"`))
+				}
+			}
+		}
+	}
+
+	if len(chain) > 0 {
+		return chain
+	}
+
+	for _, forwardedFor := range r.Header.Values("X-Forwarded-For") {
+		for _, addr := range strings.Split(forwardedFor, ",") {
+			chain = append(chain, strings.TrimSpace(addr))
+		}
+	}
+
+	return chain
+}
+
+// parseIP parses an IP with an optional port, like "192.0.2.1:1234" or "[2001:db8::1]:80"
+func parseIP(s string) (netip.Addr, bool) {
+	s = strings.TrimSpace(s)
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+
+	addr, err := netip.ParseAddr(strings.Trim(s, "[]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return addr.Unmap(), true
+}
@@ -0,0 +1,46 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("fd00::/8")}
+	tests := []struct {
+		name   string
+		remote string
+		header http.Header
+		want   string
+	}{
+		{name: "untrusted peer", remote: "192.0.2.1:1234", header: http.Header{"X-Forwarded-For": {"198.51.100.1"}}, want: "192.0.2.1"},
+		{name: "unparsable peer", remote: "pipe", want: "pipe"},
+		{name: "trusted peer without headers", remote: "10.0.0.1:1234", want: "10.0.0.1"},
+		{name: "x-forwarded-for", remote: "10.0.0.1:1234", header: http.Header{"X-Forwarded-For": {"198.51.100.1, 10.0.0.2"}}, want: "198.51.100.1"},
+		{name: "spoofed x-forwarded-for", remote: "10.0.0.1:1234", header: http.Header{"X-Forwarded-For": {"203.0.113.9, 198.51.100.1"}}, want: "198.51.100.1"},
+		{name: "several x-forwarded-for headers", remote: "10.0.0.1:1234", header: http.Header{"X-Forwarded-For": {"198.51.100.1", "10.0.0.2"}}, want: "198.51.100.1"},
+		{name: "only trusted proxies", remote: "10.0.0.1:1234", header: http.Header{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}}, want: "10.0.0.3"},
+		{name: "forwarded", remote: "10.0.0.1:1234", header: http.Header{"Forwarded": {`for=198.51.100.1;proto=https, for="[fd00::1]:80"`}}, want: "198.51.100.1"},
+		{name: "forwarded wins over x-forwarded-for", remote: "10.0.0.1:1234", header: http.Header{"Forwarded": {"for=198.51.100.1"}, "X-Forwarded-For": {"203.0.113.9"}}, want: "198.51.100.1"},
+		{name: "ipv4 mapped ipv6", remote: "[::ffff:10.0.0.1]:1234", header: http.Header{"X-Forwarded-For": {"198.51.100.1"}}, want: "198.51.100.1"},
+		{name: "x-real-ip", remote: "10.0.0.1:1234", header: http.Header{"X-Real-Ip": {"198.51.100.1"}}, want: "198.51.100.1"},
+		{name: "unparsable hop", remote: "10.0.0.1:1234", header: http.Header{"Forwarded": {"for=_hidden, for=10.0.0.2"}, "X-Real-Ip": {"203.0.113.9"}}, want: "10.0.0.1"},
+		{name: "unparsable x-forwarded-for", remote: "10.0.0.1:1234", header: http.Header{"X-Forwarded-For": {"unknown"}, "X-Real-Ip": {"203.0.113.9"}}, want: "10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+			for key, values := range tt.header {
+				r.Header[key] = values
+			}
+
+			if got := ClientIP(r, proxies...); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
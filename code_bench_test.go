@@ -0,0 +1,30 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func BenchmarkCode(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		_ = Code(http.StatusNotFound)
+	}
+}
+
+func BenchmarkCodeHeaders(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		_ = Code(http.StatusNotFound).Headers(map[string]string{"X-Reason": "missing"})
+	}
+}
+
+func BenchmarkErrorCodeWrite(b *testing.B) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	b.ReportAllocs()
+	for range b.N {
+		w := httptest.NewRecorder()
+		ErrNotFound.JSON().ServeHTTP(w, req)
+	}
+}
@@ -0,0 +1,103 @@
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CodeGroup creates codes under a common prefix, so services with many codes don't collide.
+// Example:
+//
+//	var billing = httpx.NewCodeGroup("BILLING", httpx.StatusRange(400, 499), httpx.DocsBaseURL("https://docs.example.com/errors"))
+//
+//	// 402 {"code": "BILLING.CARD_DECLINED", "docs_url": "https://docs.example.com/errors/BILLING.CARD_DECLINED"}
+//	var ErrCardDeclined = billing.NewCode("CARD_DECLINED", http.StatusPaymentRequired)
+type CodeGroup struct {
+	name string
+	opts groupOptions
+}
+
+type groupOptions struct {
+	minStatus, maxStatus int
+	docsBase             string
+	codeOpts             []CodeOption
+}
+
+// GroupOption configures the defaults of a CodeGroup
+type GroupOption func(*groupOptions)
+
+// StatusRange makes NewCode panic when a code of the group has a status outside [min, max]
+func StatusRange(min, max int) GroupOption {
+	return func(o *groupOptions) {
+		o.minStatus, o.maxStatus = min, max
+	}
+}
+
+// DocsBaseURL sets the docs URL of the codes of the group to base followed by the code
+func DocsBaseURL(base string) GroupOption {
+	return func(o *groupOptions) {
+		o.docsBase = strings.TrimSuffix(base, "/")
+	}
+}
+
+// GroupCodeOptions applies opts to every code of the group before the options passed to NewCode
+func GroupCodeOptions(opts ...CodeOption) GroupOption {
+	return func(o *groupOptions) {
+		o.codeOpts = append(o.codeOpts, opts...)
+	}
+}
+
+// NewCodeGroup returns a group whose codes are prefixed with name and a dot
+func NewCodeGroup(name string, opts ...GroupOption) *CodeGroup {
+	g := &CodeGroup{name: name}
+	for _, opt := range opts {
+		opt(&g.opts)
+	}
+
+	return g
+}
+
+// Name returns the prefix of the group
+func (g *CodeGroup) Name() string {
+	return g.name
+}
+
+// Group returns a subgroup, "BILLING" and "CARDS" give codes like "BILLING.CARDS.DECLINED".
+// The subgroup inherits the defaults of g, opts are applied after them.
+func (g *CodeGroup) Group(name string, opts ...GroupOption) *CodeGroup {
+	sub := &CodeGroup{name: g.name + "." + name, opts: g.opts}
+	sub.opts.codeOpts = append([]CodeOption(nil), g.opts.codeOpts...)
+	for _, opt := range opts {
+		opt(&sub.opts)
+	}
+
+	return sub
+}
+
+// NewCode creates and registers a code prefixed with the name of the group, with the defaults of the group
+func (g *CodeGroup) NewCode(code string, status int, opts ...CodeOption) *ErrorJSONCode {
+	code = g.name + "." + code
+	if g.opts.maxStatus != 0 && (status < g.opts.minStatus || status > g.opts.maxStatus) {
+		panic(fmt.Sprintf("httpx: code %s has status %d outside of its group range [%d, %d]",
+			code, status, g.opts.minStatus, g.opts.maxStatus))
+	}
+
+	all := make([]CodeOption, 0, len(g.opts.codeOpts)+len(opts)+1)
+	if g.opts.docsBase != "" {
+		all = append(all, WithDocsURL(g.opts.docsBase+"/"+code))
+	}
+
+	all = append(all, g.opts.codeOpts...)
+	return NewCode(code, status, append(all, opts...)...)
+}
+
+// Contains reports if err is a code of the group or of its subgroups
+func (g *CodeGroup) Contains(err error) bool {
+	var code *ErrorJSONCode
+	if !errors.As(err, &code) {
+		return false
+	}
+
+	return strings.HasPrefix(code.Code, g.name+".")
+}
@@ -0,0 +1,167 @@
+package httpx
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// incompressibleTypes are content type prefixes that are already compressed
+var incompressibleTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"font/woff",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/zstd",
+	"application/x-bzip2",
+	"application/x-7z-compressed",
+	"application/x-rar-compressed",
+	"application/pdf",
+	"application/octet-stream",
+}
+
+var gzipPool = sync.Pool{New: func() any { return gzip.NewWriter(io.Discard) }}
+
+var flatePool = sync.Pool{New: func() any {
+	w, _ := flate.NewWriter(io.Discard, flate.DefaultCompression)
+	return w
+}}
+
+// encoder is the common interface of the pooled gzip and flate writers
+type encoder interface {
+	io.WriteCloser
+	Reset(io.Writer)
+}
+
+// Compress enables transparent compression of the body when the client accepts gzip or deflate.
+// Content-Encoding and Vary headers are set for you and content types that are
+// already compressed (images, archives...) are sent as is.
+func (r *Response) Compress() *Response {
+	r = r.mutable()
+	r.compress = true
+	return r
+}
+
+// compressWriter delays writing the header until the first write, so the compression
+// decision can be made with the final Content-Type.
+type compressWriter struct {
+	w           http.ResponseWriter
+	code        int
+	encoding    string
+	enc         encoder
+	pool        *sync.Pool
+	wroteHeader bool
+}
+
+// compressWriterFor returns the compressWriter for the response, or nil if it shouldn't be compressed
+func compressWriterFor(res *Response, w http.ResponseWriter, r *http.Request) *compressWriter {
+	if !res.compress {
+		return nil
+	}
+
+	return newCompressWriter(w, r, res.Code)
+}
+
+// newCompressWriter returns nil if the client doesn't accept a supported encoding
+func newCompressWriter(w http.ResponseWriter, r *http.Request, code int) *compressWriter {
+	if w.Header().Get("Content-Encoding") != "" {
+		return nil
+	}
+
+	addVary(w.Header(), "Accept-Encoding")
+	encoding := negotiate(r.Header.Get("Accept-Encoding"), "gzip", "deflate")
+	if encoding == "" {
+		return nil
+	}
+
+	return &compressWriter{w: w, code: code, encoding: encoding}
+}
+
+func (c *compressWriter) writeHeader(p []byte) {
+	c.wroteHeader = true
+	header := c.w.Header()
+	if header.Get("Content-Type") == "" && len(p) > 0 {
+		header.Set("Content-Type", http.DetectContentType(p))
+	}
+
+	if compressible(header.Get("Content-Type")) {
+		c.pool = &gzipPool
+		if c.encoding == "deflate" {
+			c.pool = &flatePool
+		}
+
+		c.enc = c.pool.Get().(encoder)
+		c.enc.Reset(c.w)
+		header.Set("Content-Encoding", c.encoding)
+		header.Del("Content-Length")
+	}
+
+	c.w.WriteHeader(c.code)
+}
+
+// Header returns the header of the underlying writer, so trailers can be set while writing
+func (c *compressWriter) Header() http.Header {
+	return c.w.Header()
+}
+
+func (c *compressWriter) Write(p []byte) (int, error) {
+	if !c.wroteHeader {
+		c.writeHeader(p)
+	}
+
+	if c.enc == nil {
+		return c.w.Write(p)
+	}
+
+	return c.enc.Write(p)
+}
+
+// Flush sends the compressed data written until now to the client
+func (c *compressWriter) Flush() error {
+	if !c.wroteHeader {
+		c.writeHeader(nil)
+	}
+
+	if flusher, ok := c.enc.(interface{ Flush() error }); ok {
+		if err := flusher.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return flusherOf(c.w)()
+}
+
+// Close writes the header if nothing was written and flushes the encoder, returning it to its pool
+func (c *compressWriter) Close() error {
+	if !c.wroteHeader {
+		c.wroteHeader = true
+		c.w.WriteHeader(c.code)
+	}
+
+	if c.enc == nil {
+		return nil
+	}
+
+	err := c.enc.Close()
+	c.enc.Reset(io.Discard)
+	c.pool.Put(c.enc)
+	c.enc = nil
+	return err
+}
+
+func compressible(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+
+	return true
+}
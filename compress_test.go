@@ -0,0 +1,108 @@
+package httpx
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompress(t *testing.T) {
+	text := strings.Repeat("compress me ", 100)
+	tests := []struct {
+		name     string
+		accept   string
+		res      *Response
+		encoding string
+		vary     bool
+	}{
+		{name: "gzip", accept: "gzip", res: Code(http.StatusOK).Compress().Text(text), encoding: "gzip", vary: true},
+		{name: "deflate", accept: "deflate", res: Code(http.StatusOK).Compress().Text(text), encoding: "deflate", vary: true},
+		{name: "preferred by quality", accept: "gzip;q=0.5, deflate", res: Code(http.StatusOK).Compress().Text(text), encoding: "deflate", vary: true},
+		{name: "same quality in offer order", accept: "deflate, gzip", res: Code(http.StatusOK).Compress().Text(text), encoding: "gzip", vary: true},
+		{name: "wildcard", accept: "*", res: Code(http.StatusOK).Compress().Text(text), encoding: "gzip", vary: true},
+		{name: "refused", accept: "gzip;q=0, br", res: Code(http.StatusOK).Compress().Text(text), vary: true},
+		{name: "no accept encoding", res: Code(http.StatusOK).Compress().Text(text), vary: true},
+		{name: "already compressed type", accept: "gzip", res: Code(http.StatusOK).Compress().Blob("image/png", []byte(text)), vary: true},
+		{name: "json", accept: "gzip", res: Code(http.StatusCreated).Compress().JSON(map[string]string{"text": text}), encoding: "gzip", vary: true},
+		{name: "already encoded", accept: "gzip", res: Code(http.StatusOK).Compress().Headers(map[string]string{"Content-Encoding": "br"}).Text(text)},
+		{name: "empty body", accept: "gzip", res: Code(http.StatusNoContent).Compress()},
+		{name: "not enabled", accept: "gzip", res: Code(http.StatusOK).Text(text)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept-Encoding", tt.accept)
+			}
+
+			w := httptest.NewRecorder()
+			H(func(w http.ResponseWriter, r *http.Request) error { return tt.res })(w, r)
+			if w.Code != tt.res.Code {
+				t.Fatalf("expected %d, got %d", tt.res.Code, w.Code)
+			}
+
+			if got := w.Header().Get("Vary") == "Accept-Encoding"; got != tt.vary {
+				t.Fatalf("expected Vary on Accept-Encoding to be %v, got %q", tt.vary, w.Header().Get("Vary"))
+			}
+
+			if tt.encoding == "" {
+				if w.Header().Get("Content-Encoding") == "gzip" || w.Header().Get("Content-Encoding") == "deflate" {
+					t.Fatalf("expected the body not to be compressed, got %s", w.Header().Get("Content-Encoding"))
+				}
+
+				return
+			}
+
+			if w.Header().Get("Content-Encoding") != tt.encoding || w.Header().Get("Content-Length") != "" {
+				t.Fatalf("expected a %s body without Content-Length, got %v", tt.encoding, w.Header())
+			}
+
+			var body io.Reader
+			switch tt.encoding {
+			case "gzip":
+				zr, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				body = zr
+			case "deflate":
+				body = flate.NewReader(w.Body)
+			}
+
+			got, err := io.ReadAll(body)
+			if err != nil || !strings.Contains(string(got), text) {
+				t.Fatalf("expected the decompressed body to contain the text, got %d bytes, %v", len(got), err)
+			}
+		})
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: ""},
+		{header: "gzip", want: "gzip"},
+		{header: "GZIP", want: "gzip"},
+		{header: "br, deflate", want: "deflate"},
+		{header: "deflate;q=0.9, gzip;q=0.8", want: "deflate"},
+		{header: "*;q=0.5, gzip;q=0", want: "deflate"},
+		{header: "gzip;q=invalid, deflate;q=0.1", want: "deflate"},
+		{header: "*;q=0", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := negotiate(tt.header, "gzip", "deflate"); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// StatusClientClosedRequest is the non standard status used when the client went away before the response
+const StatusClientClosedRequest = 499
+
+// ErrClientClosedRequest is the code of handlers returning context.Canceled
+var ErrClientClosedRequest = NewCode("CLIENT_CLOSED_REQUEST", StatusClientClosedRequest)
+
+// ContextErrorHandler is the function that will be fired instead of DefaultErrorHandler when a handler returns
+// context.Canceled or context.DeadlineExceeded. By default they are a 499 ErrClientClosedRequest and a
+// 504 ErrTimeout. When the request context was canceled the response isn't written, as nobody is listening,
+// and ClientAbortHandler is fired instead.
+var ContextErrorHandler = func(err error) *Response {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrTimeout.JSON()
+	}
+
+	return ErrClientClosedRequest.JSON()
+}
+
+// isContextError returns true if err is a context error that ContextErrorHandler renders
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// clientGone returns true if err is the request being canceled, so the response can't be delivered
+func clientGone(r *http.Request, err error) bool {
+	return errors.Is(err, context.Canceled) && errors.Is(r.Context().Err(), context.Canceled)
+}
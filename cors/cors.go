@@ -0,0 +1,168 @@
+// package cors answers CORS preflights and decorates httpx responses with CORS headers,
+// rejecting disallowed requests with JSON errors instead of silent browser failures.
+package cors
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gabivlj/httpx"
+)
+
+// Codes returned to rejected cross origin requests
+var (
+	ErrOriginNotAllowed = httpx.NewCode("CORS_ORIGIN_NOT_ALLOWED", http.StatusForbidden)
+	ErrMethodNotAllowed = httpx.NewCode("CORS_METHOD_NOT_ALLOWED", http.StatusForbidden)
+	ErrHeaderNotAllowed = httpx.NewCode("CORS_HEADER_NOT_ALLOWED", http.StatusForbidden)
+)
+
+// Config configures the allowed cross origin requests
+type Config struct {
+	// AllowedOrigins are the allowed origins like "https://example.com", "*" allows any origin
+	AllowedOrigins []string
+	// AllowedMethods are the allowed methods, GET, HEAD and POST by default
+	AllowedMethods []string
+	// AllowedHeaders are the request headers allowed besides the CORS-safelisted ones, "*" allows any header
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers the browser lets the client read
+	ExposedHeaders []string
+	// AllowCredentials lets the browser send cookies and credentials
+	AllowCredentials bool
+	// MaxAge is how long browsers can cache a preflight response, in seconds
+	MaxAge int
+}
+
+// CORS handles the cross origin requests as its Config says
+type CORS struct {
+	cfg Config
+}
+
+// New returns a CORS for the config
+func New(cfg Config) *CORS {
+	if len(cfg.AllowedMethods) == 0 {
+		cfg.AllowedMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	}
+
+	return &CORS{cfg: cfg}
+}
+
+// Middleware handles the cross origin requests of a httpx handler. Preflights only reach the middleware if the
+// router routes OPTIONS requests to the handler, use Handler to wrap a whole router instead.
+func (c *CORS) Middleware(next httpx.Handler) httpx.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if err := c.handle(w, r); err != nil {
+			return err
+		}
+
+		return next(w, r)
+	}
+}
+
+// Handler handles the cross origin requests before calling next, answering preflights itself.
+// Example:
+//
+//	c := cors.New(cors.Config{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true})
+//	http.ListenAndServe(":8080", c.Handler(mux))
+func (c *CORS) Handler(next http.Handler) http.Handler {
+	return httpx.H(func(w http.ResponseWriter, r *http.Request) error {
+		if err := c.handle(w, r); err != nil {
+			return err
+		}
+
+		next.ServeHTTP(w, r)
+		return nil
+	})
+}
+
+// handle sets the CORS headers, it returns a response for preflights and rejected requests
+func (c *CORS) handle(w http.ResponseWriter, r *http.Request) error {
+	header := w.Header()
+	origin := r.Header.Get("Origin")
+	if !c.anyOrigin() || c.cfg.AllowCredentials {
+		header.Add("Vary", "Origin")
+	}
+
+	if origin == "" || sameOrigin(r, origin) {
+		return nil
+	}
+
+	if !c.allowedOrigin(origin) {
+		return ErrOriginNotAllowed.JSON(origin)
+	}
+
+	if c.anyOrigin() && !c.cfg.AllowCredentials {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+
+	if c.cfg.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	method := r.Header.Get("Access-Control-Request-Method")
+	if r.Method != http.MethodOptions || method == "" {
+		if len(c.cfg.ExposedHeaders) > 0 {
+			header.Set("Access-Control-Expose-Headers", strings.Join(c.cfg.ExposedHeaders, ", "))
+		}
+
+		return nil
+	}
+
+	header.Add("Vary", "Access-Control-Request-Method")
+	header.Add("Vary", "Access-Control-Request-Headers")
+	if !slices.Contains(c.cfg.AllowedMethods, method) {
+		return ErrMethodNotAllowed.JSON(method)
+	}
+
+	requested := r.Header.Get("Access-Control-Request-Headers")
+	for _, name := range strings.Split(requested, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" && !c.allowedHeader(name) {
+			return ErrHeaderNotAllowed.JSON(name)
+		}
+	}
+
+	header.Set("Access-Control-Allow-Methods", strings.Join(c.cfg.AllowedMethods, ", "))
+	if requested != "" {
+		header.Set("Access-Control-Allow-Headers", requested)
+	}
+
+	if c.cfg.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(c.cfg.MaxAge))
+	}
+
+	return httpx.NoContent()
+}
+
+func (c *CORS) anyOrigin() bool {
+	return slices.Contains(c.cfg.AllowedOrigins, "*")
+}
+
+func (c *CORS) allowedOrigin(origin string) bool {
+	return c.anyOrigin() || slices.ContainsFunc(c.cfg.AllowedOrigins, func(allowed string) bool {
+		return strings.EqualFold(allowed, origin)
+	})
+}
+
+// safelistedHeaders are always allowed
+var safelistedHeaders = []string{"accept", "accept-language", "content-language", "content-type"}
+
+func (c *CORS) allowedHeader(name string) bool {
+	if slices.Contains(safelistedHeaders, strings.ToLower(name)) {
+		return true
+	}
+
+	return slices.ContainsFunc(c.cfg.AllowedHeaders, func(allowed string) bool {
+		return allowed == "*" || strings.EqualFold(allowed, name)
+	})
+}
+
+// sameOrigin returns true when the origin is the host of the request, browsers send Origin on same origin POSTs too
+func sameOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
@@ -0,0 +1,178 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gabivlj/httpx"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    Config
+		method string
+		header http.Header
+		status int
+		code   *httpx.ErrorJSONCode
+		want   map[string]string
+	}{
+		{
+			name:   "no origin",
+			cfg:    Config{AllowedOrigins: []string{"https://app.example.com"}},
+			method: http.MethodGet,
+			status: http.StatusOK,
+			want:   map[string]string{"Access-Control-Allow-Origin": "", "Vary": "Origin"},
+		},
+		{
+			name:   "same origin",
+			cfg:    Config{AllowedOrigins: []string{"https://app.example.com"}},
+			method: http.MethodPost,
+			header: http.Header{"Origin": {"https://api.example.com"}},
+			status: http.StatusOK,
+			want:   map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name:   "allowed origin",
+			cfg:    Config{AllowedOrigins: []string{"https://app.example.com"}, ExposedHeaders: []string{"X-Total", "ETag"}},
+			method: http.MethodGet,
+			header: http.Header{"Origin": {"https://APP.example.com"}},
+			status: http.StatusOK,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":      "https://APP.example.com",
+				"Access-Control-Expose-Headers":    "X-Total, ETag",
+				"Access-Control-Allow-Credentials": "",
+				"Vary":                             "Origin",
+			},
+		},
+		{
+			name:   "any origin",
+			cfg:    Config{AllowedOrigins: []string{"*"}},
+			method: http.MethodGet,
+			header: http.Header{"Origin": {"https://other.com"}},
+			status: http.StatusOK,
+			want:   map[string]string{"Access-Control-Allow-Origin": "*", "Vary": ""},
+		},
+		{
+			name:   "any origin with credentials",
+			cfg:    Config{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+			method: http.MethodGet,
+			header: http.Header{"Origin": {"https://other.com"}},
+			status: http.StatusOK,
+			want:   map[string]string{"Access-Control-Allow-Origin": "https://other.com", "Access-Control-Allow-Credentials": "true", "Vary": "Origin"},
+		},
+		{
+			name:   "origin not allowed",
+			cfg:    Config{AllowedOrigins: []string{"https://app.example.com"}},
+			method: http.MethodGet,
+			header: http.Header{"Origin": {"https://evil.com"}},
+			status: http.StatusForbidden,
+			code:   ErrOriginNotAllowed,
+		},
+		{
+			name:   "preflight",
+			cfg:    Config{AllowedOrigins: []string{"https://app.example.com"}, AllowedMethods: []string{http.MethodPut}, AllowedHeaders: []string{"X-Request-ID"}, MaxAge: 600},
+			method: http.MethodOptions,
+			header: http.Header{
+				"Origin":                         {"https://app.example.com"},
+				"Access-Control-Request-Method":  {http.MethodPut},
+				"Access-Control-Request-Headers": {"content-type, x-request-id"},
+			},
+			status: http.StatusNoContent,
+			want: map[string]string{
+				"Access-Control-Allow-Origin":  "https://app.example.com",
+				"Access-Control-Allow-Methods": "PUT",
+				"Access-Control-Allow-Headers": "content-type, x-request-id",
+				"Access-Control-Max-Age":       "600",
+			},
+		},
+		{
+			name:   "preflight with any header",
+			cfg:    Config{AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"*"}},
+			method: http.MethodOptions,
+			header: http.Header{
+				"Origin":                         {"https://other.com"},
+				"Access-Control-Request-Method":  {http.MethodPost},
+				"Access-Control-Request-Headers": {"x-anything"},
+			},
+			status: http.StatusNoContent,
+			want:   map[string]string{"Access-Control-Allow-Methods": "GET, HEAD, POST", "Access-Control-Allow-Headers": "x-anything"},
+		},
+		{
+			name:   "preflight of a method not allowed",
+			cfg:    Config{AllowedOrigins: []string{"https://app.example.com"}},
+			method: http.MethodOptions,
+			header: http.Header{"Origin": {"https://app.example.com"}, "Access-Control-Request-Method": {http.MethodDelete}},
+			status: http.StatusForbidden,
+			code:   ErrMethodNotAllowed,
+		},
+		{
+			name:   "preflight of a header not allowed",
+			cfg:    Config{AllowedOrigins: []string{"https://app.example.com"}},
+			method: http.MethodOptions,
+			header: http.Header{
+				"Origin":                         {"https://app.example.com"},
+				"Access-Control-Request-Method":  {http.MethodPost},
+				"Access-Control-Request-Headers": {"Authorization"},
+			},
+			status: http.StatusForbidden,
+			code:   ErrHeaderNotAllowed,
+		},
+		{
+			name:   "options without a request method isn't a preflight",
+			cfg:    Config{AllowedOrigins: []string{"https://app.example.com"}},
+			method: http.MethodOptions,
+			header: http.Header{"Origin": {"https://app.example.com"}},
+			status: http.StatusOK,
+			want:   map[string]string{"Access-Control-Allow-Methods": ""},
+		},
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "https://api.example.com/users", nil)
+			for key, values := range tt.header {
+				r.Header[key] = values
+			}
+
+			w := httptest.NewRecorder()
+			New(tt.cfg).Handler(next).ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Fatalf("expected %d, got %d %s", tt.status, w.Code, w.Body.String())
+			}
+
+			if tt.code != nil && !strings.Contains(w.Body.String(), tt.code.Code) {
+				t.Fatalf("expected %s, got %s", tt.code.Code, w.Body.String())
+			}
+
+			for key, value := range tt.want {
+				if got := strings.Join(w.Header().Values(key), ", "); got != value {
+					t.Fatalf("expected %s %q, got %q", key, value, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	c := New(Config{AllowedOrigins: []string{"https://app.example.com"}})
+	handler := httpx.H(c.Middleware(func(w http.ResponseWriter, r *http.Request) error {
+		return httpx.Code(http.StatusOK)
+	}))
+
+	for origin, status := range map[string]int{"https://app.example.com": http.StatusOK, "https://evil.com": http.StatusForbidden} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != status {
+			t.Fatalf("expected %d for %s, got %d", status, origin, w.Code)
+		}
+	}
+}
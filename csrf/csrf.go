@@ -0,0 +1,262 @@
+// package csrf protects httpx handlers from cross site request forgery with tokens that must be sent
+// back by unsafe requests, rejecting the ones that don't with JSON errors.
+package csrf
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/gabivlj/httpx"
+)
+
+// Codes returned to the rejected requests
+var (
+	ErrMissingToken  = httpx.NewCode("CSRF_TOKEN_MISSING", http.StatusForbidden)
+	ErrInvalidToken  = httpx.NewCode("CSRF_TOKEN_INVALID", http.StatusForbidden)
+	ErrInvalidOrigin = httpx.NewCode("CSRF_ORIGIN_INVALID", http.StatusForbidden)
+)
+
+// tokenKey is the request value key of the token
+const tokenKey = "csrf.token"
+
+// Store keeps the token of each client. CookieStore implements the double submit pattern,
+// implement it with a server side session to use synchronizer tokens.
+type Store interface {
+	// Get returns the token of the client, or "" if it doesn't have one
+	Get(r *http.Request) (string, error)
+	// Save saves a new token for the client
+	Save(w http.ResponseWriter, r *http.Request, token string) error
+}
+
+// Config configures the protection
+type Config struct {
+	// Key signs the tokens of the default Store, it's required unless Store is set
+	Key []byte
+	// Store keeps the tokens, a secure CookieStore signed with Key by default
+	Store Store
+	// HeaderName is the header unsafe requests send the token in, "X-CSRF-Token" by default
+	HeaderName string
+	// FieldName is the form field unsafe requests send the token in when they don't use the header, "csrf_token" by default
+	FieldName string
+	// TrustedOrigins are the origins, like "https://app.example.com", allowed to send unsafe requests
+	// besides the host of the request
+	TrustedOrigins []string
+}
+
+// CSRF validates the tokens of the requests
+type CSRF struct {
+	cfg Config
+}
+
+// New returns a CSRF for the config, it panics without a Key or a Store
+func New(cfg Config) *CSRF {
+	if cfg.Store == nil {
+		if len(cfg.Key) == 0 {
+			panic("httpx/csrf: Config.Key is required to sign the tokens")
+		}
+
+		cfg.Store = &CookieStore{Key: cfg.Key, Path: "/", Secure: true}
+	}
+
+	if cfg.HeaderName == "" {
+		cfg.HeaderName = "X-CSRF-Token"
+	}
+
+	if cfg.FieldName == "" {
+		cfg.FieldName = "csrf_token"
+	}
+
+	return &CSRF{cfg: cfg}
+}
+
+// Middleware issues a token to the clients that don't have one and rejects unsafe requests (POST, PUT,
+// DELETE...) without the token of the client with ErrMissingToken or ErrInvalidToken, and the ones
+// whose Origin header isn't the host of the request or a trusted origin with ErrInvalidOrigin.
+// Example:
+//
+//	protect := csrf.New(csrf.Config{Key: key})
+//	mux := httpx.NewMux(httpx.WithMiddleware(protect.Middleware))
+//	mux.GET("/form", func(w http.ResponseWriter, r *http.Request) error {
+//		return httpx.Code(http.StatusOK).Blob("text/html", render(form, csrf.Token(r)))
+//	})
+func (c *CSRF) Middleware(next httpx.Handler) httpx.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		token, err := c.cfg.Store.Get(r)
+		if err != nil {
+			return err
+		}
+
+		issued := token == ""
+		if issued {
+			if token, err = newToken(); err != nil {
+				return err
+			}
+
+			if err := c.cfg.Store.Save(w, r, token); err != nil {
+				return err
+			}
+		}
+
+		r = httpx.Set(r, tokenKey, token)
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			return next(w, r)
+		}
+
+		if !c.sameOrigin(r) {
+			return ErrInvalidOrigin.JSON()
+		}
+
+		sent := r.Header.Get(c.cfg.HeaderName)
+		if sent == "" {
+			sent = r.PostFormValue(c.cfg.FieldName)
+		}
+
+		if sent == "" {
+			return ErrMissingToken.JSON()
+		}
+
+		if issued || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			return ErrInvalidToken.JSON()
+		}
+
+		return next(w, r)
+	}
+}
+
+// sameOrigin reports if the Origin of the request, when the browser sent one, is the host of the
+// request or a trusted origin
+func (c *CSRF) sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	if slices.Contains(c.cfg.TrustedOrigins, origin) {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	return err == nil && u.Host != "" && strings.EqualFold(u.Host, r.Host)
+}
+
+// Token returns the token of the request, to render it in forms or pass it to scripts.
+// It's empty if the request didn't go through the middleware.
+func Token(r *http.Request) string {
+	token, _ := httpx.Get[string](r, tokenKey)
+	return token
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// errNoKey is returned by CookieStore without a Key
+var errNoKey = errors.New("httpx/csrf: CookieStore needs a Key")
+
+// CookieStore keeps the token in a cookie, implementing the signed double submit pattern: the cookie has
+// the token and its HMAC, so a cookie planted by a sibling subdomain or over plain HTTP isn't accepted.
+type CookieStore struct {
+	// Key signs the tokens, it's required
+	Key []byte
+	// SessionID returns the session of the request, if it's set tokens are bound to it and stop
+	// being valid when the session changes
+	SessionID func(r *http.Request) string
+	// Name is "__Host-csrf" by default for Secure cookies without Domain, "_csrf" otherwise
+	Name string
+	// Path is "/" by default
+	Path   string
+	Domain string
+	Secure bool
+	// HttpOnly hides the cookie from scripts, leave it false if scripts read the token from the cookie
+	HttpOnly bool
+	// SameSite is http.SameSiteLaxMode by default
+	SameSite http.SameSite
+}
+
+// Get returns the token of the cookie, or "" if there's none or its signature is invalid
+func (s *CookieStore) Get(r *http.Request) (string, error) {
+	if len(s.Key) == 0 {
+		return "", errNoKey
+	}
+
+	cookie, err := r.Cookie(s.name())
+	if err != nil {
+		return "", nil
+	}
+
+	token, sig, ok := strings.Cut(cookie.Value, ".")
+	if !ok || subtle.ConstantTimeCompare([]byte(sig), []byte(s.sign(r, token))) != 1 {
+		return "", nil
+	}
+
+	return token, nil
+}
+
+// Save sets the cookie with the token and its signature
+func (s *CookieStore) Save(w http.ResponseWriter, r *http.Request, token string) error {
+	if len(s.Key) == 0 {
+		return errNoKey
+	}
+
+	sameSite := s.SameSite
+	if sameSite == 0 {
+		sameSite = http.SameSiteLaxMode
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     s.name(),
+		Value:    token + "." + s.sign(r, token),
+		Path:     s.path(),
+		Domain:   s.Domain,
+		Secure:   s.Secure,
+		HttpOnly: s.HttpOnly,
+		SameSite: sameSite,
+	})
+
+	return nil
+}
+
+func (s *CookieStore) name() string {
+	switch {
+	case s.Name != "":
+		return s.Name
+	case s.Secure && s.Domain == "" && s.path() == "/":
+		return "__Host-csrf"
+	}
+
+	return "_csrf"
+}
+
+func (s *CookieStore) path() string {
+	if s.Path == "" {
+		return "/"
+	}
+
+	return s.Path
+}
+
+// sign returns the HMAC of the token and the session of the request
+func (s *CookieStore) sign(r *http.Request, token string) string {
+	session := ""
+	if s.SessionID != nil {
+		session = s.SessionID(r)
+	}
+
+	h := hmac.New(sha256.New, s.Key)
+	h.Write([]byte(session + "!" + token))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
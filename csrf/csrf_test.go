@@ -0,0 +1,154 @@
+package csrf
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gabivlj/httpx"
+)
+
+var key = []byte("key")
+
+// issue runs a GET through the middleware and returns the cookie and the token it issued
+func issue(t *testing.T, c *CSRF) (*http.Cookie, string) {
+	t.Helper()
+	var token string
+	handler := httpx.H(c.Middleware(func(w http.ResponseWriter, r *http.Request) error {
+		token = Token(r)
+		return httpx.Code(http.StatusNoContent)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "https://example.com/form", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || token == "" {
+		t.Fatalf("expected a cookie and a token, got %v and %q", cookies, token)
+	}
+
+	return cookies[0], token
+}
+
+func TestMiddleware(t *testing.T) {
+	c := New(Config{Key: key, TrustedOrigins: []string{"https://app.example.com"}})
+	cookie, token := issue(t, c)
+	forged := *cookie
+	forged.Value = token + "." + (&CookieStore{Key: []byte("attacker")}).sign(nil, token)
+	unsigned := *cookie
+	unsigned.Value = token
+
+	tests := []struct {
+		name   string
+		method string
+		cookie *http.Cookie
+		header http.Header
+		form   url.Values
+		code   *httpx.ErrorJSONCode
+	}{
+		{name: "get without a token", method: http.MethodGet},
+		{name: "head without a token", method: http.MethodHead},
+		{name: "options without a token", method: http.MethodOptions},
+		{name: "header token", method: http.MethodPost, cookie: cookie, header: http.Header{"X-Csrf-Token": {token}}},
+		{name: "form field token", method: http.MethodPost, cookie: cookie, form: url.Values{"csrf_token": {token}}},
+		{name: "header wins over the form field", method: http.MethodPut, cookie: cookie, header: http.Header{"X-Csrf-Token": {token}}, form: url.Values{"csrf_token": {"other"}}},
+		{name: "same origin", method: http.MethodPost, cookie: cookie, header: http.Header{"X-Csrf-Token": {token}, "Origin": {"https://example.com"}}},
+		{name: "trusted origin", method: http.MethodPost, cookie: cookie, header: http.Header{"X-Csrf-Token": {token}, "Origin": {"https://app.example.com"}}},
+		{name: "missing token", method: http.MethodPost, cookie: cookie, code: ErrMissingToken},
+		{name: "wrong token", method: http.MethodDelete, cookie: cookie, header: http.Header{"X-Csrf-Token": {"other"}}, code: ErrInvalidToken},
+		{name: "no cookie", method: http.MethodPost, header: http.Header{"X-Csrf-Token": {token}}, code: ErrInvalidToken},
+		{name: "forged cookie", method: http.MethodPost, cookie: &forged, header: http.Header{"X-Csrf-Token": {token}}, code: ErrInvalidToken},
+		{name: "unsigned cookie", method: http.MethodPost, cookie: &unsigned, header: http.Header{"X-Csrf-Token": {token}}, code: ErrInvalidToken},
+		{name: "origin mismatch", method: http.MethodPost, cookie: cookie, header: http.Header{"X-Csrf-Token": {token}, "Origin": {"https://evil.com"}}, code: ErrInvalidOrigin},
+		{name: "null origin", method: http.MethodPost, cookie: cookie, header: http.Header{"X-Csrf-Token": {token}, "Origin": {"null"}}, code: ErrInvalidOrigin},
+	}
+
+	handler := httpx.H(c.Middleware(func(w http.ResponseWriter, r *http.Request) error {
+		return httpx.Code(http.StatusNoContent)
+	}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r *http.Request
+			if tt.form != nil {
+				r = httptest.NewRequest(tt.method, "https://example.com/form", strings.NewReader(tt.form.Encode()))
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			} else {
+				r = httptest.NewRequest(tt.method, "https://example.com/form", nil)
+			}
+
+			for name, values := range tt.header {
+				r.Header[name] = values
+			}
+
+			if tt.cookie != nil {
+				r.AddCookie(tt.cookie)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if tt.code == nil {
+				if w.Code != http.StatusNoContent {
+					t.Fatalf("expected the request to pass, got %d %s", w.Code, w.Body.String())
+				}
+
+				return
+			}
+
+			if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), tt.code.Code) {
+				t.Fatalf("expected a 403 %s, got %d %s", tt.code.Code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTokenIsBoundToSession(t *testing.T) {
+	session := "a"
+	store := &CookieStore{Key: key, SessionID: func(r *http.Request) string { return session }}
+	c := New(Config{Store: store})
+	cookie, token := issue(t, c)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookie)
+	if got, _ := store.Get(r); got != token {
+		t.Fatalf("expected the token of the session, got %q", got)
+	}
+
+	session = "b"
+	if got, _ := store.Get(r); got != "" {
+		t.Fatal("expected the token to be rejected once the session changes")
+	}
+}
+
+func TestCookieName(t *testing.T) {
+	tests := []struct {
+		name  string
+		store CookieStore
+		want  string
+	}{
+		{name: "secure", store: CookieStore{Secure: true}, want: "__Host-csrf"},
+		{name: "insecure", store: CookieStore{}, want: "_csrf"},
+		{name: "domain", store: CookieStore{Secure: true, Domain: "example.com"}, want: "_csrf"},
+		{name: "path", store: CookieStore{Secure: true, Path: "/app"}, want: "_csrf"},
+		{name: "custom", store: CookieStore{Secure: true, Name: "token"}, want: "token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.store.name(); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewRequiresKey(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected New to panic")
+		}
+	}()
+
+	New(Config{})
+}
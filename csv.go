@@ -0,0 +1,194 @@
+package httpx
+
+import (
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"mime"
+	"reflect"
+	"time"
+)
+
+// CSVOption configures CSV responses
+type CSVOption func(*csvOptions)
+
+type csvOptions struct {
+	comma    rune
+	noHeader bool
+}
+
+// CSVComma sets the field delimiter, ',' by default
+func CSVComma(comma rune) CSVOption {
+	return func(o *csvOptions) { o.comma = comma }
+}
+
+// CSVNoHeader skips the header row derived from struct fields
+func CSVNoHeader() CSVOption {
+	return func(o *csvOptions) { o.noHeader = true }
+}
+
+// CSV returns a text/csv response streaming records, that can be a [][]string or a slice of structs.
+// For structs, the header row is derived from the `csv:"name"` tags of the exported fields, falling back to the
+// field name. Fields tagged with `csv:"-"` are skipped. Records of other types return a 500 ErrInternal.
+// Example:
+//
+//	type Row struct {
+//		ID    int       `csv:"id"`
+//		Email string    `csv:"email"`
+//		Since time.Time `csv:"since"`
+//	}
+//
+//	return httpx.Code(http.StatusOK).Attachment("users.csv").CSV(rows)
+func (r *Response) CSV(records any, opts ...CSVOption) *Response {
+	o := &csvOptions{comma: ','}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if _, err := csvElem(records); err != nil {
+		// checked before anything is sent, a wrong type would be a truncated 200 otherwise
+		return ErrInternal.JSON(err.Error())
+	}
+
+	r = r.mutable()
+	r.resetBody(-1)
+	r.setHeader("Content-Type", "text/csv; charset=utf-8")
+	r.Copy = func(w io.Writer) error {
+		cw := csv.NewWriter(w)
+		cw.Comma = o.comma
+		if err := writeCSV(cw, records, o); err != nil {
+			return err
+		}
+
+		cw.Flush()
+		return cw.Error()
+	}
+
+	return r
+}
+
+// Attachment sets Content-Disposition so browsers download the body as a file named filename.
+func (r *Response) Attachment(filename string) *Response {
+	r = r.mutable()
+	r.setHeader("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	return r
+}
+
+// csvElem returns the struct type of the records, or nil if they're a [][]string
+func csvElem(records any) (reflect.Type, error) {
+	if _, ok := records.([][]string); ok {
+		return nil, nil
+	}
+
+	rv := reflect.ValueOf(records)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("httpx: CSV records must be a slice, got %T", records)
+	}
+
+	elem := rv.Type().Elem()
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+
+	if elem.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("httpx: CSV records must be a [][]string or a slice of structs, got %T", records)
+	}
+
+	return elem, nil
+}
+
+func writeCSV(cw *csv.Writer, records any, o *csvOptions) error {
+	elem, err := csvElem(records)
+	if err != nil {
+		return err
+	}
+
+	if elem == nil {
+		return cw.WriteAll(records.([][]string))
+	}
+
+	rv := reflect.ValueOf(records)
+	fields, header := csvFields(elem)
+	if !o.noHeader {
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+	}
+
+	row := make([]string, len(fields))
+	for i := 0; i < rv.Len(); i++ {
+		item := rv.Index(i)
+		for item.Kind() == reflect.Pointer {
+			item = item.Elem()
+		}
+
+		for j, field := range fields {
+			row[j] = ""
+			if !item.IsValid() {
+				continue
+			}
+
+			if value, err := item.FieldByIndexErr(field); err == nil {
+				row[j] = csvValue(value)
+			}
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// csvFields returns the indexes and the header names of the fields of a struct type
+func csvFields(t reflect.Type) ([][]int, []string) {
+	var fields [][]int
+	var header []string
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		name := field.Tag.Get("csv")
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fields = append(fields, field.Index)
+		header = append(header, name)
+	}
+
+	return fields, header
+}
+
+func csvValue(v reflect.Value) string {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+
+		v = v.Elem()
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Time:
+		return value.Format(time.RFC3339)
+	case encoding.TextMarshaler:
+		b, err := value.MarshalText()
+		if err != nil {
+			return ""
+		}
+
+		return string(b)
+	case fmt.Stringer:
+		return value.String()
+	}
+
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,78 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type csvUser struct {
+	ID     int       `csv:"id"`
+	Email  string    `csv:"email"`
+	Since  time.Time `csv:"since"`
+	Secret string    `csv:"-"`
+	Note   *string
+	hidden int
+}
+
+func TestCSV(t *testing.T) {
+	note := "a, \"quoted\" note"
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name    string
+		records any
+		opts    []CSVOption
+		status  int
+		want    string
+	}{
+		{
+			name:    "strings",
+			records: [][]string{{"a", "b"}, {"c", "d"}},
+			status:  http.StatusOK,
+			want:    "a,b\nc,d\n",
+		},
+		{
+			name:    "structs",
+			records: []csvUser{{ID: 1, Email: "a@example.com", Since: since, Secret: "s", Note: &note}, {ID: 2}},
+			status:  http.StatusOK,
+			want:    "id,email,since,Note\n1,a@example.com,2024-01-02T03:04:05Z,\"a, \"\"quoted\"\" note\"\n2,,0001-01-01T00:00:00Z,\n",
+		},
+		{
+			name:    "pointers without header",
+			records: []*csvUser{{ID: 1}, nil},
+			opts:    []CSVOption{CSVNoHeader(), CSVComma(';')},
+			status:  http.StatusOK,
+			want:    "1;;0001-01-01T00:00:00Z;\n;;;\n",
+		},
+		{name: "not a slice", records: csvUser{}, status: http.StatusInternalServerError},
+		{name: "slice of ints", records: []int{1, 2}, status: http.StatusInternalServerError},
+		{name: "nil", records: nil, status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			res := Code(http.StatusOK).Attachment("users.csv").CSV(tt.records, tt.opts...)
+			if err := res.Write(w, httptest.NewRequest(http.MethodGet, "/", nil)); err != nil {
+				t.Fatal(err)
+			}
+
+			if w.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, w.Code)
+			}
+
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			if w.Body.String() != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, w.Body.String())
+			}
+
+			if w.Header().Get("Content-Type") != "text/csv; charset=utf-8" || w.Header().Get("Content-Disposition") != `attachment; filename=users.csv` {
+				t.Fatalf("unexpected headers %v", w.Header())
+			}
+		})
+	}
+}
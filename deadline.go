@@ -0,0 +1,79 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"time"
+)
+
+// ErrRequestTimeout is the code returned when the client takes longer than the WithReadTimeout of the route
+// to send the request body
+var ErrRequestTimeout = NewCode("REQUEST_TIMEOUT", http.StatusRequestTimeout)
+
+// writeTimeoutGrace is the time the connection can still be written after the WithWriteTimeout of a route,
+// so the ErrTimeout response can be sent
+const writeTimeoutGrace = time.Second
+
+// WithReadTimeout sets a deadline to read the request body of the handlers, so slow clients can't keep
+// them busy. Reading the body after the deadline fails, and the handlers returning that error get a
+// 408 ErrRequestTimeout.
+// Example:
+//
+//	mux.POST("/upload", upload, httpx.WithReadTimeout(30*time.Second))
+func WithReadTimeout(d time.Duration) Option {
+	return WithMiddleware(func(next Handler) Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			_ = http.NewResponseController(w).SetReadDeadline(time.Now().Add(d))
+			err := next(w, r)
+			if isDeadlineError(err) {
+				return ErrRequestTimeout.JSON()
+			}
+
+			return err
+		}
+	})
+}
+
+// WithWriteTimeout cancels the request context of the handlers after d and sets the write deadline of the
+// connection shortly after it. The handlers returning the context error get a 504 ErrTimeout.
+// Unlike WithTimeout, the response isn't buffered so it works for streaming, but handlers have to
+// respect the context.
+func WithWriteTimeout(d time.Duration) Option {
+	return WithMiddleware(func(next Handler) Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			// older versions of net/http only reset the deadline between requests when the server has
+			// a WriteTimeout, without it the deadline would still apply to the next requests of the connection
+			rc := http.NewResponseController(w)
+			_ = rc.SetWriteDeadline(time.Now().Add(d + writeTimeoutGrace))
+			r = After(r.WithContext(ctx), func(http.ResponseWriter, *http.Request, AfterInfo) {
+				_ = rc.SetWriteDeadline(time.Time{})
+			})
+
+			err := next(w, r)
+			if errors.Is(err, context.DeadlineExceeded) && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return ErrTimeout.JSON()
+			}
+
+			return err
+		}
+	})
+}
+
+// isDeadlineError returns true if err is an error reading past a connection deadline
+func isDeadlineError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	switch err.(type) {
+	case *Response, *ErrorJSONCode:
+		return false
+	}
+
+	return errors.Is(err, os.ErrDeadlineExceeded)
+}
@@ -0,0 +1,250 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidJSON is the code returned by DecodeJSON when the body can't be decoded, its extra payload is a JSONError.
+var ErrInvalidJSON = NewCode("INVALID_JSON", http.StatusBadRequest)
+
+// Limits applied by Strict when DecodeMaxDepth or DecodeMaxBytes aren't passed
+var (
+	DefaultJSONMaxDepth       = 32
+	DefaultJSONMaxBytes int64 = 1 << 20
+)
+
+// JSONError describes why a JSON body couldn't be decoded, so the client can fix it.
+// Field is the path of the field, like "items.0.price", or the name of the field for unknown fields.
+// Offset is the byte where decoding failed.
+type JSONError struct {
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+	Offset  int64  `json:"offset"`
+}
+
+// DecodeOption configures DecodeJSON
+type DecodeOption func(*decodeOptions)
+
+type decodeOptions struct {
+	strict   bool
+	maxDepth int
+	maxBytes int64
+}
+
+// Strict rejects unknown fields and bodies with more than one JSON document, and limits the depth and size
+// of the body to DefaultJSONMaxDepth and DefaultJSONMaxBytes if they aren't set.
+func Strict() DecodeOption {
+	return func(o *decodeOptions) { o.strict = true }
+}
+
+// DecodeMaxDepth rejects bodies with objects and arrays nested more than depth levels
+func DecodeMaxDepth(depth int) DecodeOption {
+	return func(o *decodeOptions) { o.maxDepth = depth }
+}
+
+// DecodeMaxBytes rejects bodies bigger than n bytes with ErrRequestTooLarge
+func DecodeMaxBytes(n int64) DecodeOption {
+	return func(o *decodeOptions) { o.maxBytes = n }
+}
+
+// DecodeJSON decodes the JSON body of r into dst. Bodies that aren't valid JSON or don't match dst return
+// a 400 ErrInvalidJSON response with a JSONError explaining where, bodies over the limit return ErrRequestTooLarge.
+// Example:
+//
+//	var order Order
+//	if err := httpx.DecodeJSON(r, &order, httpx.Strict()); err != nil {
+//		return err // 400 {"code": "INVALID_JSON", "extra": {"message": "expected number", "field": "items.0.price", "offset": 42}}
+//	}
+func DecodeJSON(r *http.Request, dst any, opts ...DecodeOption) error {
+	var o decodeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.strict && o.maxDepth == 0 {
+		o.maxDepth = DefaultJSONMaxDepth
+	}
+
+	if o.strict && o.maxBytes == 0 {
+		o.maxBytes = DefaultJSONMaxBytes
+	}
+
+	body := io.Reader(r.Body)
+	if o.maxBytes > 0 {
+		body = io.LimitReader(r.Body, o.maxBytes+1)
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return ErrRequestTooLarge.JSON(maxBytesErr.Limit)
+		}
+
+		return err
+	}
+
+	if o.maxBytes > 0 && int64(len(data)) > o.maxBytes {
+		return ErrRequestTooLarge.JSON(o.maxBytes)
+	}
+
+	if o.maxDepth > 0 {
+		if offset := jsonDepthExceeded(data, o.maxDepth); offset >= 0 {
+			return invalidJSON(JSONError{Message: "nested more than " + strconv.Itoa(o.maxDepth) + " levels", Offset: offset})
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if o.strict {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(dst); err != nil {
+		return jsonDecodeError(err, data, dec.InputOffset())
+	}
+
+	if o.strict {
+		offset := dec.InputOffset()
+		if err := dec.Decode(&json.RawMessage{}); err != io.EOF {
+			return invalidJSON(JSONError{Message: "the body must have a single JSON document", Offset: offset})
+		}
+	}
+
+	return nil
+}
+
+// jsonDecodeError converts the errors of encoding/json into ErrInvalidJSON responses, offset is where the
+// decoder stopped in data
+func jsonDecodeError(err error, data []byte, offset int64) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return invalidJSON(JSONError{Message: syntaxErr.Error(), Offset: syntaxErr.Offset})
+	case errors.As(err, &typeErr):
+		return invalidJSON(JSONError{
+			Message: fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value),
+			Field:   jsonPath(data, typeErr.Offset, typeErr.Field),
+			Offset:  typeErr.Offset,
+		})
+	case errors.Is(err, io.EOF):
+		return invalidJSON(JSONError{Message: "the body is empty"})
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return invalidJSON(JSONError{Message: "the body ended unexpectedly", Offset: int64(len(data))})
+	// DisallowUnknownFields has no error type, its message is pinned by TestDecodeJSON
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+		return invalidJSON(JSONError{Message: "unknown field", Field: field, Offset: offset})
+	}
+
+	return err
+}
+
+// jsonPath returns the path of the value of data that ends at offset, like "items.0.price", with the
+// indexes of arrays that the Field of json.UnmarshalTypeError doesn't have in older versions of Go.
+// It returns field if data can't be walked up to offset.
+func jsonPath(data []byte, offset int64, field string) string {
+	type frame struct {
+		array   bool
+		index   int
+		key     string
+		wantKey bool
+	}
+
+	path := func(stack []frame) string {
+		parts := make([]string, len(stack))
+		for i, f := range stack {
+			parts[i] = f.key
+			if f.array {
+				parts[i] = strconv.Itoa(f.index)
+			}
+		}
+
+		return strings.Join(parts, ".")
+	}
+
+	// advance moves the innermost object or array past a value
+	advance := func(stack []frame) {
+		if len(stack) == 0 {
+			return
+		}
+
+		if top := &stack[len(stack)-1]; top.array {
+			top.index++
+		} else {
+			top.wantKey = true
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var stack []frame
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return field
+		}
+
+		if delim, ok := tok.(json.Delim); ok && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			advance(stack)
+			continue
+		}
+
+		if top := len(stack) - 1; top >= 0 && stack[top].wantKey {
+			stack[top].key, _ = tok.(string)
+			stack[top].wantKey = false
+			continue
+		}
+
+		// objects and arrays fail as soon as they're opened, other values once they're read
+		if dec.InputOffset() >= offset {
+			return path(stack)
+		}
+
+		switch tok {
+		case json.Delim('{'):
+			stack = append(stack, frame{wantKey: true})
+		case json.Delim('['):
+			stack = append(stack, frame{array: true})
+		default:
+			advance(stack)
+		}
+	}
+}
+
+func invalidJSON(e JSONError) *Response {
+	return ErrInvalidJSON.JSON(e)
+}
+
+// jsonDepthExceeded returns the offset where data nests more than depth levels, or -1
+func jsonDepthExceeded(data []byte, depth int) int64 {
+	level, inString, escaped := 0, false, false
+	for i, c := range data {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			level++
+			if level > depth {
+				return int64(i)
+			}
+		case c == '}' || c == ']':
+			level--
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,76 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type decodeItem struct {
+	Price int `json:"price"`
+}
+
+type decodeOrder struct {
+	Items  []decodeItem         `json:"items"`
+	Labels map[string]string    `json:"labels"`
+	Nested map[string][]float64 `json:"nested"`
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		opts   []DecodeOption
+		status int
+		want   *JSONError
+	}{
+		{name: "valid", body: `{"items": [{"price": 1}]}`},
+		{name: "unknown fields are ignored by default", body: `{"id": 1}`},
+		{name: "type error in an array", body: `{"items": [{"price": 1}, {"price": "1"}]}`, want: &JSONError{Message: "expected int, got string", Field: "items.1.price", Offset: 38}},
+		{name: "object instead of a number", body: `{"items": [{"price": {"amount": 1}}]}`, want: &JSONError{Message: "expected int, got object", Field: "items.0.price", Offset: 22}},
+		{name: "type error in a map", body: `{"labels": {"a": "b", "c": 1}}`, want: &JSONError{Message: "expected string, got number", Field: "labels.c", Offset: 28}},
+		{name: "type error in nested arrays", body: `{"nested": {"a": [1, 2, "x"]}}`, want: &JSONError{Message: "expected float64, got string", Field: "nested.a.2", Offset: 27}},
+		{name: "type error at the root", body: `[1]`, want: &JSONError{Message: "expected httpx.decodeOrder, got array", Offset: 1}},
+		{name: "syntax error", body: `{"items": [}`, want: &JSONError{Message: "invalid character '}' looking for beginning of value", Offset: 12}},
+		{name: "empty", body: ``, want: &JSONError{Message: "the body is empty"}},
+		{name: "truncated", body: `{"items": [`, want: &JSONError{Message: "the body ended unexpectedly", Offset: 11}},
+		{name: "unknown field", body: `{"items": [], "id": 1}`, opts: []DecodeOption{Strict()}, want: &JSONError{Message: "unknown field", Field: "id", Offset: 22}},
+		{name: "several documents", body: `{} {}`, opts: []DecodeOption{Strict()}, want: &JSONError{Message: "the body must have a single JSON document", Offset: 2}},
+		{name: "too deep", body: `{"nested": {"a": [[1]]}}`, opts: []DecodeOption{DecodeMaxDepth(3)}, want: &JSONError{Message: "nested more than 3 levels", Offset: 18}},
+		{name: "brackets in strings don't count", body: `{"labels": {"a": "[[[["}}`, opts: []DecodeOption{DecodeMaxDepth(2)}},
+		{name: "too large", body: `{"items": []}`, opts: []DecodeOption{DecodeMaxBytes(4)}, status: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var order decodeOrder
+			err := DecodeJSON(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)), &order, tt.opts...)
+			if tt.want == nil && tt.status == 0 {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+
+				return
+			}
+
+			res, ok := err.(*Response)
+			if !ok {
+				t.Fatalf("expected a response, got %v", err)
+			}
+
+			if tt.status != 0 {
+				if res.Status() != tt.status {
+					t.Fatalf("expected %d, got %d", tt.status, res.Status())
+				}
+
+				return
+			}
+
+			if got, ok := res.ErrorExtra().(JSONError); res.ErrorCode() != ErrInvalidJSON.Code || !ok || !reflect.DeepEqual(got, *tt.want) {
+				t.Fatalf("expected %s with %+v, got %s with %+v", ErrInvalidJSON.Code, *tt.want, res.ErrorCode(), res.ErrorExtra())
+			}
+		})
+	}
+}
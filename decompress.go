@@ -0,0 +1,200 @@
+package httpx
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Codes returned by DecompressRequests
+var (
+	// ErrUnsupportedEncoding is returned for request bodies in an encoding other than gzip or deflate,
+	// its extra payload is the encoding
+	ErrUnsupportedEncoding = NewCode("UNSUPPORTED_ENCODING", http.StatusUnsupportedMediaType)
+	// ErrInvalidEncoding is returned for request bodies that are corrupted, its extra payload is the reason
+	ErrInvalidEncoding = NewCode("INVALID_ENCODING", http.StatusBadRequest)
+)
+
+// DecompressRequests decodes request bodies sent with Content-Encoding gzip or deflate, so handlers,
+// Bind and DecodeJSON read them as if they were sent as is. Decompressed bodies over limit bytes
+// (0 is no limit) fail like MaxBytes does and the client gets ErrRequestTooLarge, decompression bombs
+// can't fill the memory of handlers that read everything.
+// Other encodings get a 415 ErrUnsupportedEncoding and corrupted or truncated bodies a 400 ErrInvalidEncoding,
+// the errors of the connection, like a client that disconnects mid body, are returned as is.
+// Example:
+//
+//	mux.POST("/events", ingest, httpx.WithMiddleware(httpx.DecompressRequests(10<<20)))
+func DecompressRequests(limit int64) Middleware {
+	return func(next Handler) Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			encodings := contentEncodings(r.Header)
+			if len(encodings) == 0 {
+				return next(w, r)
+			}
+
+			for _, encoding := range encodings {
+				if encoding != "gzip" && encoding != "x-gzip" && encoding != "deflate" {
+					return ErrUnsupportedEncoding.JSON(encoding).Headers(map[string]string{"Accept-Encoding": "gzip, deflate"})
+				}
+			}
+
+			transport := &transportReader{r: r.Body}
+			body := io.Reader(transport)
+			// the last encoding was applied last, so it's removed first
+			for i := len(encodings) - 1; i >= 0; i-- {
+				decoded, err := decoder(encodings[i], body)
+				if transport.err != nil {
+					return transport.err
+				}
+
+				if err != nil {
+					return ErrInvalidEncoding.JSON(err.Error())
+				}
+
+				body = decoded
+			}
+
+			if limit > 0 {
+				body = &decompressLimit{r: body, n: limit}
+			}
+
+			r2 := r.Clone(r.Context())
+			r2.Body = struct {
+				io.Reader
+				io.Closer
+			}{&decodeErrorReader{r: body, transport: transport}, r.Body}
+			r2.Header.Del("Content-Encoding")
+			r2.Header.Del("Content-Length")
+			r2.ContentLength = -1
+
+			err := next(w, r2)
+			var maxBytesErr *http.MaxBytesError
+			var decodeErr *decodingError
+			switch {
+			case errors.As(err, &maxBytesErr) && maxBytesErr.Limit == limit:
+				return ErrRequestTooLarge.JSON(limit)
+			case errors.As(err, &decodeErr):
+				return ErrInvalidEncoding.JSON(decodeErr.err.Error())
+			}
+
+			return err
+		}
+	}
+}
+
+// contentEncodings returns the encodings of the body in the order they were applied, identity is skipped
+func contentEncodings(h http.Header) []string {
+	var encodings []string
+	for _, value := range h.Values("Content-Encoding") {
+		for _, encoding := range strings.Split(value, ",") {
+			encoding = strings.ToLower(strings.TrimSpace(encoding))
+			if encoding != "" && encoding != "identity" {
+				encodings = append(encodings, encoding)
+			}
+		}
+	}
+
+	return encodings
+}
+
+// decoder returns a reader decoding r. Deflate is zlib in HTTP, but some clients send raw deflate so
+// the zlib header is checked first.
+func decoder(encoding string, r io.Reader) (io.Reader, error) {
+	if encoding != "deflate" {
+		return gzip.NewReader(r)
+	}
+
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return flate.NewReader(br), nil
+}
+
+// decompressLimit fails with a *http.MaxBytesError when more than n bytes are read
+type decompressLimit struct {
+	r    io.Reader
+	n    int64
+	read int64
+}
+
+func (l *decompressLimit) Read(p []byte) (int, error) {
+	if l.read > l.n {
+		return 0, &http.MaxBytesError{Limit: l.n}
+	}
+
+	if int64(len(p)) > l.n-l.read+1 {
+		p = p[:l.n-l.read+1]
+	}
+
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.read > l.n {
+		return n - int(l.read-l.n), &http.MaxBytesError{Limit: l.n}
+	}
+
+	return n, err
+}
+
+// decodingError marks errors of a corrupted body, so they aren't confused with errors of the connection
+type decodingError struct {
+	err error
+}
+
+func (e *decodingError) Error() string {
+	return "decoding request body: " + e.err.Error()
+}
+
+func (e *decodingError) Unwrap() error {
+	return e.err
+}
+
+// transportReader keeps the error of the request body, like the io.ErrUnexpectedEOF of a client that
+// disconnects, so it isn't mistaken for a truncated stream
+type transportReader struct {
+	r   io.Reader
+	err error
+}
+
+func (t *transportReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if err != nil && err != io.EOF {
+		t.err = err
+	}
+
+	return n, err
+}
+
+// decodeErrorReader wraps the errors of corrupted bodies in decodingError, the errors of the
+// transport are returned as is
+type decodeErrorReader struct {
+	r         io.Reader
+	transport *transportReader
+}
+
+func (d *decodeErrorReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	var corrupt flate.CorruptInputError
+	switch {
+	case err == nil || err == io.EOF:
+	case d.transport.err != nil:
+		err = d.transport.err
+	case errors.Is(err, gzip.ErrChecksum), errors.Is(err, gzip.ErrHeader),
+		errors.Is(err, zlib.ErrChecksum), errors.Is(err, zlib.ErrHeader),
+		errors.Is(err, io.ErrUnexpectedEOF), errors.As(err, &corrupt):
+		err = &decodingError{err}
+	}
+
+	return n, err
+}
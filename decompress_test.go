@@ -0,0 +1,127 @@
+package httpx
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func compressed(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw deflate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	}
+
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// disconnectedBody returns data and then fails like the body of a client that went away
+type disconnectedBody struct {
+	r io.Reader
+}
+
+func (b disconnectedBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return n, err
+}
+
+func TestDecompressRequests(t *testing.T) {
+	payload := []byte(strings.Repeat(`{"event": "click"}`, 100))
+	gzipped := compressed(t, "gzip", payload)
+	corrupt := bytes.Clone(gzipped)
+	corrupt[len(corrupt)-5] ^= 0xff
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     io.Reader
+		limit    int64
+		status   int
+		code     *ErrorJSONCode
+		err      error
+	}{
+		{name: "identity", encoding: "identity", body: bytes.NewReader(payload)},
+		{name: "gzip", encoding: "gzip", body: bytes.NewReader(gzipped)},
+		{name: "x-gzip", encoding: "x-gzip", body: bytes.NewReader(gzipped)},
+		{name: "deflate", encoding: "deflate", body: bytes.NewReader(compressed(t, "deflate", payload))},
+		{name: "raw deflate", encoding: "deflate", body: bytes.NewReader(compressed(t, "raw deflate", payload))},
+		{name: "gzip then deflate", encoding: "gzip, deflate", body: bytes.NewReader(compressed(t, "deflate", gzipped))},
+		{name: "within the limit", encoding: "gzip", body: bytes.NewReader(gzipped), limit: int64(len(payload))},
+		{name: "over the limit", encoding: "gzip", body: bytes.NewReader(gzipped), limit: int64(len(payload)) - 1, status: http.StatusRequestEntityTooLarge, code: ErrRequestTooLarge},
+		{name: "unsupported", encoding: "br", body: bytes.NewReader(payload), status: http.StatusUnsupportedMediaType, code: ErrUnsupportedEncoding},
+		{name: "invalid header", encoding: "gzip", body: bytes.NewReader(payload), status: http.StatusBadRequest, code: ErrInvalidEncoding},
+		{name: "corrupt checksum", encoding: "gzip", body: bytes.NewReader(corrupt), status: http.StatusBadRequest, code: ErrInvalidEncoding},
+		{name: "truncated stream", encoding: "gzip", body: bytes.NewReader(gzipped[:len(gzipped)/2]), status: http.StatusBadRequest, code: ErrInvalidEncoding},
+		{name: "client disconnected", encoding: "gzip", body: disconnectedBody{bytes.NewReader(gzipped[:len(gzipped)/2])}, err: io.ErrUnexpectedEOF},
+		{name: "client disconnected in the header", encoding: "gzip", body: disconnectedBody{bytes.NewReader(gzipped[:4])}, err: io.ErrUnexpectedEOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []byte
+			handler := DecompressRequests(tt.limit)(func(w http.ResponseWriter, r *http.Request) error {
+				if tt.encoding != "identity" && r.Header.Get("Content-Encoding") != "" {
+					t.Fatal("expected the Content-Encoding header to be removed")
+				}
+
+				var err error
+				got, err = io.ReadAll(r.Body)
+				return err
+			})
+
+			r := httptest.NewRequest(http.MethodPost, "/", tt.body)
+			r.Header.Set("Content-Encoding", tt.encoding)
+			err := handler(httptest.NewRecorder(), r)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected %v, got %v", tt.err, err)
+				}
+
+				if _, ok := err.(*Response); ok {
+					t.Fatalf("expected the error of the connection, got the response %v", err)
+				}
+
+				return
+			}
+
+			if tt.code == nil {
+				if err != nil || !bytes.Equal(got, payload) {
+					t.Fatalf("expected the decompressed payload, got %d bytes and %v", len(got), err)
+				}
+
+				return
+			}
+
+			res, ok := err.(*Response)
+			if !ok || res.Status() != tt.status || res.ErrorCode() != tt.code.Code {
+				t.Fatalf("expected a %d %s, got %v", tt.status, tt.code.Code, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package httpx
+
+import (
+	"net/http"
+	"strings"
+)
+
+// DefaultBodies are the bodies sent by the responses of their status that have no body, like Code(http.StatusNotFound).
+// Only the body and its headers are used, the status is the one of the response.
+// Example:
+//
+//	httpx.DefaultBodies[http.StatusNotFound] = httpx.Code(http.StatusNotFound).Text("not found").Freeze()
+var DefaultBodies = map[int]*Response{}
+
+// DefaultBody returns the body of the responses without body whose status isn't in DefaultBodies,
+// nil keeps them empty. It's nil by default, StatusTextBody gives the error statuses a JSON code.
+// Example:
+//
+//	httpx.DefaultBody = httpx.StatusTextBody
+var DefaultBody func(code int) *Response
+
+// StatusTextBody returns the error code of the status text for 4xx and 5xx statuses, like {"code": "NOT_FOUND"},
+// and nil for the rest. It's meant to be used as DefaultBody.
+func StatusTextBody(code int) *Response {
+	text := http.StatusText(code)
+	if code < http.StatusBadRequest || text == "" {
+		return nil
+	}
+
+	text = strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text))
+	return (&ErrorJSONCode{Code: text, Status: code}).JSON()
+}
+
+// Empty removes the body of the response and opts it out of DefaultBodies and DefaultBody.
+func (r *Response) Empty() *Response {
+	r = r.mutable()
+	r.resetBody(0)
+	r.Copy = nil
+	r.empty = true
+	return r
+}
+
+// defaultBody returns the default body of the response with its status, or nil when it must stay empty
+func (r *Response) defaultBody() *Response {
+	if r.empty || !bodyAllowed(r.Code) {
+		return nil
+	}
+
+	body, ok := DefaultBodies[r.Code]
+	if !ok && DefaultBody != nil {
+		body = DefaultBody(r.Code)
+	}
+
+	if body == nil || body.Copy == nil {
+		return nil
+	}
+
+	body = body.Clone()
+	body.Code = r.Code
+	return body
+}
+
+// bodyAllowed returns false for the statuses that can't have a body
+func bodyAllowed(code int) bool {
+	return code >= http.StatusOK && code != http.StatusNoContent && code != http.StatusNotModified
+}
@@ -0,0 +1,87 @@
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DeprecationOption configures the headers of Deprecated and WithDeprecation
+type DeprecationOption func(*deprecationOptions)
+
+type deprecationOptions struct {
+	since time.Time
+}
+
+// DeprecatedSince sets the date the resource was deprecated, sent in the Deprecation header. Without it the
+// header has the unix epoch, a date in the past that only tells the resource is deprecated.
+func DeprecatedSince(since time.Time) DeprecationOption {
+	return func(o *deprecationOptions) { o.since = since }
+}
+
+// Deprecated marks the response as deprecated with the Deprecation header (RFC 9745), the Sunset header
+// (RFC 8594) with the date it will stop working, unless it's zero, and a Link with rel=deprecation to the
+// documentation, unless it's empty.
+// Example:
+//
+//	sunset := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+//	return httpx.OK(users).Deprecated(sunset, "https://example.com/docs/v2", httpx.DeprecatedSince(since))
+func (r *Response) Deprecated(sunset time.Time, link string, opts ...DeprecationOption) *Response {
+	r = r.mutable()
+	for key, value := range deprecationHeaders(sunset, opts) {
+		r.setHeader(key, value)
+	}
+
+	if link != "" {
+		r.addLink(deprecationLink(link))
+	}
+
+	return r
+}
+
+// WithDeprecation marks every response of a group or route as deprecated like Deprecated, including the ones
+// the handlers write to the http.ResponseWriter.
+// Example:
+//
+//	mux.GET("/v1/users", listUsers, httpx.WithDeprecation(sunset, "https://example.com/docs/v2"))
+func WithDeprecation(sunset time.Time, link string, opts ...DeprecationOption) Option {
+	headers := deprecationHeaders(sunset, opts)
+	return func(o *options) {
+		WithTransformer(func(res *Response, r *http.Request) *Response {
+			return res.Deprecated(sunset, link, opts...)
+		})(o)
+
+		WithMiddleware(func(next Handler) Handler {
+			return func(w http.ResponseWriter, r *http.Request) error {
+				for key, value := range headers {
+					w.Header().Set(key, value)
+				}
+
+				if link != "" {
+					w.Header().Add("Link", deprecationLink(link))
+				}
+
+				return next(w, r)
+			}
+		})(o)
+	}
+}
+
+// deprecationHeaders returns the Deprecation header as a structured field date, "@" followed by the unix seconds
+func deprecationHeaders(sunset time.Time, opts []DeprecationOption) map[string]string {
+	o := &deprecationOptions{since: time.Unix(0, 0)}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	headers := map[string]string{"Deprecation": "@" + strconv.FormatInt(o.since.Unix(), 10)}
+	if !sunset.IsZero() {
+		headers["Sunset"] = sunset.UTC().Format(http.TimeFormat)
+	}
+
+	return headers
+}
+
+func deprecationLink(link string) string {
+	return "<" + link + `>; rel="deprecation"`
+}
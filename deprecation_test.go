@@ -0,0 +1,58 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeprecated(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		sunset time.Time
+		link   string
+		opts   []DeprecationOption
+		want   map[string]string
+	}{
+		{name: "since", sunset: sunset, link: "https://example.com/docs", opts: []DeprecationOption{DeprecatedSince(since)}, want: map[string]string{
+			"Deprecation": "@1767225600",
+			"Sunset":      "Fri, 01 Jan 2027 00:00:00 GMT",
+			"Link":        `<https://example.com/docs>; rel="deprecation"`,
+		}},
+		{name: "without since", sunset: sunset, want: map[string]string{"Deprecation": "@0", "Sunset": "Fri, 01 Jan 2027 00:00:00 GMT"}},
+		{name: "without sunset", opts: []DeprecationOption{DeprecatedSince(since)}, want: map[string]string{"Deprecation": "@1767225600"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, handler := range map[string]func(http.ResponseWriter, *http.Request) error{
+				"response": func(w http.ResponseWriter, r *http.Request) error {
+					return Code(http.StatusOK).Deprecated(tt.sunset, tt.link, tt.opts...)
+				},
+				"written": func(w http.ResponseWriter, r *http.Request) error {
+					w.WriteHeader(http.StatusOK)
+					return nil
+				},
+			} {
+				mux := NewMux()
+				if name == "written" {
+					mux.GET("/", handler, WithDeprecation(tt.sunset, tt.link, tt.opts...))
+				} else {
+					mux.GET("/", handler)
+				}
+
+				w := httptest.NewRecorder()
+				mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+				for _, key := range []string{"Deprecation", "Sunset", "Link"} {
+					if got := w.Header().Get(key); got != tt.want[key] {
+						t.Fatalf("%s: expected %s %q, got %q", name, key, tt.want[key], got)
+					}
+				}
+			}
+		})
+	}
+}
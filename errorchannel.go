@@ -0,0 +1,46 @@
+package httpx
+
+import "net/http"
+
+// ErrorChannel is where the error codes of the responses are sent, combine them with |
+type ErrorChannel int
+
+const (
+	// ErrorBody sends the error codes in the JSON body of the responses
+	ErrorBody ErrorChannel = 1 << iota
+	// ErrorHeaders sends the error codes in the ErrorCodeHeader and the request id in the RequestIDHeader,
+	// for clients that only look at headers like CDNs and load balancers. Retry-After is already a header.
+	ErrorHeaders
+)
+
+// ErrorChannels are the channels where the responses of error codes send them. Without ErrorBody
+// the error responses have no body.
+// Example:
+//
+//	httpx.ErrorChannels = httpx.ErrorBody | httpx.ErrorHeaders
+var ErrorChannels = ErrorBody
+
+// ErrorCodeHeader is the header that has the error code of the response with ErrorHeaders
+var ErrorCodeHeader = "X-Error-Code"
+
+// writeErrorHeaders sets the error headers of the response if it's an error code, it returns false when
+// the body mustn't be written
+func (r *Response) writeErrorHeaders(w http.ResponseWriter, req *http.Request) bool {
+	if r.errorCode == "" {
+		return true
+	}
+
+	if ErrorChannels&ErrorHeaders != 0 {
+		w.Header().Set(ErrorCodeHeader, r.errorCode)
+		if id := RequestID(req); id != "" {
+			w.Header().Set(RequestIDHeader, id)
+		}
+	}
+
+	if ErrorChannels&ErrorBody == 0 {
+		w.Header().Del("Content-Type")
+		return false
+	}
+
+	return true
+}
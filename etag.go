@@ -0,0 +1,109 @@
+package httpx
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+// AutoETag makes every buffered body, like JSON, get an ETag computed from its content when it's the 200
+// response of a GET, so requests with a matching If-None-Match get a 304. Bodies with an ETag are rendered
+// whole in memory, even the ones bigger than MaxBufferedBody.
+var AutoETag = false
+
+// ETag sets the ETag header of the response, GET requests with a matching If-None-Match get a 304.
+// The tag is quoted if it isn't already.
+// Example:
+//
+//	return httpx.OK(user).ETag(strconv.Itoa(user.Version))
+func (r *Response) ETag(tag string) *Response {
+	r = r.mutable()
+	r.etag = quoteETag(tag)
+	return r
+}
+
+// AutoETag computes the ETag of the response from its body like the AutoETag variable, for this response only.
+func (r *Response) AutoETag() *Response {
+	r = r.mutable()
+	r.autoETag = true
+	return r
+}
+
+// entityTag returns the ETag of the response, computing it if needed
+func (r *Response) entityTag(req *http.Request) string {
+	if r.etag != "" {
+		return r.etag
+	}
+
+	if !(r.autoETag || AutoETag) || r.rendered == nil || r.Code != http.StatusOK {
+		return ""
+	}
+
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return ""
+	}
+
+	body, err := r.rendered.render(r.Copy)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(body)
+	tag := `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+	if r.compress {
+		// the compressed representation has different bytes, so the tag can only be weak
+		return "W/" + tag
+	}
+
+	return tag
+}
+
+// quoteETag quotes tag if it's not quoted, weak tags are kept as they are
+func quoteETag(tag string) string {
+	if strings.HasPrefix(tag, `"`) || strings.HasPrefix(tag, `W/"`) {
+		return tag
+	}
+
+	return `"` + tag + `"`
+}
+
+// etagMatch returns true if etag is in the list of tags of an If-Match or If-None-Match header.
+// Weak comparison ignores the W/ prefix, If-None-Match uses it and If-Match the strong one.
+func etagMatch(header, etag string, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		switch {
+		case tag == "*":
+			return true
+		case weak && strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/"):
+			return true
+		case !weak && tag == etag && !strings.HasPrefix(tag, "W/"):
+			return true
+		}
+	}
+
+	return false
+}
+
+// RequireIfMatch checks the If-Match header of a request that modifies a resource against its currentETag,
+// for optimistic locking. It returns nil when they match, a 428 PreconditionRequired when the header is missing
+// and a 412 PreconditionFailed with the current ETag when the client modified an outdated version.
+// Example:
+//
+//	if res := httpx.RequireIfMatch(r, strconv.Itoa(doc.Version)); res != nil {
+//		return res
+//	}
+func RequireIfMatch(r *http.Request, currentETag string) *Response {
+	match := r.Header.Get("If-Match")
+	if match == "" {
+		return PreconditionRequired()
+	}
+
+	currentETag = quoteETag(currentETag)
+	if !etagMatch(match, currentETag, false) {
+		return PreconditionFailed().ETag(currentETag)
+	}
+
+	return nil
+}
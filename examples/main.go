@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -49,7 +50,7 @@ func main() {
 		}).Headers(map[string]string{"X-Chainable": "headers"}).Text("Unauthorized")
 	}()
 
-	http.Handle("/stream", http.MaxBytesHandler(http.HandlerFunc(httpx.H(func(w http.ResponseWriter, r *http.Request) error {
+	http.HandleFunc("/stream", httpx.H(httpx.MaxBytes(1024*1024*10, func(w http.ResponseWriter, r *http.Request) error {
 		tmp := path.Join(os.TempDir(), "my-file")
 		fd, err := os.Create(tmp)
 		if err != nil {
@@ -58,6 +59,12 @@ func main() {
 
 		defer fd.Close()
 		_, err = io.Copy(fd, r.Body)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			// httpx.MaxBytes returns 413 {"code": "REQUEST_TOO_LARGE", "extra": 10485760}
+			return err
+		}
+
 		if err != nil {
 			return ErrCopyFile.JSON(err.Error())
 		}
@@ -68,7 +75,7 @@ func main() {
 		}
 
 		return httpx.Code(http.StatusOK).ReadCloser(fd)
-	})), 1024*1024*10))
+	})))
 
 	http.HandleFunc("/hello", httpx.H(func(w http.ResponseWriter, r *http.Request) error {
 		return fmt.Errorf("plain text and returns a 400")
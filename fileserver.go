@@ -0,0 +1,190 @@
+package httpx
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// FileServerOption configures FileServer
+type FileServerOption func(*fileServerOptions)
+
+type fileServerOptions struct {
+	spa       string
+	notFound  *ErrorJSONCode
+	forbidden *ErrorJSONCode
+	cache     func(name string) []CacheDirective
+}
+
+// FileSPA serves index for the paths without extension that don't exist, so the routes of single-page apps
+// load the app. Missing assets like /app.js still get a 404.
+func FileSPA(index string) FileServerOption {
+	return func(o *fileServerOptions) { o.spa = index }
+}
+
+// FileNotFound sets the code of missing files, ErrNotFound by default
+func FileNotFound(code *ErrorJSONCode) FileServerOption {
+	return func(o *fileServerOptions) { o.notFound = code }
+}
+
+// FileForbidden sets the code of directories without index.html and files that can't be read, ErrForbidden by default
+func FileForbidden(code *ErrorJSONCode) FileServerOption {
+	return func(o *fileServerOptions) { o.forbidden = code }
+}
+
+// FileCache sets the Cache-Control directives of each file. By default HTML files are revalidated on each
+// request and other assets are cached for an hour.
+func FileCache(cache func(name string) []CacheDirective) FileServerOption {
+	return func(o *fileServerOptions) { o.cache = cache }
+}
+
+// defaultFileCache is the default of FileCache
+func defaultFileCache(name string) []CacheDirective {
+	if path.Ext(name) == ".html" {
+		return []CacheDirective{CacheNoCache}
+	}
+
+	return []CacheDirective{CachePublic, CacheMaxAge(time.Hour)}
+}
+
+// FileServer returns a handler serving the files of fsys, like an embed.FS or os.DirFS, with range and
+// conditional requests support. Errors are returned as error codes instead of the text of http.FileServer,
+// directories are served with their index.html and never listed.
+// Example:
+//
+//	//go:embed dist
+//	var dist embed.FS
+//
+//	assets, _ := fs.Sub(dist, "dist")
+//	http.Handle("/", httpx.FileServer(assets, httpx.FileSPA("index.html")))
+func FileServer(fsys fs.FS, opts ...FileServerOption) http.Handler {
+	o := fileServerOptions{notFound: ErrNotFound, forbidden: ErrForbidden, cache: defaultFileCache}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return H(func(w http.ResponseWriter, r *http.Request) error {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			return ErrMethodNotAllowed.JSON().Headers(map[string]string{"Allow": "GET, HEAD"})
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+		if name == "" {
+			name = "."
+		}
+
+		f, info, err := openFile(fsys, name)
+		if errors.Is(err, fs.ErrNotExist) && o.spa != "" && path.Ext(name) == "" {
+			name = o.spa
+			f, info, err = openFile(fsys, name)
+		}
+
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return o.notFound.JSON()
+		case errors.Is(err, fs.ErrPermission):
+			return o.forbidden.JSON()
+		case err != nil:
+			return err
+		}
+
+		if cache := o.cache(info.Name()); len(cache) > 0 {
+			w.Header().Set("Cache-Control", cacheControl(cache))
+		}
+
+		if seeker, ok := f.(io.ReadSeeker); ok {
+			defer f.Close()
+			http.ServeContent(w, r, info.Name(), info.ModTime(), seeker)
+			return nil
+		}
+
+		if contentType := mime.TypeByExtension(path.Ext(info.Name())); contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+
+		return Code(http.StatusOK).ReadCloser(f)
+	})
+}
+
+// FS sets the file name of fsys as the body, with its Content-Type detected from the extension and its
+// modification time as Last-Modified, so conditional requests with If-Modified-Since get a 304.
+// If the file doesn't exist the response is replaced by a 404 ErrNotFound, and by a 500 ErrInternal
+// if it can't be opened. Directories are served with their index.html.
+// Example:
+//
+//	//go:embed assets
+//	var assets embed.FS
+//
+//	return httpx.Code(http.StatusOK).Cache(httpx.CacheMaxAge(time.Hour)).FS(assets, "assets/logo.png")
+func (r *Response) FS(fsys fs.FS, name string) *Response {
+	f, info, err := openFile(fsys, name)
+	switch {
+	case errors.Is(err, fs.ErrNotExist), errors.Is(err, fs.ErrInvalid):
+		return ErrNotFound.JSON()
+	case err != nil:
+		return ErrInternal.JSON()
+	}
+
+	r = r.ReadCloser(f)
+	if contentType := mime.TypeByExtension(path.Ext(info.Name())); contentType != "" {
+		r.setHeader("Content-Type", contentType)
+	}
+
+	r.modTime = info.ModTime()
+	return r
+}
+
+// notModified returns true when the response has an ETag or a modification time and the request
+// already has the latest version of it
+func (r *Response) notModified(req *http.Request, etag string) bool {
+	if r.Code != http.StatusOK || (req.Method != http.MethodGet && req.Method != http.MethodHead) {
+		return false
+	}
+
+	if match := req.Header.Get("If-None-Match"); match != "" {
+		return etag != "" && etagMatch(match, etag, true)
+	}
+
+	if r.modTime.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	return !r.modTime.Truncate(time.Second).After(since)
+}
+
+// openFile opens the file name of fsys, or its index.html if it's a directory.
+// Directories without index.html return fs.ErrPermission.
+func openFile(fsys fs.FS, name string) (fs.File, fs.FileInfo, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+
+	if !info.IsDir() {
+		return f, info, nil
+	}
+
+	f.Close()
+	f, info, err = openFile(fsys, path.Join(name, "index.html"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, fs.ErrPermission
+	}
+
+	return f, info, err
+}
@@ -1,11 +1,5 @@
 module github.com/gabivlj/httpx
 
-go 1.19
+go 1.23
 
-require (
-	github.com/julienschmidt/httprouter v1.3.0 // indirect
-	github.com/yuin/goldmark v1.4.13 // indirect
-	golang.org/x/mod v0.7.0 // indirect
-	golang.org/x/sys v0.3.0 // indirect
-	golang.org/x/tools v0.4.0 // indirect
-)
+require github.com/julienschmidt/httprouter v1.3.0
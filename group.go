@@ -0,0 +1,155 @@
+package httpx
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Option configures the handlers registered in a Group, options can be set for a whole group or per route.
+type Option func(*options)
+
+type options struct {
+	middleware   []Middleware
+	errorHandler func(error) *Response
+}
+
+// WithMiddleware wraps the handlers with the middleware, as in Chain.
+// Middleware of outer groups run before the ones of inner groups and routes.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(o *options) {
+		o.middleware = append(o.middleware, mw...)
+	}
+}
+
+// WithErrorHandler replaces DefaultErrorHandler for errors that are not httpx.Response returned by the handlers
+// and their middleware. The innermost error handler wins. Context errors still go through ContextErrorHandler.
+func WithErrorHandler(fn func(error) *Response) Option {
+	return func(o *options) {
+		o.errorHandler = fn
+	}
+}
+
+// apply wraps h with the options
+func (o *options) apply(h Handler) Handler {
+	h = Chain(h, o.middleware...)
+	if o.errorHandler == nil {
+		return h
+	}
+
+	errorHandler := o.errorHandler
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := h(w, r)
+		if err == nil {
+			return nil
+		}
+
+		errs := joinedErrors(err)
+		if len(errs) > 1 {
+			return joinedResponse(errs, errorHandler)
+		} else if len(errs) == 1 {
+			err = errs[0]
+		}
+
+		switch err.(type) {
+		case *Response, *ErrorJSONCode:
+			return err
+		}
+
+		if isContextError(err) {
+			return err
+		}
+
+		return errorHandler(err)
+	}
+}
+
+// Group registers httpx handlers under a common path prefix sharing options.
+// Example:
+//
+//	api := httpx.NewGroup(mux, "/v1", httpx.WithMiddleware(RequireUser))
+//	api.GET("/users/{id}", getUser)
+type Group struct {
+	prefix string
+	opts   []Option
+	handle func(method, path string, h http.Handler)
+}
+
+// NewGroup returns a group that registers its handlers on a http.ServeMux
+// using method patterns, like "GET /v1/users/{id}".
+func NewGroup(mux *http.ServeMux, prefix string, opts ...Option) *Group {
+	return &Group{
+		prefix: prefix,
+		opts:   opts,
+		handle: func(method, path string, h http.Handler) {
+			if method == "" {
+				mux.Handle(path, h)
+				return
+			}
+
+			mux.Handle(method+" "+path, h)
+		},
+	}
+}
+
+// Group returns a child group with the prefix appended to this group prefix,
+// its options are applied after the options of the parent group.
+func (g *Group) Group(prefix string, opts ...Option) *Group {
+	return &Group{
+		prefix: joinPath(g.prefix, prefix),
+		opts:   append(append([]Option{}, g.opts...), opts...),
+		handle: g.handle,
+	}
+}
+
+// Handle registers h for the method and path, an empty method matches every method.
+func (g *Group) Handle(method, path string, h Handler, opts ...Option) {
+	o := &options{}
+	for _, opt := range g.opts {
+		opt(o)
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	g.handle(method, joinPath(g.prefix, path), H(o.apply(h)))
+}
+
+// GET registers h for GET requests on path
+func (g *Group) GET(path string, h Handler, opts ...Option) {
+	g.Handle(http.MethodGet, path, h, opts...)
+}
+
+// POST registers h for POST requests on path
+func (g *Group) POST(path string, h Handler, opts ...Option) {
+	g.Handle(http.MethodPost, path, h, opts...)
+}
+
+// PUT registers h for PUT requests on path
+func (g *Group) PUT(path string, h Handler, opts ...Option) {
+	g.Handle(http.MethodPut, path, h, opts...)
+}
+
+// PATCH registers h for PATCH requests on path
+func (g *Group) PATCH(path string, h Handler, opts ...Option) {
+	g.Handle(http.MethodPatch, path, h, opts...)
+}
+
+// DELETE registers h for DELETE requests on path
+func (g *Group) DELETE(path string, h Handler, opts ...Option) {
+	g.Handle(http.MethodDelete, path, h, opts...)
+}
+
+// joinPath joins a prefix and a path keeping the trailing slash of the path
+func joinPath(prefix, path string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if path == "" {
+		return prefix
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	return prefix + path
+}
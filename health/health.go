@@ -0,0 +1,148 @@
+// package health has liveness and readiness handlers that aggregate named checks.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gabivlj/httpx"
+)
+
+// ErrUnhealthy is the code returned when a check fails, its extra is the Report
+var ErrUnhealthy = httpx.NewCode("UNHEALTHY", http.StatusServiceUnavailable)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check returns an error when the dependency it checks isn't healthy, it should return once ctx is done
+type Check func(ctx context.Context) error
+
+// Config configures a Checker
+type Config struct {
+	// Timeout is the time each check has to finish, 5 seconds by default
+	Timeout time.Duration
+}
+
+// Checker is a registry of named checks
+type Checker struct {
+	cfg   Config
+	mu    sync.RWMutex
+	live  []namedCheck
+	ready []namedCheck
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Report is the result of running the checks
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Result is the result of a single check
+type Result struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// New returns an empty Checker
+func New(cfg Config) *Checker {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	return &Checker{cfg: cfg}
+}
+
+// Live adds a liveness check, they're run by both Healthz and Readyz. Liveness checks should
+// only fail when the process has to be restarted.
+func (c *Checker) Live(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.live = append(c.live, namedCheck{name, check})
+}
+
+// Ready adds a readiness check, they're run by Readyz. Use them for the dependencies the process
+// needs to serve requests, like databases.
+func (c *Checker) Ready(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ready = append(c.ready, namedCheck{name, check})
+}
+
+// Healthz is the liveness handler, it returns the Report of the liveness checks, with a 503
+// ErrUnhealthy if any of them fails.
+// Example:
+//
+//	checker := health.New(health.Config{Timeout: time.Second})
+//	checker.Ready("db", db.PingContext)
+//	mux.GET("/healthz", checker.Healthz)
+//	mux.GET("/readyz", checker.Readyz)
+func (c *Checker) Healthz(w http.ResponseWriter, r *http.Request) error {
+	c.mu.RLock()
+	checks := c.live
+	c.mu.RUnlock()
+	return c.respond(c.run(r.Context(), checks))
+}
+
+// Readyz is the readiness handler, it's like Healthz running both the liveness and readiness checks.
+func (c *Checker) Readyz(w http.ResponseWriter, r *http.Request) error {
+	c.mu.RLock()
+	checks := append(c.live[:len(c.live):len(c.live)], c.ready...)
+	c.mu.RUnlock()
+	return c.respond(c.run(r.Context(), checks))
+}
+
+func (c *Checker) respond(report Report) error {
+	if report.Status != StatusOK {
+		return ErrUnhealthy.JSON(report).Cache(httpx.CacheNoStore)
+	}
+
+	return httpx.OK(report).Cache(httpx.CacheNoStore)
+}
+
+// run runs the checks concurrently and returns their report
+func (c *Checker) run(ctx context.Context, checks []namedCheck) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := c.runCheck(ctx, check.check)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusFail
+			}
+		}()
+	}
+
+	wg.Wait()
+	return report
+}
+
+// runCheck runs a single check with the timeout
+func (c *Checker) runCheck(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := Result{Status: StatusOK, LatencyMS: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		result.Status, result.Error = StatusFail, err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,124 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gabivlj/httpx"
+)
+
+func ok(ctx context.Context) error { return nil }
+
+func failing(ctx context.Context) error { return errors.New("connection refused") }
+
+// hanging blocks until the timeout of the check
+func hanging(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestHandlers(t *testing.T) {
+	tests := []struct {
+		name    string
+		live    map[string]Check
+		ready   map[string]Check
+		handler func(c *Checker) httpx.Handler
+		status  int
+		checks  map[string]Result
+	}{
+		{name: "no checks", handler: func(c *Checker) httpx.Handler { return c.Healthz }, status: http.StatusOK, checks: map[string]Result{}},
+		{
+			name:    "healthz skips the readiness checks",
+			live:    map[string]Check{"process": ok},
+			ready:   map[string]Check{"db": failing},
+			handler: func(c *Checker) httpx.Handler { return c.Healthz },
+			status:  http.StatusOK,
+			checks:  map[string]Result{"process": {Status: StatusOK}},
+		},
+		{
+			name:    "readyz runs both",
+			live:    map[string]Check{"process": ok},
+			ready:   map[string]Check{"db": ok, "cache": ok},
+			handler: func(c *Checker) httpx.Handler { return c.Readyz },
+			status:  http.StatusOK,
+			checks:  map[string]Result{"process": {Status: StatusOK}, "db": {Status: StatusOK}, "cache": {Status: StatusOK}},
+		},
+		{
+			name:    "failing readiness check",
+			live:    map[string]Check{"process": ok},
+			ready:   map[string]Check{"db": failing},
+			handler: func(c *Checker) httpx.Handler { return c.Readyz },
+			status:  http.StatusServiceUnavailable,
+			checks:  map[string]Result{"process": {Status: StatusOK}, "db": {Status: StatusFail, Error: "connection refused"}},
+		},
+		{
+			name:    "failing liveness check",
+			live:    map[string]Check{"process": failing},
+			handler: func(c *Checker) httpx.Handler { return c.Healthz },
+			status:  http.StatusServiceUnavailable,
+			checks:  map[string]Result{"process": {Status: StatusFail, Error: "connection refused"}},
+		},
+		{
+			name:    "timeout",
+			ready:   map[string]Check{"db": hanging},
+			handler: func(c *Checker) httpx.Handler { return c.Readyz },
+			status:  http.StatusServiceUnavailable,
+			checks:  map[string]Result{"db": {Status: StatusFail, Error: context.DeadlineExceeded.Error()}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(Config{Timeout: 10 * time.Millisecond})
+			for name, check := range tt.live {
+				c.Live(name, check)
+			}
+
+			for name, check := range tt.ready {
+				c.Ready(name, check)
+			}
+
+			w := httptest.NewRecorder()
+			httpx.H(tt.handler(c)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != tt.status || w.Header().Get("Cache-Control") != "no-store" {
+				t.Fatalf("expected an uncached %d, got %d with %q", tt.status, w.Code, w.Header().Get("Cache-Control"))
+			}
+
+			var report Report
+			if tt.status == http.StatusOK {
+				err := json.Unmarshal(w.Body.Bytes(), &report)
+				if err != nil || report.Status != StatusOK {
+					t.Fatalf("expected an ok report, got %s", w.Body.String())
+				}
+			} else {
+				var body struct {
+					Code  string `json:"code"`
+					Extra Report `json:"extra"`
+				}
+
+				err := json.Unmarshal(w.Body.Bytes(), &body)
+				if err != nil || body.Code != ErrUnhealthy.Code || body.Extra.Status != StatusFail {
+					t.Fatalf("expected a failed report, got %s", w.Body.String())
+				}
+
+				report = body.Extra
+			}
+
+			if len(report.Checks) != len(tt.checks) {
+				t.Fatalf("expected %d checks, got %+v", len(tt.checks), report.Checks)
+			}
+
+			for name, want := range tt.checks {
+				got := report.Checks[name]
+				if got.Status != want.Status || got.Error != want.Error || got.LatencyMS < 0 {
+					t.Fatalf("expected %s to be %+v, got %+v", name, want, got)
+				}
+			}
+		})
+	}
+}
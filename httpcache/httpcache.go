@@ -0,0 +1,206 @@
+// package httpcache caches the responses of GET httpx handlers in memory or in a pluggable Store,
+// serving them again without running the handler.
+package httpcache
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gabivlj/httpx"
+)
+
+// Header is set to HIT, MISS or BYPASS on the responses that go through the cache
+const Header = "X-Cache"
+
+// Store keeps the cached responses
+type Store interface {
+	Get(ctx context.Context, key string) (*httpx.Snapshot, bool, error)
+	Set(ctx context.Context, key string, snap *httpx.Snapshot, ttl time.Duration) error
+}
+
+// Config configures the cache
+type Config struct {
+	// Store keeps the responses, a MemoryStore by default
+	Store Store
+	// TTL is how long responses are cached when they don't have max-age, 1 minute by default
+	TTL time.Duration
+	// KeyFunc returns the key of the request, by default its route, path, sorted query and Accept-Encoding
+	KeyFunc func(r *http.Request) string
+}
+
+// Cache caches responses
+type Cache struct {
+	cfg Config
+}
+
+// New returns a Cache for the config
+func New(cfg Config) *Cache {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+
+	if cfg.TTL <= 0 {
+		cfg.TTL = time.Minute
+	}
+
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = Key
+	}
+
+	return &Cache{cfg: cfg}
+}
+
+// Key is the default KeyFunc
+func Key(r *http.Request) string {
+	return httpx.Route(r) + " " + r.URL.Path + "?" + r.URL.Query().Encode() + " " + r.Header.Get("Accept-Encoding")
+}
+
+// Middleware serves GET requests from the cache, running the handler only on misses. Only 200 responses are
+// cached, for their max-age or s-maxage if they have one, and never the ones with Cache-Control no-store or
+// private, Set-Cookie or a Vary header besides Accept-Encoding. Requests with Cache-Control no-cache or
+// no-store skip the cache.
+// Example:
+//
+//	cache := httpcache.New(httpcache.Config{TTL: 30 * time.Second})
+//	mux.GET("/products", listProducts, httpx.WithMiddleware(cache.Middleware))
+func (c *Cache) Middleware(next httpx.Handler) httpx.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if r.Method != http.MethodGet {
+			return next(w, r)
+		}
+
+		if directives := cacheControl(r.Header); directives["no-cache"] || directives["no-store"] {
+			w.Header().Set(Header, "BYPASS")
+			return next(w, r)
+		}
+
+		ctx := r.Context()
+		key := c.cfg.KeyFunc(r)
+		if snap, ok, err := c.cfg.Store.Get(ctx, key); err == nil && ok {
+			return snap.Response().Headers(map[string]string{Header: "HIT"})
+		}
+
+		snap, err := httpx.Capture(next, r)
+		res := snap.Response().Headers(map[string]string{Header: "MISS"})
+		if err != nil {
+			return res
+		}
+
+		if ttl, ok := c.ttl(snap); ok {
+			if err := c.cfg.Store.Set(ctx, key, snap, ttl); err != nil {
+				return err
+			}
+		}
+
+		return res
+	}
+}
+
+// ttl returns how long snap can be cached
+func (c *Cache) ttl(snap *httpx.Snapshot) (time.Duration, bool) {
+	if snap.Status != http.StatusOK || len(snap.Header.Values("Set-Cookie")) > 0 {
+		return 0, false
+	}
+
+	for _, vary := range snap.Header.Values("Vary") {
+		for _, header := range strings.Split(vary, ",") {
+			if !strings.EqualFold(strings.TrimSpace(header), "Accept-Encoding") {
+				return 0, false
+			}
+		}
+	}
+
+	directives := cacheControl(snap.Header)
+	if directives["no-store"] || directives["private"] || directives["no-cache"] {
+		return 0, false
+	}
+
+	for _, name := range []string{"s-maxage", "max-age"} {
+		if seconds, err := strconv.Atoi(directiveValue(snap.Header, name)); err == nil {
+			return time.Duration(seconds) * time.Second, seconds > 0
+		}
+	}
+
+	return c.cfg.TTL, true
+}
+
+// cacheControl returns the directives without value of the Cache-Control header
+func cacheControl(h http.Header) map[string]bool {
+	directives := map[string]bool{}
+	for _, value := range h.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			directives[strings.ToLower(strings.TrimSpace(directive))] = true
+		}
+	}
+
+	return directives
+}
+
+// directiveValue returns the value of the Cache-Control directive name
+func directiveValue(h http.Header, name string) string {
+	for _, value := range h.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			key, value, ok := strings.Cut(strings.TrimSpace(directive), "=")
+			if ok && strings.EqualFold(key, name) {
+				return strings.Trim(value, `"`)
+			}
+		}
+	}
+
+	return ""
+}
+
+// MemoryStore keeps the responses in memory
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+type memoryEntry struct {
+	snap    *httpx.Snapshot
+	expires time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]memoryEntry{}}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) (*httpx.Snapshot, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false, nil
+	}
+
+	return entry.snap.Clone(), true, nil
+}
+
+func (s *MemoryStore) Set(ctx context.Context, key string, snap *httpx.Snapshot, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.sweep(now)
+	s.entries[key] = memoryEntry{snap: snap.Clone(), expires: now.Add(ttl)}
+	return nil
+}
+
+// sweep deletes the expired entries at most once a minute
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if now.After(entry.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package httpcache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gabivlj/httpx"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		headers map[string]string
+		method  string
+		request map[string]string
+		want    []string
+	}{
+		{name: "cached", status: http.StatusOK, want: []string{"MISS", "HIT"}},
+		{name: "max-age", status: http.StatusOK, headers: map[string]string{"Cache-Control": "public, max-age=60"}, want: []string{"MISS", "HIT"}},
+		{name: "max-age 0", status: http.StatusOK, headers: map[string]string{"Cache-Control": "max-age=0"}, want: []string{"MISS", "MISS"}},
+		{name: "no-store", status: http.StatusOK, headers: map[string]string{"Cache-Control": "no-store"}, want: []string{"MISS", "MISS"}},
+		{name: "private", status: http.StatusOK, headers: map[string]string{"Cache-Control": "private, max-age=60"}, want: []string{"MISS", "MISS"}},
+		{name: "set-cookie", status: http.StatusOK, headers: map[string]string{"Set-Cookie": "a=b"}, want: []string{"MISS", "MISS"}},
+		{name: "vary accept-encoding", status: http.StatusOK, headers: map[string]string{"Vary": "Accept-Encoding"}, want: []string{"MISS", "HIT"}},
+		{name: "vary other header", status: http.StatusOK, headers: map[string]string{"Vary": "Accept-Encoding, Authorization"}, want: []string{"MISS", "MISS"}},
+		{name: "errors", status: http.StatusNotFound, want: []string{"MISS", "MISS"}},
+		{name: "request no-cache", status: http.StatusOK, request: map[string]string{"Cache-Control": "no-cache"}, want: []string{"BYPASS", "BYPASS"}},
+		{name: "post", status: http.StatusOK, method: http.MethodPost, want: []string{"", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			mux := httpx.NewMux(httpx.WithMiddleware(New(Config{}).Middleware))
+			handler := func(w http.ResponseWriter, r *http.Request) error {
+				calls++
+				return httpx.Code(tt.status).Headers(tt.headers).Text("products")
+			}
+
+			mux.GET("/products", handler)
+			mux.POST("/products", handler)
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			wantCalls := 0
+			for _, want := range tt.want {
+				r := httptest.NewRequest(method, "/products", nil)
+				for key, value := range tt.request {
+					r.Header.Set(key, value)
+				}
+
+				w := httptest.NewRecorder()
+				mux.ServeHTTP(w, r)
+				if got := w.Header().Get(Header); got != want {
+					t.Fatalf("expected %s %q, got %q", Header, want, got)
+				}
+
+				if w.Code != tt.status || w.Body.String() != "products" {
+					t.Fatalf("expected %d products, got %d %q", tt.status, w.Code, w.Body.String())
+				}
+
+				if want != "HIT" {
+					wantCalls++
+				}
+			}
+
+			if calls != wantCalls {
+				t.Fatalf("expected the handler to run %d times, it ran %d", wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	mux := httpx.NewMux(httpx.WithMiddleware(New(Config{}).Middleware))
+	calls := 0
+	mux.GET("/products", func(w http.ResponseWriter, r *http.Request) error {
+		calls++
+		return httpx.Code(http.StatusOK).Text(r.URL.RawQuery)
+	})
+
+	for _, target := range []string{"/products?a=1&b=2", "/products?b=2&a=1", "/products?a=2"} {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	if calls != 2 {
+		t.Fatalf("expected the reordered query to hit the cache, the handler ran %d times", calls)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	c := New(Config{TTL: time.Hour})
+	tests := []struct {
+		cacheControl string
+		want         time.Duration
+		ok           bool
+	}{
+		{cacheControl: "", want: time.Hour, ok: true},
+		{cacheControl: "max-age=30", want: 30 * time.Second, ok: true},
+		{cacheControl: "max-age=30, s-maxage=60", want: time.Minute, ok: true},
+		{cacheControl: `max-age="10"`, want: 10 * time.Second, ok: true},
+		{cacheControl: "no-cache"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cacheControl, func(t *testing.T) {
+			snap := &httpx.Snapshot{Status: http.StatusOK, Header: http.Header{}}
+			if tt.cacheControl != "" {
+				snap.Header.Set("Cache-Control", tt.cacheControl)
+			}
+
+			if got, ok := c.ttl(snap); got != tt.want || ok != tt.ok {
+				t.Fatalf("expected %v %v, got %v %v", tt.want, tt.ok, got, ok)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	s := NewMemoryStore()
+	snap := &httpx.Snapshot{Status: http.StatusOK, Header: http.Header{}, Body: []byte("a")}
+	if err := s.Set(context.Background(), "a", snap, -time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, _ := s.Get(context.Background(), "a"); ok {
+		t.Fatal("expected an expired entry to be missed")
+	}
+
+	s.Set(context.Background(), "b", snap, time.Minute)
+	got, ok, _ := s.Get(context.Background(), "b")
+	if !ok || string(got.Body) != "a" {
+		t.Fatal("expected the entry")
+	}
+
+	got.Body[0] = 'b'
+	if again, _, _ := s.Get(context.Background(), "b"); string(again.Body) != "a" {
+		t.Fatal("expected the store to keep its own copy")
+	}
+}
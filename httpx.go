@@ -3,11 +3,18 @@ package httpx
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"maps"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 )
@@ -21,25 +28,129 @@ var DefaultErrorHandler = func(err error) *Response {
 var DefaultAfterMiddleware = func(w http.ResponseWriter, r *http.Request, e error) {}
 
 // CopyErrorHandler is the function that will be fired after an error copying to the http.ResponseWriter
-var CopyErrorHandler = func(err error) {}
+var CopyErrorHandler = func(r *http.Request, err error) {}
+
+// ClientAbortHandler is the function that will be fired instead of CopyErrorHandler when
+// the copy failed because the client went away (closed connection, canceled request...)
+var ClientAbortHandler = func(r *http.Request, err error) {}
+
+// AlreadyWrittenHandler is the function that will be fired instead of writing the returned response when
+// the handler already sent the header writing to the http.ResponseWriter directly, the response is dropped.
+var AlreadyWrittenHandler = func(r *http.Request, res *Response) {}
 
 // Response is the standard struct that implements error for httpx. It should be mainly used to return errors
 type Response struct {
 	Code    int
 	headers map[string]string
 	Copy    func(io.Writer) error
+
+	// multiHeaders are headers with many values, like Set-Cookie, replayed from a Snapshot
+	multiHeaders http.Header
+
+	compress      bool
+	length        int64
+	hasLength     bool
+	closer        io.Closer
+	flushInterval time.Duration
+	stream        func(w *StreamWriter) error // the function of a Stream body, Clone binds it to the clone
+	frozen        bool
+	buffered      bool
+	chunked       bool
+	errorCode     string
+	errorExtra    any
+	rendered      *renderedBody
+	once          *onceBody
+	trailers      map[string]string
+	pushes        []string
+	earlyHints    []string
+	modTime       time.Time
+	etag          string
+	autoETag      bool
+	page          *PageInfo
+	empty         bool
 }
 
+// Error returns the body of the response, or the status text if it has no body.
+// JSON bodies are rendered once, so logging the error doesn't encode them again. Bodies that can only
+// be read once, like Reader, are kept in memory so the client still gets them.
 func (r *Response) Error() string {
-	b := &bytes.Buffer{}
-	err := r.Copy(b)
-	if err != nil {
+	if r.Copy == nil {
+		return http.StatusText(r.Code)
+	}
+
+	if memo := r.memo(); memo != nil {
+		body, err := memo.render(r.Copy)
+		if err != nil {
+			return fmt.Errorf("reading all bytes from Reader: %w", err).Error()
+		}
+
+		return string(body)
+	}
+
+	b := getBuffer()
+	defer putBuffer(b)
+	if err := r.Copy(b); err != nil {
 		return fmt.Errorf("reading all bytes from Reader: %w", err).Error()
 	}
 
 	return b.String()
 }
 
+// Status returns the status code of the response
+func (r *Response) Status() int {
+	return r.Code
+}
+
+// ErrorCode returns the code of the ErrorJSONCode the response was created from, or "" if it wasn't
+func (r *Response) ErrorCode() string {
+	return r.errorCode
+}
+
+// ErrorExtra returns the extra payload of the ErrorJSONCode the response was created from, for transformers
+// that render errors differently
+func (r *Response) ErrorExtra() any {
+	return r.errorExtra
+}
+
+// HeaderMap returns a copy of the headers set on the response
+func (r *Response) HeaderMap() map[string]string {
+	return maps.Clone(r.headers)
+}
+
+// Body renders the body of the response. JSON bodies are rendered once and the result is reused when
+// writing them, don't modify the returned slice. Bodies that can only be read once, like Reader, are
+// kept in memory the same way, and fail with ErrBodyConsumed if they were already written.
+func (r *Response) Body() ([]byte, error) {
+	if r.Copy == nil {
+		return nil, nil
+	}
+
+	if memo := r.memo(); memo != nil {
+		return memo.render(r.Copy)
+	}
+
+	b := getBuffer()
+	defer putBuffer(b)
+	if err := r.Copy(b); err != nil {
+		return nil, err
+	}
+
+	return bytes.Clone(b.Bytes()), nil
+}
+
+// memo returns where the body is memoized when it's rendered, nil if it's not
+func (r *Response) memo() *renderedBody {
+	if r.rendered != nil {
+		return r.rendered
+	}
+
+	if r.once != nil {
+		return &r.once.memo
+	}
+
+	return nil
+}
+
 // Handler is the httpx handler, prepared to be the same as a http handler where you're able to return errors
 type Handler func(w http.ResponseWriter, r *http.Request) error
 
@@ -49,14 +160,24 @@ type HttpRouterHandler func(w http.ResponseWriter, r *http.Request, p httprouter
 // H wraps a httpx handler with a http.HandlerFunc
 func H(h Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		fireAfterMiddleware(h(w, r), w, r)
+		start := time.Now()
+		r = withValues(r)
+		sw := wrapWriter(w)
+		err := h(sw, r)
+		fireAfterMiddleware(err, sw, r)
+		fireAfter(sw, r, err, start)
 	}
 }
 
 // HRouter wraps a httpxrouter handler with a httprouter.Handle
 func HRouter(h HttpRouterHandler) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
-		fireAfterMiddleware(h(w, r, p), w, r)
+		start := time.Now()
+		r = withValues(r)
+		sw := wrapWriter(w)
+		err := h(sw, r, p)
+		fireAfterMiddleware(err, sw, r)
+		fireAfter(sw, r, err, start)
 	}
 }
 
@@ -66,49 +187,267 @@ func fireAfterMiddleware(err error, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, ok := err.(*Response)
-	if !ok {
-		res = DefaultErrorHandler(err)
-	}
+	res := responseOf(err)
+	Set(r, responseKey, res)
 
-	for key, value := range res.headers {
-		w.Header().Set(key, value)
+	if clientGone(r, err) {
+		ClientAbortHandler(r, err)
+		DefaultAfterMiddleware(w, r, res)
+		return
 	}
 
-	w.WriteHeader(res.Code)
-	if res.Copy == nil {
+	if sw, ok := w.(*statusWriter); ok && sw.wroteHeader {
+		AlreadyWrittenHandler(r, res)
+		DefaultAfterMiddleware(w, r, res)
 		return
 	}
 
-	err = res.Copy(w)
-	if err != nil {
-		CopyErrorHandler(err)
+	if transformed := transform(res, r); transformed != res {
+		res = transformed
+		Set(r, responseKey, res)
+	}
+
+	var sw *statusWriter
+	if tracingWrites() {
+		sw = wrapWriter(w)
+		w = sw
+	}
+
+	prepared := res.Prepare(w, r)
+	BeforeWrite(prepared, r)
+	if replaced := prepared.Response(); replaced != res {
+		res = replaced
+		Set(r, responseKey, res)
+	}
+
+	var copyErr error
+	if sw != nil {
+		copyErr = commit(prepared, sw, r)
+	} else {
+		copyErr = prepared.Commit()
+	}
+
+	if copyErr != nil {
+		handleCopyError(r, copyErr)
 	}
 
 	DefaultAfterMiddleware(w, r, res)
 }
 
+// responseOf returns the response of an error returned by a handler
+func responseOf(err error) *Response {
+	switch err := err.(type) {
+	case *Response:
+		return err
+	case *ErrorJSONCode:
+		return err.JSON()
+	}
+
+	if errs := joinedErrors(err); len(errs) == 1 {
+		return responseOf(errs[0])
+	} else if len(errs) > 1 {
+		return joinedResponse(errs, DefaultErrorHandler)
+	}
+
+	if isContextError(err) {
+		return ContextErrorHandler(err)
+	}
+
+	return DefaultErrorHandler(err)
+}
+
+// handleCopyError fires ClientAbortHandler or CopyErrorHandler depending on the cause of err
+func handleCopyError(r *http.Request, err error) {
+	if isClientAbort(r, err) {
+		ClientAbortHandler(r, err)
+		return
+	}
+
+	CopyErrorHandler(r, err)
+}
+
+// isClientAbort returns true when err was caused by the client closing the connection or canceling the request
+func isClientAbort(r *http.Request, err error) bool {
+	switch {
+	case errors.Is(err, context.Canceled),
+		errors.Is(err, syscall.EPIPE),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(r.Context().Err(), context.Canceled):
+		return true
+	}
+
+	return false
+}
+
+// ServeHTTP lets you mount a prebuilt response as a handler, it's written like if a handler returned it.
+func (r *Response) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	fireAfterMiddleware(r, w, req)
+}
+
+// Write writes the status, headers and body of the response to w, adapting it to the request.
+// On HEAD requests the body is never copied, Content-Length is set instead when it's known.
+// Unlike ServeHTTP, it doesn't fire the after middleware and returns the error copying the body.
+func (r *Response) Write(w http.ResponseWriter, req *http.Request) error {
+	if valid := r.checkStatus(req); valid != nil {
+		return valid.Write(w, req)
+	}
+
+	r.applyHeaders(w.Header())
+	r.writeLinks(w, req)
+	if !r.writeErrorHeaders(w, req) {
+		w.WriteHeader(r.Code)
+		return r.discard()
+	}
+
+	if !r.modTime.IsZero() {
+		w.Header().Set("Last-Modified", r.modTime.UTC().Format(http.TimeFormat))
+	}
+
+	etag := r.entityTag(req)
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+
+	if r.notModified(req, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return r.discard()
+	}
+
+	if req.Method == http.MethodHead {
+		if r.hasLength && !r.compress && !r.chunked {
+			w.Header().Set("Content-Length", strconv.FormatInt(r.length, 10))
+		}
+
+		w.WriteHeader(r.Code)
+		return r.discard()
+	}
+
+	r.push(w)
+	if len(r.earlyHints) > 0 {
+		EarlyHints(w, r.earlyHints...)
+	}
+
+	if r.Copy == nil {
+		if body := r.defaultBody(); body != nil {
+			return body.Write(w, req)
+		}
+
+		w.WriteHeader(r.Code)
+		return nil
+	}
+
+	if len(r.trailers) == 0 {
+		return r.writeBody(w, req)
+	}
+
+	w.Header().Set("Trailer", r.trailerNames())
+	err := r.writeBody(w, req)
+	r.writeTrailers(w)
+	return err
+}
+
+// writeBody writes the header and copies the body choosing how depending on the kind of body
+func (r *Response) writeBody(w http.ResponseWriter, req *http.Request) error {
+	if r.once.consumed() {
+		// writing the header would send a success with an empty body
+		w.Header().Del("Content-Length")
+		if err := ErrInternal.JSON().Write(w, req); err != nil {
+			handleCopyError(req, err)
+		}
+
+		return ErrBodyConsumed
+	}
+
+	chunked := r.chunked || len(r.trailers) > 0
+	copyBody := r.Copy
+	body, ok := r.rendered.load()
+	if !ok {
+		body, ok = r.once.load()
+	}
+
+	if ok {
+		copyBody = func(w io.Writer) error { _, err := w.Write(body); return err }
+		if !r.compress && !chunked {
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			w.WriteHeader(r.Code)
+			return copyBody(w)
+		}
+	}
+
+	if cw := compressWriterFor(r, w, req); cw != nil {
+		err := copyBody(cw)
+		if closeErr := cw.Close(); err == nil {
+			err = closeErr
+		}
+
+		return err
+	}
+
+	if chunked {
+		w.WriteHeader(r.Code)
+		return copyBody(w)
+	}
+
+	if r.hasLength {
+		w.Header().Set("Content-Length", strconv.FormatInt(r.length, 10))
+	}
+
+	if r.buffered {
+		sw := &spillWriter{w: w, code: r.Code, limit: MaxBufferedBody}
+		err := r.Copy(sw)
+		if err != nil && !sw.spilled {
+			// nothing was sent yet, so the client gets an error instead of a truncated body
+			sw.release()
+			w.Header().Del("Content-Length")
+			if writeErr := ErrInternal.JSON().Write(w, req); writeErr != nil {
+				handleCopyError(req, writeErr)
+			}
+
+			return err
+		}
+
+		if closeErr := sw.Close(); err == nil {
+			err = closeErr
+		}
+
+		return err
+	}
+
+	w.WriteHeader(r.Code)
+	return r.Copy(w)
+}
+
+// discard releases the body without copying it
+func (r *Response) discard() error {
+	if r.closer == nil {
+		return nil
+	}
+
+	return r.closer.Close()
+}
+
 // Code returns an empty response with the status code set
 func Code(code int) *Response {
-	return &Response{Code: code, headers: map[string]string{}}
+	validateCode(code)
+	return &Response{Code: code}
 }
 
 // JSON returns a JSON response with application/json
 func (r *Response) JSON(value any) *Response {
-	r.headers["Content-Type"] = "application/json"
+	r = r.mutable()
+	r.resetBody(-1)
+	r.setHeader("Content-Type", "application/json")
+	r.buffered, r.rendered = true, &renderedBody{}
 	r.Copy = func(w io.Writer) error {
-		return json.NewEncoder(w).Encode(value)
+		return DefaultJSONEncoder.NewEncoder(w).Encode(value)
 	}
 	return r
 }
 
-// NoContent just returns an empty OK response
-func NoContent() *Response {
-	return Code(http.StatusOK)
-}
-
 // Text returns a plain text response
 func (r *Response) Text(s string) *Response {
+	r = r.mutable()
+	r.resetBody(int64(len(s)))
 	r.Copy = func(w io.Writer) error {
 		_, err := io.Copy(w, strings.NewReader(s))
 		return err
@@ -121,30 +460,138 @@ func (r *Response) Text(s string) *Response {
 // You can chain this function multiple times.
 // The headers set on http.ResponseWriter will be kept in mind.
 func (r *Response) Headers(m map[string]string) *Response {
-	if r.headers != nil {
-		for k, v := range m {
-			r.headers[k] = v
+	r = r.mutable()
+	for k, v := range m {
+		r.setHeader(k, v)
+	}
+
+	return r
+}
+
+// setHeader sets a header of the response, the headers map is only allocated for responses that have headers
+func (r *Response) setHeader(key, value string) {
+	if r.headers == nil {
+		r.headers = make(map[string]string, 2)
+	}
+
+	r.headers[key] = value
+	r.multiHeaders.Del(key)
+}
+
+// applyHeaders sets the headers of the response in h
+func (r *Response) applyHeaders(h http.Header) {
+	for key, value := range r.headers {
+		if strings.EqualFold(key, "Vary") {
+			addVary(h, value)
+			continue
 		}
 
-		return r
+		h.Set(key, value)
 	}
 
-	r.headers = m
-	return r
+	for key, values := range r.multiHeaders {
+		h.Del(key)
+		for _, value := range values {
+			h.Add(key, value)
+		}
+	}
 }
 
 // Reader sets the reader as the body response
 func (r *Response) Reader(reader io.Reader) *Response {
-	r.Copy = func(w io.Writer) error { _, err := io.Copy(w, reader); return err }
+	r = r.mutable()
+	r.resetBody(-1)
+	if sized, ok := reader.(interface{ Len() int }); ok {
+		r.resetBody(int64(sized.Len()))
+	}
+
+	r.once = &onceBody{}
+	r.Copy = r.once.guard(func(w io.Writer) error { return copyReader(w, reader) })
 	return r
 }
 
 // ReadCloser is the same as Reader but closes the ReadCloser when it finishes copying.
 func (r *Response) ReadCloser(reader io.ReadCloser) *Response {
-	r.Copy = func(w io.Writer) error { defer reader.Close(); _, err := io.Copy(w, reader); return err }
+	r = r.mutable()
+	r.resetBody(-1)
+	if file, ok := reader.(interface{ Stat() (fs.FileInfo, error) }); ok {
+		if info, err := file.Stat(); err == nil && info.Mode().IsRegular() {
+			r.resetBody(info.Size())
+		}
+	}
+
+	r.closer = reader
+	r.once = &onceBody{}
+	r.Copy = r.once.guard(func(w io.Writer) error { defer reader.Close(); return copyReader(w, reader) })
+	return r
+}
+
+// copyReader copies reader to w through their fast paths: files are handed to the io.ReaderFrom of the
+// http.ResponseWriter so the runtime can use sendfile or splice, other io.WriterTo write themselves.
+func copyReader(w io.Writer, reader io.Reader) error {
+	if rf, ok := w.(io.ReaderFrom); ok {
+		if _, isFile := reader.(*os.File); isFile {
+			_, err := rf.ReadFrom(reader)
+			return err
+		}
+	}
+
+	if wt, ok := reader.(io.WriterTo); ok {
+		_, err := wt.WriteTo(w)
+		return err
+	}
+
+	_, err := io.Copy(w, reader)
+	return err
+}
+
+// Clone returns a copy of the response that can be modified without affecting r.
+// The body is shared, so bodies that can only be read once like Reader aren't safe to clone.
+func (r *Response) Clone() *Response {
+	clone := *r
+	clone.headers = maps.Clone(r.headers)
+	clone.multiHeaders = r.multiHeaders.Clone()
+	clone.trailers = maps.Clone(r.trailers)
+	clone.frozen = false
+	if clone.stream != nil {
+		clone.Copy = clone.copyStream
+	}
+
+	return &clone
+}
+
+// Freeze makes r a prototype that is never modified: the methods that would modify it
+// return a modified clone instead. Frozen responses can be returned from many handlers concurrently.
+// Example:
+//
+//	var ErrMaintenance = httpx.Code(http.StatusServiceUnavailable).Text("under maintenance").Freeze()
+//
+//	func handler(w http.ResponseWriter, r *http.Request) error {
+//		return ErrMaintenance.Headers(map[string]string{"X-Region": region}) // ErrMaintenance is untouched
+//	}
+func (r *Response) Freeze() *Response {
+	r.frozen = true
+	return r
+}
+
+// mutable returns r, or a clone of it if r is frozen
+func (r *Response) mutable() *Response {
+	if r.frozen {
+		return r.Clone()
+	}
+
 	return r
 }
 
+// resetBody forgets the previous body and sets the length of the new one, a negative length means that it's unknown
+func (r *Response) resetBody(length int64) {
+	r.length, r.hasLength = length, length >= 0
+	r.closer, r.stream = nil, nil
+	r.buffered, r.rendered, r.once = false, nil, nil
+	r.modTime = time.Time{}
+	r.empty = false
+}
+
 // ErrorJSONCode lets you create codes that you can use as errors in a very opinionated way for httpx.
 // Example:
 //	var ErrNotFound = httpx.NewCode("NOT_FOUND", http.StatusNotFound)
@@ -155,20 +602,79 @@ func (r *Response) ReadCloser(reader io.ReadCloser) *Response {
 type ErrorJSONCode struct {
 	Code   string
 	Status int
-	Extra  any
+	// Extra is the extra payload of the responses created without one
+	Extra any
+	// Message is a human readable description of the code, rendered on the "message" field
+	Message string
+	// DocsURL points to the documentation of the code, rendered on the "docs_url" field
+	DocsURL string
+	// Retryable tells clients if repeating the request can succeed, rendered on the "retryable" field
+	// when it was set with WithRetryable
+	Retryable bool
+
+	hasRetryable bool
 }
 
-// NewCode returns a new ErrorJSONCode to generate error codes formated in JSON
-func NewCode(code string, status int) *ErrorJSONCode {
-	return &ErrorJSONCode{
+// CodeOption configures the metadata of a code created with NewCode
+type CodeOption func(*ErrorJSONCode)
+
+// WithMessage sets the message of the code
+func WithMessage(message string) CodeOption {
+	return func(e *ErrorJSONCode) {
+		e.Message = message
+	}
+}
+
+// WithDocsURL sets the documentation URL of the code
+func WithDocsURL(url string) CodeOption {
+	return func(e *ErrorJSONCode) {
+		e.DocsURL = url
+	}
+}
+
+// WithRetryable sets if requests that fail with the code can be retried
+func WithRetryable(retryable bool) CodeOption {
+	return func(e *ErrorJSONCode) {
+		e.Retryable, e.hasRetryable = retryable, true
+	}
+}
+
+// WithExtra sets the extra payload of the responses created without one
+func WithExtra(extra any) CodeOption {
+	return func(e *ErrorJSONCode) {
+		e.Extra = extra
+	}
+}
+
+// NewCode returns a new ErrorJSONCode to generate error codes formated in JSON.
+// The code is registered so clients using Do can convert the error responses back into it.
+// Example:
+//
+//	var ErrNotFound = httpx.NewCode("NOT_FOUND", http.StatusNotFound,
+//		httpx.WithMessage("resource not found"), httpx.WithRetryable(false))
+func NewCode(code string, status int, opts ...CodeOption) *ErrorJSONCode {
+	e := &ErrorJSONCode{
 		Code:   code,
 		Status: status,
 	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	registerCode(e)
+	return e
+}
+
+// Error returns the code, so codes can be returned as errors and compared with errors.Is.
+// Returning a code from a handler is the same as returning code.JSON().
+func (e *ErrorJSONCode) Error() string {
+	return e.Code
 }
 
 // JSON creates a Response from a ErrorJSONCode
 func (e *ErrorJSONCode) JSON(extra ...any) *Response {
-	var value any
+	value := e.Extra
 	if len(extra) > 0 {
 		value = extra[0]
 	}
@@ -179,5 +685,19 @@ func (e *ErrorJSONCode) JSON(extra ...any) *Response {
 	if value != nil {
 		json["extra"] = value
 	}
-	return Code(e.Status).JSON(json)
+	if e.Message != "" {
+		json["message"] = e.Message
+	}
+	if e.DocsURL != "" {
+		json["docs_url"] = e.DocsURL
+	}
+	if e.hasRetryable {
+		json["retryable"] = e.Retryable
+	}
+	res := Code(e.Status).JSON(json)
+	res.Copy = func(w io.Writer) error {
+		return encodeErrorBody(w, json)
+	}
+	res.errorCode, res.errorExtra = e.Code, value
+	return res
 }
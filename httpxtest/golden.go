@@ -0,0 +1,74 @@
+package httpxtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"mime"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("httpxtest.update", false, "update the golden files of httpxtest.AssertGolden")
+
+// Canonical renders the result as text that only changes when the response does: the status, the headers
+// sorted by name and the body, indented when it's JSON.
+func Canonical(res *Result) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%d\n", res.Status)
+	for _, key := range slices.Sorted(maps.Keys(res.Header)) {
+		for _, value := range res.Header[key] {
+			fmt.Fprintf(&b, "%s: %s\n", key, value)
+		}
+	}
+
+	b.WriteByte('\n')
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, bytes.TrimSpace(res.Body), "", "  "); err == nil {
+			indented.WriteByte('\n')
+			return append(b.Bytes(), indented.Bytes()...)
+		}
+	}
+
+	return append(b.Bytes(), res.Body...)
+}
+
+// AssertGolden compares the Canonical form of the result with the golden file testdata/name.golden.
+// Run the tests with -httpxtest.update to write the golden files with the current results.
+// Example:
+//
+//	res := httpxtest.Record(getUser, httptest.NewRequest(http.MethodGet, "/users/unknown", nil))
+//	httpxtest.AssertGolden(t, res, "get_user_not_found")
+func AssertGolden(t testing.TB, res *Result, name string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	got := Canonical(res)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("creating the golden file directory: %v", err)
+		}
+
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("updating the golden file: %v", err)
+		}
+
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("reading the golden file, run the tests with -httpxtest.update to create it: %v", err)
+		return
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("response doesn't match %s, run the tests with -httpxtest.update if the change is expected:\n--- want\n%s\n--- got\n%s", path, want, got)
+	}
+}
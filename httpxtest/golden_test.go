@@ -0,0 +1,87 @@
+package httpxtest
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{name: "json", contentType: "application/json", body: `{"id":1,"tags":["a"]}` + "\n", want: "{\n  \"id\": 1,\n  \"tags\": [\n    \"a\"\n  ]\n}\n"},
+		{name: "json with parameters", contentType: "application/json; charset=utf-8", body: `[1]`, want: "[\n  1\n]\n"},
+		{name: "json suffix", contentType: "application/problem+json", body: `{"title":"x"}`, want: "{\n  \"title\": \"x\"\n}\n"},
+		{name: "invalid json", contentType: "application/json", body: `{"id":`, want: `{"id":`},
+		{name: "text", contentType: "text/plain", body: `{"id":1}`, want: `{"id":1}`},
+		{name: "no body", contentType: "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &Result{
+				Status: http.StatusOK,
+				Header: http.Header{"X-B": {"2", "3"}, "Content-Type": {tt.contentType}, "X-A": {"1"}},
+				Body:   []byte(tt.body),
+			}
+
+			want := "200\nContent-Type: " + tt.contentType + "\nX-A: 1\nX-B: 2\nX-B: 3\n\n" + tt.want
+			if got := string(Canonical(res)); got != want {
+				t.Fatalf("expected %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Chdir(wd)
+
+	res := &Result{Status: http.StatusOK, Header: http.Header{"Content-Type": {"application/json"}}, Body: []byte(`{"id":1}`)}
+	rt := &recordingT{TB: t}
+	AssertGolden(rt, res, "missing")
+	if len(rt.failures) != 1 {
+		t.Fatalf("expected a missing golden file to fail, got %v", rt.failures)
+	}
+
+	*update = true
+	AssertGolden(rt, res, "user")
+	*update = false
+	got, err := os.ReadFile(filepath.Join("testdata", "user.golden"))
+	if err != nil || string(got) != string(Canonical(res)) {
+		t.Fatalf("expected the golden file to be written, got %q, %v", got, err)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		fail bool
+	}{
+		{name: "same", body: `{"id":1}`},
+		{name: "same after indenting", body: `{ "id": 1 }`},
+		{name: "different", body: `{"id":2}`, fail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &recordingT{TB: t}
+			AssertGolden(rt, &Result{Status: res.Status, Header: res.Header, Body: []byte(tt.body)}, "user")
+			if failed := len(rt.failures) > 0; failed != tt.fail {
+				t.Fatalf("expected failed to be %v, got %v", tt.fail, rt.failures)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// package httpxtest has helpers to test httpx handlers without spinning up a server.
+package httpxtest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gabivlj/httpx"
+)
+
+// Result is the recorded outcome of a httpx handler
+type Result struct {
+	// Status is the status code written to the client
+	Status int
+	// Header are the headers written to the client
+	Header http.Header
+	// Body is the body written to the client
+	Body []byte
+	// Err is the error returned by the handler
+	Err error
+}
+
+// JSON decodes the body into v
+func (r *Result) JSON(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Record runs h with req like httpx.H would, recording what the client receives and the returned error.
+func Record(h httpx.Handler, req *http.Request) *Result {
+	var err error
+	rec := httptest.NewRecorder()
+	httpx.H(func(w http.ResponseWriter, r *http.Request) error {
+		err = h(w, r)
+		return err
+	})(rec, req)
+
+	return &Result{
+		Status: rec.Code,
+		Header: rec.Header(),
+		Body:   rec.Body.Bytes(),
+		Err:    err,
+	}
+}
+
+// AssertCode checks that err is a httpx.ErrorJSONCode with the code, or a httpx.Response created from one.
+func AssertCode(t testing.TB, err error, code string) {
+	t.Helper()
+	var got string
+	var errorCode *httpx.ErrorJSONCode
+	var res *httpx.Response
+	switch {
+	case errors.As(err, &errorCode):
+		got = errorCode.Code
+	case errors.As(err, &res):
+		got = res.ErrorCode()
+	default:
+		t.Errorf("expected an error with code %q, got %v", code, err)
+		return
+	}
+
+	if got != code {
+		t.Errorf("expected code %q, got %q", code, got)
+	}
+}
+
+// AssertStatus checks the status code received by the client
+func AssertStatus(t testing.TB, res *Result, status int) {
+	t.Helper()
+	if res.Status != status {
+		t.Errorf("expected status %d, got %d", status, res.Status)
+	}
+}
+
+// AssertJSON checks that the body is JSON equivalent to want, want is marshaled to JSON before comparing
+// so it can be a struct, a map or a json.RawMessage.
+func AssertJSON(t testing.TB, res *Result, want any) {
+	t.Helper()
+	expected, err := json.Marshal(want)
+	if err != nil {
+		t.Errorf("marshaling expected JSON: %v", err)
+		return
+	}
+
+	var got, wanted any
+	if err := json.Unmarshal(res.Body, &got); err != nil {
+		t.Errorf("body is not JSON: %v: %s", err, res.Body)
+		return
+	}
+
+	if err := json.Unmarshal(expected, &wanted); err != nil {
+		t.Errorf("expected value is not JSON: %v", err)
+		return
+	}
+
+	if !reflect.DeepEqual(got, wanted) {
+		t.Errorf("expected JSON body %s, got %s", expected, res.Body)
+	}
+}
@@ -0,0 +1,135 @@
+package httpxtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gabivlj/httpx"
+)
+
+// recordingT records the failures of the helpers under test instead of failing the test
+type recordingT struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+}
+
+func TestRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler httpx.Handler
+		status  int
+		body    string
+		err     bool
+	}{
+		{name: "response", handler: func(w http.ResponseWriter, r *http.Request) error {
+			return httpx.Code(http.StatusCreated).Headers(map[string]string{"X-Id": "1"}).Text("created")
+		}, status: http.StatusCreated, body: "created", err: true},
+		{name: "error", handler: func(w http.ResponseWriter, r *http.Request) error {
+			return httpx.ErrNotFound.JSON()
+		}, status: http.StatusNotFound, body: `{"code":"NOT_FOUND"}`, err: true},
+		{name: "written", handler: func(w http.ResponseWriter, r *http.Request) error {
+			w.WriteHeader(http.StatusAccepted)
+			return nil
+		}, status: http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Record(tt.handler, httptest.NewRequest(http.MethodGet, "/", nil))
+			if res.Status != tt.status || string(res.Body) != tt.body && string(res.Body) != tt.body+"\n" {
+				t.Fatalf("expected %d %q, got %d %q", tt.status, tt.body, res.Status, res.Body)
+			}
+
+			if (res.Err != nil) != tt.err {
+				t.Fatalf("expected the returned error to be recorded, got %v", res.Err)
+			}
+		})
+	}
+}
+
+func TestAssertCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		fail bool
+	}{
+		{name: "code", err: httpx.ErrNotFound},
+		{name: "response", err: httpx.ErrNotFound.JSON("extra")},
+		{name: "wrapped", err: fmt.Errorf("loading: %w", httpx.ErrNotFound)},
+		{name: "other code", err: httpx.ErrBadRequest.JSON(), fail: true},
+		{name: "plain error", err: errors.New("not found"), fail: true},
+		{name: "nil", fail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &recordingT{TB: t}
+			AssertCode(rt, tt.err, httpx.ErrNotFound.Code)
+			if failed := len(rt.failures) > 0; failed != tt.fail {
+				t.Fatalf("expected failed to be %v, got %v", tt.fail, rt.failures)
+			}
+		})
+	}
+}
+
+func TestAssertStatus(t *testing.T) {
+	rt := &recordingT{TB: t}
+	AssertStatus(rt, &Result{Status: http.StatusOK}, http.StatusOK)
+	AssertStatus(rt, &Result{Status: http.StatusNotFound}, http.StatusOK)
+	if len(rt.failures) != 1 {
+		t.Fatalf("expected only the wrong status to fail, got %v", rt.failures)
+	}
+}
+
+func TestAssertJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want any
+		fail bool
+	}{
+		{name: "map", body: `{"b": [1, 2], "a": "x"}`, want: map[string]any{"a": "x", "b": []int{1, 2}}},
+		{name: "struct", body: `{"id": 1}`, want: struct {
+			ID int `json:"id"`
+		}{ID: 1}},
+		{name: "raw message", body: `[true]`, want: json.RawMessage(`[ true ]`)},
+		{name: "different", body: `{"id": 1}`, want: map[string]int{"id": 2}, fail: true},
+		{name: "extra field", body: `{"id": 1, "name": "al"}`, want: map[string]int{"id": 1}, fail: true},
+		{name: "body isn't JSON", body: `<html>`, want: map[string]int{}, fail: true},
+		{name: "want can't be marshaled", body: `{}`, want: make(chan int), fail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &recordingT{TB: t}
+			AssertJSON(rt, &Result{Body: []byte(tt.body)}, tt.want)
+			if failed := len(rt.failures) > 0; failed != tt.fail {
+				t.Fatalf("expected failed to be %v, got %v", tt.fail, rt.failures)
+			}
+		})
+	}
+}
+
+func TestResultJSON(t *testing.T) {
+	var got struct {
+		ID int `json:"id"`
+	}
+
+	if err := (&Result{Body: []byte(`{"id": 3}`)}).JSON(&got); err != nil || got.ID != 3 {
+		t.Fatalf("expected id 3, got %d, %v", got.ID, err)
+	}
+}
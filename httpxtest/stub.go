@@ -0,0 +1,51 @@
+package httpxtest
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gabivlj/httpx"
+)
+
+// Stub returns a handler that always responds with status and body: bodies that are errors are returned as
+// they are, strings and byte slices are sent as they are and the rest as JSON. A nil body sends only the status.
+// Example:
+//
+//	mux.GET("/users/{id}", httpxtest.Stub(http.StatusOK, User{ID: "1"}))
+//	mux.GET("/down", httpxtest.Stub(0, httpx.ErrServiceUnavailable))
+func Stub(status int, body any) httpx.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		switch body := body.(type) {
+		case nil:
+			return httpx.Code(status)
+		case error:
+			return body
+		case string:
+			return httpx.Code(status).Text(body)
+		case []byte:
+			return httpx.Code(status).Bytes(body)
+		}
+
+		return httpx.Code(status).JSON(body)
+	}
+}
+
+// Sequence returns a handler that runs the handlers in order, one per request, repeating the last one once
+// they've all run. It's useful to script a flaky downstream, like a 503 followed by a success.
+// Example:
+//
+//	flaky := httpxtest.Sequence(
+//		httpxtest.Stub(0, httpx.ErrServiceUnavailable),
+//		httpxtest.Stub(http.StatusOK, quote),
+//	)
+func Sequence(handlers ...httpx.Handler) httpx.Handler {
+	if len(handlers) == 0 {
+		panic("httpxtest: Sequence needs at least a handler")
+	}
+
+	var calls atomic.Int64
+	return func(w http.ResponseWriter, r *http.Request) error {
+		i := min(int(calls.Add(1)-1), len(handlers)-1)
+		return handlers[i](w, r)
+	}
+}
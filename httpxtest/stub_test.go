@@ -0,0 +1,89 @@
+package httpxtest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gabivlj/httpx"
+)
+
+func TestStub(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        any
+		wantStatus  int
+		contentType string
+		want        string
+	}{
+		{name: "nil", status: http.StatusNoContent, wantStatus: http.StatusNoContent},
+		{name: "string", status: http.StatusOK, body: "pong", wantStatus: http.StatusOK, want: "pong"},
+		{name: "bytes", status: http.StatusOK, body: []byte{1, 2}, wantStatus: http.StatusOK, want: "\x01\x02"},
+		{name: "json", status: http.StatusCreated, body: map[string]int{"id": 1}, wantStatus: http.StatusCreated, contentType: "application/json", want: `{"id":1}`},
+		{name: "error code", body: httpx.ErrServiceUnavailable, wantStatus: http.StatusServiceUnavailable, contentType: "application/json", want: `{"code":"SERVICE_UNAVAILABLE"}`},
+		{name: "error response", status: http.StatusOK, body: httpx.ErrNotFound.JSON("user"), wantStatus: http.StatusNotFound, contentType: "application/json", want: `{"code":"NOT_FOUND","extra":"user"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Record(Stub(tt.status, tt.body), httptest.NewRequest(http.MethodGet, "/", nil))
+			AssertStatus(t, res, tt.wantStatus)
+			if got := res.Header.Get("Content-Type"); got != tt.contentType {
+				t.Fatalf("expected Content-Type %q, got %q", tt.contentType, got)
+			}
+
+			if string(res.Body) != tt.want && string(res.Body) != tt.want+"\n" {
+				t.Fatalf("expected %q, got %q", tt.want, res.Body)
+			}
+		})
+	}
+}
+
+func TestSequence(t *testing.T) {
+	flaky := Sequence(
+		Stub(0, httpx.ErrServiceUnavailable),
+		Stub(0, httpx.ErrInternal),
+		Stub(http.StatusOK, "ok"),
+	)
+
+	for i, status := range []int{http.StatusServiceUnavailable, http.StatusInternalServerError, http.StatusOK, http.StatusOK} {
+		if res := Record(flaky, httptest.NewRequest(http.MethodGet, "/", nil)); res.Status != status {
+			t.Fatalf("request %d: expected %d, got %d", i, status, res.Status)
+		}
+	}
+}
+
+func TestSequenceConcurrently(t *testing.T) {
+	var mu sync.Mutex
+	counts := map[int]int{}
+	seq := Sequence(Stub(http.StatusAccepted, nil), Stub(http.StatusOK, nil))
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := Record(seq, httptest.NewRequest(http.MethodGet, "/", nil))
+			mu.Lock()
+			defer mu.Unlock()
+			counts[res.Status]++
+		}()
+	}
+
+	wg.Wait()
+	if counts[http.StatusAccepted] != 1 || counts[http.StatusOK] != 19 {
+		t.Fatalf("expected the first handler to run once, got %v", counts)
+	}
+}
+
+func TestSequenceWithoutHandlers(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected Sequence to panic")
+		}
+	}()
+
+	Sequence()
+}
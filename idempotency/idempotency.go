@@ -0,0 +1,165 @@
+// package idempotency makes retried requests with the same Idempotency-Key get the response of the
+// first one instead of running the handler again.
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gabivlj/httpx"
+)
+
+// Codes returned to the requests that can't be run or replayed
+var (
+	ErrInProgress   = httpx.NewCode("IDEMPOTENCY_KEY_IN_PROGRESS", http.StatusConflict)
+	ErrKeyReused    = httpx.NewCode("IDEMPOTENCY_KEY_REUSED", http.StatusUnprocessableEntity)
+	ErrKeyRequired  = httpx.NewCode("IDEMPOTENCY_KEY_REQUIRED", http.StatusBadRequest)
+	ErrBodyTooLarge = httpx.NewCode("IDEMPOTENCY_BODY_TOO_LARGE", http.StatusRequestEntityTooLarge)
+)
+
+// Header is the header the key is read from
+const Header = "Idempotency-Key"
+
+// ReplayedHeader is set to "true" on replayed responses
+const ReplayedHeader = "Idempotent-Replayed"
+
+// Config configures the middleware
+type Config struct {
+	// Store keeps the responses, a MemoryStore by default
+	Store Store
+	// TTL is how long responses are replayed, 24 hours by default
+	TTL time.Duration
+	// LockTTL is how long a key is locked while its first request runs, 1 minute by default.
+	// It should be longer than the handler can take.
+	LockTTL time.Duration
+	// Required rejects the requests without key with ErrKeyRequired
+	Required bool
+	// MaxBodySize is the size of the request bodies read to detect keys reused with different requests, 1MB by default
+	MaxBodySize int64
+}
+
+// Idempotency replays the responses of requests retried with the same key
+type Idempotency struct {
+	cfg Config
+}
+
+// New returns an Idempotency for the config
+func New(cfg Config) *Idempotency {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+
+	if cfg.TTL <= 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+
+	if cfg.LockTTL <= 0 {
+		cfg.LockTTL = time.Minute
+	}
+
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = 1 << 20
+	}
+
+	return &Idempotency{cfg: cfg}
+}
+
+// Middleware runs the first request of each key and stores its response, the requests retried with the key
+// get the stored response with the Idempotent-Replayed header. Requests arriving while the first one runs get
+// a 409 ErrInProgress, and requests reusing the key with a different method, route or body a 422 ErrKeyReused.
+// Server errors aren't stored, so they can be retried. Keys are scoped by route.
+// Example:
+//
+//	payments := idempotency.New(idempotency.Config{Store: store})
+//	mux.POST("/payments", createPayment, httpx.WithMiddleware(payments.Middleware))
+func (i *Idempotency) Middleware(next httpx.Handler) httpx.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		key := r.Header.Get(Header)
+		if key == "" {
+			if i.cfg.Required {
+				return ErrKeyRequired.JSON()
+			}
+
+			return next(w, r)
+		}
+
+		fingerprint, err := i.fingerprint(r)
+		if err != nil {
+			return err
+		}
+
+		ctx := r.Context()
+		key = httpx.Route(r) + " " + key
+		record, found, err := i.cfg.Store.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		if found {
+			return replay(record, fingerprint)
+		}
+
+		locked, err := i.cfg.Store.Lock(ctx, key, i.cfg.LockTTL)
+		if err != nil {
+			return err
+		}
+
+		if !locked {
+			// the first request could have finished between Get and Lock
+			if record, found, err := i.cfg.Store.Get(ctx, key); err == nil && found {
+				return replay(record, fingerprint)
+			}
+
+			return ErrInProgress.JSON()
+		}
+
+		snap, err := httpx.Capture(next, r)
+		if err != nil || snap.Status >= 500 {
+			if unlockErr := i.cfg.Store.Unlock(ctx, key); unlockErr != nil {
+				return unlockErr
+			}
+
+			return snap.Response()
+		}
+
+		if err := i.cfg.Store.Save(ctx, key, &Record{Fingerprint: fingerprint, Snapshot: snap}, i.cfg.TTL); err != nil {
+			return err
+		}
+
+		return snap.Response()
+	}
+}
+
+// replay returns the stored response if the request is the same one that stored it
+func replay(record *Record, fingerprint string) error {
+	if record.Fingerprint != fingerprint {
+		return ErrKeyReused.JSON()
+	}
+
+	return record.Snapshot.Response().Headers(map[string]string{ReplayedHeader: "true"})
+}
+
+// fingerprint hashes the method, path and body of the request, the body is restored so the handler can read it
+func (i *Idempotency) fingerprint(r *http.Request) (string, error) {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.Path+"\n")
+	if r.Body != nil && r.Body != http.NoBody {
+		body, err := io.ReadAll(io.LimitReader(r.Body, i.cfg.MaxBodySize+1))
+		if err != nil {
+			return "", err
+		}
+
+		if int64(len(body)) > i.cfg.MaxBodySize {
+			return "", ErrBodyTooLarge.JSON(i.cfg.MaxBodySize)
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		h.Write(body)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,188 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gabivlj/httpx"
+)
+
+// step is a request sent to the middleware and what it should get
+type step struct {
+	key      string
+	path     string
+	body     string
+	status   int
+	code     *httpx.ErrorJSONCode
+	replayed bool
+	// calls is the number of times the handler ran after the request
+	calls int
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		handler func(calls int) *httpx.Response
+		steps   []step
+	}{
+		{
+			name: "replayed",
+			steps: []step{
+				{key: "a", body: `{"amount": 1}`, status: http.StatusCreated, calls: 1},
+				{key: "a", body: `{"amount": 1}`, status: http.StatusCreated, replayed: true, calls: 1},
+				{key: "b", body: `{"amount": 1}`, status: http.StatusCreated, calls: 2},
+			},
+		},
+		{
+			name: "without a key",
+			steps: []step{
+				{status: http.StatusCreated, calls: 1},
+				{status: http.StatusCreated, calls: 2},
+			},
+		},
+		{
+			name:  "required key",
+			cfg:   Config{Required: true},
+			steps: []step{{status: http.StatusBadRequest, code: ErrKeyRequired}},
+		},
+		{
+			name: "key reused with another body",
+			steps: []step{
+				{key: "a", body: `{"amount": 1}`, status: http.StatusCreated, calls: 1},
+				{key: "a", body: `{"amount": 2}`, status: http.StatusUnprocessableEntity, code: ErrKeyReused, calls: 1},
+			},
+		},
+		{
+			name: "keys are scoped by route",
+			steps: []step{
+				{key: "a", path: "/payments", status: http.StatusCreated, calls: 1},
+				{key: "a", path: "/refunds", status: http.StatusCreated, calls: 2},
+			},
+		},
+		{
+			name: "client errors are replayed",
+			handler: func(calls int) *httpx.Response {
+				return httpx.ErrBadRequest.JSON(calls)
+			},
+			steps: []step{
+				{key: "a", status: http.StatusBadRequest, code: httpx.ErrBadRequest, calls: 1},
+				{key: "a", status: http.StatusBadRequest, code: httpx.ErrBadRequest, replayed: true, calls: 1},
+			},
+		},
+		{
+			name: "server errors are retried",
+			handler: func(calls int) *httpx.Response {
+				if calls == 1 {
+					return httpx.ErrInternal.JSON()
+				}
+
+				return httpx.Code(http.StatusCreated).Text(strconv.Itoa(calls))
+			},
+			steps: []step{
+				{key: "a", status: http.StatusInternalServerError, code: httpx.ErrInternal, calls: 1},
+				{key: "a", status: http.StatusCreated, calls: 2},
+				{key: "a", status: http.StatusCreated, replayed: true, calls: 2},
+			},
+		},
+		{
+			name:  "body too large",
+			cfg:   Config{MaxBodySize: 4},
+			steps: []step{{key: "a", body: "12345", status: http.StatusRequestEntityTooLarge, code: ErrBodyTooLarge}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			handler := tt.handler
+			if handler == nil {
+				handler = func(calls int) *httpx.Response {
+					return httpx.Code(http.StatusCreated).Text(strconv.Itoa(calls))
+				}
+			}
+
+			mux := httpx.NewMux(httpx.WithMiddleware(New(tt.cfg).Middleware))
+			route := func(w http.ResponseWriter, r *http.Request) error {
+				calls++
+				return handler(calls)
+			}
+
+			mux.POST("/payments", route)
+			mux.POST("/refunds", route)
+
+			// stored is the body of the last request that ran the handler
+			var stored string
+			for i, s := range tt.steps {
+				path := s.path
+				if path == "" {
+					path = "/payments"
+				}
+
+				r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(s.body))
+				if s.key != "" {
+					r.Header.Set(Header, s.key)
+				}
+
+				w := httptest.NewRecorder()
+				mux.ServeHTTP(w, r)
+				if w.Code != s.status || calls != s.calls {
+					t.Fatalf("step %d: expected %d after %d calls, got %d after %d", i, s.status, s.calls, w.Code, calls)
+				}
+
+				if s.code != nil && !strings.Contains(w.Body.String(), s.code.Code) {
+					t.Fatalf("step %d: expected %s, got %s", i, s.code.Code, w.Body.String())
+				}
+
+				if replayed := w.Header().Get(ReplayedHeader) == "true"; replayed != s.replayed {
+					t.Fatalf("step %d: expected replayed to be %v", i, s.replayed)
+				}
+
+				if s.replayed && w.Body.String() != stored {
+					t.Fatalf("step %d: expected the stored body %q, got %q", i, stored, w.Body.String())
+				}
+
+				if !s.replayed {
+					stored = w.Body.String()
+				}
+			}
+		})
+	}
+}
+
+func TestInProgress(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	mux := httpx.NewMux(httpx.WithMiddleware(New(Config{}).Middleware))
+	mux.POST("/payments", func(w http.ResponseWriter, r *http.Request) error {
+		close(started)
+		<-release
+		return httpx.Code(http.StatusCreated)
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/payments", nil)
+		r.Header.Set(Header, "a")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w
+	}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- send() }()
+	<-started
+	if w := send(); w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), ErrInProgress.Code) {
+		t.Fatalf("expected a 409 %s while the first request runs, got %d %s", ErrInProgress.Code, w.Code, w.Body.String())
+	}
+
+	close(release)
+	if w := <-done; w.Code != http.StatusCreated {
+		t.Fatalf("expected the first request to finish, got %d", w.Code)
+	}
+
+	if w := send(); w.Code != http.StatusCreated || w.Header().Get(ReplayedHeader) != "true" {
+		t.Fatalf("expected the response to be replayed once the first request finished, got %d", w.Code)
+	}
+}
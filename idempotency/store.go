@@ -0,0 +1,100 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gabivlj/httpx"
+)
+
+// Record is a stored response
+type Record struct {
+	// Fingerprint identifies the request that stored the response
+	Fingerprint string
+	Snapshot    *httpx.Snapshot
+}
+
+// Store keeps the responses by key. Implementations shared by many processes, like Redis, must lock atomically.
+type Store interface {
+	// Get returns the record of key
+	Get(ctx context.Context, key string) (*Record, bool, error)
+	// Lock locks key for ttl while its first request runs, it returns false if key is locked or has a record
+	Lock(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Unlock unlocks key without saving a record
+	Unlock(ctx context.Context, key string) error
+	// Save saves the record of key for ttl and unlocks it
+	Save(ctx context.Context, key string, record *Record, ttl time.Duration) error
+}
+
+// MemoryStore keeps the responses in memory
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+type memoryEntry struct {
+	record  *Record
+	expires time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]memoryEntry{}}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) (*Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok || entry.record == nil || time.Now().After(entry.expires) {
+		return nil, false, nil
+	}
+
+	return &Record{Fingerprint: entry.record.Fingerprint, Snapshot: entry.record.Snapshot.Clone()}, true, nil
+}
+
+func (s *MemoryStore) Lock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.sweep(now)
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expires) {
+		return false, nil
+	}
+
+	s.entries[key] = memoryEntry{expires: now.Add(ttl)}
+	return true, nil
+}
+
+func (s *MemoryStore) Unlock(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.entries[key]; ok && entry.record == nil {
+		delete(s.entries, key)
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) Save(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = memoryEntry{record: record, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+// sweep deletes the expired entries at most once a minute
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if now.After(entry.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrJobNotFound is the code returned by JobStatus for jobs the store doesn't have
+var ErrJobNotFound = NewCode("JOB_NOT_FOUND", http.StatusNotFound)
+
+// JobState is the state of a long running operation
+type JobState string
+
+const (
+	JobPending   JobState = "pending"
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+)
+
+// Job is the status of a long running operation started with a 202 Accepted
+type Job struct {
+	ID    string   `json:"id"`
+	State JobState `json:"state"`
+	// Result is sent to the client when the job succeeded and it has no ResultURL
+	Result any `json:"result,omitempty"`
+	// Error describes why the job failed, errors are sent like the items of a MULTIPLE_ERRORS response
+	Error any `json:"error,omitempty"`
+	// ResultURL is where the result of a succeeded job lives, the client is redirected there with a 303
+	ResultURL string `json:"-"`
+	// RetryAfter is sent to the clients polling a job that didn't finish, 0 doesn't send it
+	RetryAfter time.Duration `json:"-"`
+}
+
+// JobStore returns the status of jobs, a nil job and error means the job doesn't exist
+type JobStore interface {
+	Job(ctx context.Context, id string) (*Job, error)
+}
+
+// JobStatus returns a handler answering the polling of the jobs in store, the id of the job is the path
+// parameter param. Jobs that didn't finish and failed jobs are returned as JSON with a 200, succeeded jobs
+// too unless they have a ResultURL, then the client gets a 303 See Other to it.
+// Example:
+//
+//	mux.POST("/exports", func(w http.ResponseWriter, r *http.Request) error {
+//		id := startExport(r)
+//		return httpx.Accepted("/exports/jobs/"+id, httpx.Job{ID: id, State: httpx.JobPending})
+//	})
+//	mux.GET("/exports/jobs/{id}", httpx.JobStatus(jobs, "id"))
+func JobStatus(store JobStore, param string) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		job, err := store.Job(r.Context(), Param(r, param))
+		if err != nil {
+			return err
+		}
+
+		if job == nil {
+			return ErrJobNotFound.JSON()
+		}
+
+		if err, ok := job.Error.(error); ok {
+			copied := *job
+			copied.Error = errorItem(err, DefaultErrorHandler)
+			job = &copied
+		}
+
+		if job.State == JobSucceeded && job.ResultURL != "" {
+			return Code(http.StatusSeeOther).Location(job.ResultURL).JSON(job)
+		}
+
+		res := OK(job).Cache(CacheNoStore)
+		if job.RetryAfter > 0 && job.State != JobSucceeded && job.State != JobFailed {
+			res = res.RetryAfter(job.RetryAfter)
+		}
+
+		return res
+	}
+}
+
+// MemoryJobStore is a JobStore in memory, for services running in a single instance and tests
+type MemoryJobStore struct {
+	mu   sync.RWMutex
+	jobs map[string]Job
+}
+
+// Put stores the job, replacing the job with the same id
+func (s *MemoryJobStore) Put(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.jobs == nil {
+		s.jobs = map[string]Job{}
+	}
+
+	s.jobs[job.ID] = job
+}
+
+// Delete removes the job with the id
+func (s *MemoryJobStore) Delete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, id)
+}
+
+// Job returns a copy of the job with the id
+func (s *MemoryJobStore) Job(_ context.Context, id string) (*Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &job, nil
+}
@@ -0,0 +1,171 @@
+package httpx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// JSONEncoder encodes the JSON bodies of the responses. Implement it to use libraries
+// like sonic or go-json instead of encoding/json.
+type JSONEncoder interface {
+	Marshal(v any) ([]byte, error)
+	NewEncoder(w io.Writer) JSONStreamEncoder
+}
+
+// JSONStreamEncoder writes JSON values to a stream, like *json.Encoder
+type JSONStreamEncoder interface {
+	Encode(v any) error
+}
+
+// DefaultJSONEncoder is the encoder used for every JSON body, StdJSONEncoder by default.
+var DefaultJSONEncoder JSONEncoder = StdJSONEncoder{}
+
+// StdJSONEncoder is the JSONEncoder backed by encoding/json
+type StdJSONEncoder struct {
+	// DisableHTMLEscape stops escaping <, > and & inside JSON strings
+	DisableHTMLEscape bool
+	// Prefix and Indent indent the output like json.MarshalIndent when any of them is set
+	Prefix string
+	Indent string
+	// NoTrailingNewline stops adding a newline after each encoded value
+	NoTrailingNewline bool
+	// OmitNil writes nothing when the encoded value is nil, instead of null
+	OmitNil bool
+}
+
+// Marshal returns the encoding of v, without a trailing newline
+func (e StdJSONEncoder) Marshal(v any) ([]byte, error) {
+	b := &bytes.Buffer{}
+	if err := e.NewEncoder(b).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(b.Bytes(), []byte{'\n'}), nil
+}
+
+// NewEncoder returns a *json.Encoder configured with the options of e
+func (e StdJSONEncoder) NewEncoder(w io.Writer) JSONStreamEncoder {
+	if e.NoTrailingNewline {
+		w = trimNewlineWriter{w}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(!e.DisableHTMLEscape)
+	if e.Prefix != "" || e.Indent != "" {
+		enc.SetIndent(e.Prefix, e.Indent)
+	}
+
+	if e.OmitNil {
+		return omitNilEncoder{enc}
+	}
+
+	return enc
+}
+
+// trimNewlineWriter drops the trailing newline of each write, *json.Encoder writes every value at once
+type trimNewlineWriter struct {
+	w io.Writer
+}
+
+func (t trimNewlineWriter) Write(p []byte) (int, error) {
+	n, err := t.w.Write(bytes.TrimSuffix(p, []byte{'\n'}))
+	if err == nil {
+		n = len(p)
+	}
+
+	return n, err
+}
+
+// omitNilEncoder skips nil values
+type omitNilEncoder struct {
+	*json.Encoder
+}
+
+func (e omitNilEncoder) Encode(v any) error {
+	if isNil(v) {
+		return nil
+	}
+
+	return e.Encoder.Encode(v)
+}
+
+// isNil returns true if v is nil or a nil pointer, map, slice or interface
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+
+	return false
+}
+
+// JSONIndent returns a JSON response like JSON but indented with prefix and indent, whatever the
+// DefaultJSONEncoder configuration is. Useful for responses read by humans.
+func (r *Response) JSONIndent(value any, prefix, indent string) *Response {
+	r = r.JSON(value)
+	r.Copy = func(w io.Writer) error {
+		enc := DefaultJSONEncoder.NewEncoder(w)
+		if indenter, ok := enc.(interface{ SetIndent(prefix, indent string) }); ok {
+			indenter.SetIndent(prefix, indent)
+			return enc.Encode(value)
+		}
+
+		b, err := DefaultJSONEncoder.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		indented := &bytes.Buffer{}
+		if err := json.Indent(indented, b, prefix, indent); err != nil {
+			return err
+		}
+
+		indented.WriteByte('\n')
+		_, err = indented.WriteTo(w)
+		return err
+	}
+
+	return r
+}
+
+// streamBufferSize is the size of the pooled buffers StreamJSON encodes into
+const streamBufferSize = 32 << 10
+
+var streamBufferPool = sync.Pool{New: func() any { return bufio.NewWriterSize(io.Discard, streamBufferSize) }}
+
+// StreamJSON returns a JSON response meant for big payloads: it's sent chunked without buffering the whole
+// body, compressed when the client accepts gzip or deflate, and encoded through pooled buffers so the
+// memory used stays flat no matter the size of the body.
+// Example:
+//
+//	return httpx.Code(http.StatusOK).StreamJSON(export) // multi-megabyte export
+func (r *Response) StreamJSON(value any) *Response {
+	r = r.JSON(value)
+	r.buffered, r.rendered = false, nil
+	r.chunked = true
+	r.compress = true
+	r.Copy = func(w io.Writer) error {
+		buf := streamBufferPool.Get().(*bufio.Writer)
+		buf.Reset(w)
+		defer func() {
+			buf.Reset(io.Discard)
+			streamBufferPool.Put(buf)
+		}()
+
+		if err := DefaultJSONEncoder.NewEncoder(buf).Encode(value); err != nil {
+			return err
+		}
+
+		return buf.Flush()
+	}
+
+	return r
+}
@@ -0,0 +1,121 @@
+// package jsonapi renders httpx errors as JSON:API error documents.
+package jsonapi
+
+import (
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gabivlj/httpx"
+)
+
+// ContentType is the media type of JSON:API documents
+const ContentType = "application/vnd.api+json"
+
+// Error is a JSON:API error object
+type Error struct {
+	ID     string         `json:"id,omitempty"`
+	Status string         `json:"status,omitempty"`
+	Code   string         `json:"code,omitempty"`
+	Title  string         `json:"title,omitempty"`
+	Detail string         `json:"detail,omitempty"`
+	Source *Source        `json:"source,omitempty"`
+	Meta   map[string]any `json:"meta,omitempty"`
+}
+
+// Source points to the part of the request that caused the error
+type Source struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+	Header    string `json:"header,omitempty"`
+}
+
+// Document is a JSON:API document with errors
+type Document struct {
+	Errors []Error `json:"errors"`
+}
+
+// FromCode returns the error object of an ErrorJSONCode, with the status text as title
+func FromCode(code *httpx.ErrorJSONCode, detail string) Error {
+	return Error{
+		Status: strconv.Itoa(code.Status),
+		Code:   code.Code,
+		Title:  http.StatusText(code.Status),
+		Detail: detail,
+	}
+}
+
+// At returns a copy of e whose source points to the JSON pointer of the request document, like "/data/attributes/email"
+func (e Error) At(pointer string) Error {
+	e.Source = &Source{Pointer: pointer}
+	return e
+}
+
+// AtParameter returns a copy of e whose source is the query parameter
+func (e Error) AtParameter(parameter string) Error {
+	e.Source = &Source{Parameter: parameter}
+	return e
+}
+
+// Validation returns the errors of code for each invalid field, keyed by their JSON pointer, sorted by pointer.
+// Example:
+//
+//	return jsonapi.Response(jsonapi.Validation(ErrInvalid, map[string]string{
+//		"/data/attributes/email": "must be a valid email",
+//	})...)
+func Validation(code *httpx.ErrorJSONCode, details map[string]string) []Error {
+	pointers := make([]string, 0, len(details))
+	for pointer := range details {
+		pointers = append(pointers, pointer)
+	}
+
+	sort.Strings(pointers)
+	errs := make([]Error, 0, len(details))
+	for _, pointer := range pointers {
+		errs = append(errs, FromCode(code, details[pointer]).At(pointer))
+	}
+
+	return errs
+}
+
+// Response returns a response with the JSON:API document of errs. When the errors have
+// different statuses, the status is the most generally applicable: 400 for client errors and 500 if there are server errors.
+func Response(errs ...Error) *httpx.Response {
+	return httpx.Code(status(errs)).Encode(encoder{Document{Errors: errs}})
+}
+
+func status(errs []Error) int {
+	status := 0
+	for _, e := range errs {
+		code, err := strconv.Atoi(e.Status)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case status == 0 || status == code:
+			status = code
+		case code >= 500 || status >= 500:
+			status = http.StatusInternalServerError
+		default:
+			status = http.StatusBadRequest
+		}
+	}
+
+	if status == 0 {
+		return http.StatusBadRequest
+	}
+
+	return status
+}
+
+type encoder struct {
+	document Document
+}
+
+func (e encoder) ContentType() string { return ContentType }
+
+func (e encoder) Encode(w io.Writer) error {
+	return httpx.DefaultJSONEncoder.NewEncoder(w).Encode(e.document)
+}
@@ -0,0 +1,80 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gabivlj/httpx"
+)
+
+var (
+	errInvalid  = httpx.NewCode("JSONAPI_TEST_INVALID", http.StatusUnprocessableEntity)
+	errConflict = httpx.NewCode("JSONAPI_TEST_CONFLICT", http.StatusConflict)
+	errDown     = httpx.NewCode("JSONAPI_TEST_DOWN", http.StatusServiceUnavailable)
+)
+
+func TestResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		errs   []Error
+		status int
+	}{
+		{name: "single error", errs: []Error{FromCode(errConflict, "already exists")}, status: http.StatusConflict},
+		{name: "same status", errs: Validation(errInvalid, map[string]string{"/data/attributes/b": "b", "/data/attributes/a": "a"}), status: http.StatusUnprocessableEntity},
+		{name: "different client errors", errs: []Error{FromCode(errInvalid, ""), FromCode(errConflict, "")}, status: http.StatusBadRequest},
+		{name: "server error", errs: []Error{FromCode(errInvalid, ""), FromCode(errDown, "")}, status: http.StatusInternalServerError},
+		{name: "server error first", errs: []Error{FromCode(errDown, ""), FromCode(errInvalid, "")}, status: http.StatusInternalServerError},
+		{name: "without status", errs: []Error{{Code: "X"}}, status: http.StatusBadRequest},
+		{name: "no errors", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			Response(tt.errs...).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != tt.status || w.Header().Get("Content-Type") != ContentType {
+				t.Fatalf("expected a %d %s, got %d %s", tt.status, ContentType, w.Code, w.Header().Get("Content-Type"))
+			}
+
+			var doc Document
+			if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(doc.Errors) != len(tt.errs) || len(tt.errs) > 0 && !reflect.DeepEqual(doc.Errors, tt.errs) {
+				t.Fatalf("expected %+v, got %+v", tt.errs, doc.Errors)
+			}
+		})
+	}
+}
+
+func TestValidation(t *testing.T) {
+	errs := Validation(errInvalid, map[string]string{
+		"/data/attributes/name":  "is required",
+		"/data/attributes/email": "must be a valid email",
+	})
+
+	want := []Error{
+		{Status: "422", Code: errInvalid.Code, Title: "Unprocessable Entity", Detail: "must be a valid email", Source: &Source{Pointer: "/data/attributes/email"}},
+		{Status: "422", Code: errInvalid.Code, Title: "Unprocessable Entity", Detail: "is required", Source: &Source{Pointer: "/data/attributes/name"}},
+	}
+
+	if !reflect.DeepEqual(errs, want) {
+		t.Fatalf("expected %+v, got %+v", want, errs)
+	}
+}
+
+func TestSource(t *testing.T) {
+	e := FromCode(errInvalid, "")
+	if at := e.AtParameter("page[size]"); at.Source.Parameter != "page[size]" || e.Source != nil {
+		t.Fatalf("expected a copy pointing to the parameter, got %+v and %+v", at.Source, e.Source)
+	}
+
+	body, _ := json.Marshal(e.At("/data"))
+	if string(body) != `{"status":"422","code":"JSONAPI_TEST_INVALID","title":"Unprocessable Entity","source":{"pointer":"/data"}}` {
+		t.Fatalf("expected the empty members to be omitted, got %s", body)
+	}
+}
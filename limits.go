@@ -0,0 +1,25 @@
+package httpx
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrRequestTooLarge is the code returned by MaxBytes when the body is over the limit,
+// its extra payload is the limit in bytes.
+var ErrRequestTooLarge = NewCode("REQUEST_TOO_LARGE", http.StatusRequestEntityTooLarge)
+
+// MaxBytes limits the body of the requests to n bytes with http.MaxBytesReader. When h returns
+// the error of reading past the limit (wrapped or not), the client gets ErrRequestTooLarge instead.
+func MaxBytes(n int64, h Handler) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		r.Body = http.MaxBytesReader(w, r.Body, n)
+		err := h(w, r)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return ErrRequestTooLarge.JSON(maxBytesErr.Limit)
+		}
+
+		return err
+	}
+}
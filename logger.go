@@ -0,0 +1,69 @@
+package httpx
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+)
+
+// RequestIDHeader is the header that WithRequestID reads and sets the request id from
+var RequestIDHeader = "X-Request-Id"
+
+const (
+	loggerKey    = "httpx.logger"
+	requestIDKey = "httpx.request_id"
+)
+
+// WithLogger stores l as the logger of the request, attaching the request id and route to it.
+// Inside httpx handlers, the logger is shared with the after middleware.
+func WithLogger(r *http.Request, l *slog.Logger) *http.Request {
+	return Set(r, loggerKey, l.With(requestAttrs(r)...))
+}
+
+// Logger returns the logger stored with WithLogger, or slog.Default with the request id and route attached.
+func Logger(r *http.Request) *slog.Logger {
+	if l, ok := Get[*slog.Logger](r, loggerKey); ok {
+		return l
+	}
+
+	return slog.Default().With(requestAttrs(r)...)
+}
+
+func requestAttrs(r *http.Request) []any {
+	attrs := []any{slog.String("method", r.Method)}
+	if id := RequestID(r); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+
+	if route := Route(r); route != "" {
+		attrs = append(attrs, slog.String("route", route))
+	}
+
+	return attrs
+}
+
+// RequestID returns the id of the request set by WithRequestID, or the RequestIDHeader of the request.
+func RequestID(r *http.Request) string {
+	if id, ok := Get[string](r, requestIDKey); ok {
+		return id
+	}
+
+	return r.Header.Get(RequestIDHeader)
+}
+
+// WithRequestID is a middleware that gives each request an id, the one in its RequestIDHeader or a random one,
+// and sends it back in the RequestIDHeader of the response.
+func WithRequestID(next Handler) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" {
+			b := make([]byte, 16)
+			_, _ = rand.Read(b)
+			id = hex.EncodeToString(b)
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		return next(w, Set(r, requestIDKey, id))
+	}
+}
@@ -0,0 +1,54 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// LongPollInterval is the time LongPoll waits before polling again when poll returns without data
+var LongPollInterval = time.Second
+
+// LongPoll blocks until poll has data or wait passes, returning a 200 with the data as JSON or an empty 204.
+// poll receives a context that is done after wait or when the client goes away, it can block on it
+// waiting for data or return false right away, then it's called again after LongPollInterval.
+// When the client disconnects the error of the request context is returned.
+// Example:
+//
+//	func events(w http.ResponseWriter, r *http.Request) error {
+//		return httpx.LongPoll(r, 30*time.Second, func(ctx context.Context) (any, bool, error) {
+//			events, err := db.EventsAfter(ctx, r.URL.Query().Get("after"))
+//			return events, len(events) > 0, err
+//		})
+//	}
+func LongPoll(r *http.Request, wait time.Duration, poll func(ctx context.Context) (any, bool, error)) error {
+	ctx, cancel := context.WithTimeout(r.Context(), wait)
+	defer cancel()
+
+	for {
+		v, ok, err := poll(ctx)
+		if ok && err == nil {
+			return OK(v)
+		}
+
+		if err := r.Context().Err(); err != nil {
+			return err
+		}
+
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return NoContent()
+		}
+
+		timer := time.NewTimer(LongPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package httpx
+
+// Middleware wraps a httpx handler, it can reject requests by returning errors
+// that will go through the same Response pipeline than handler errors.
+// Example:
+//
+//	func RequireUser(next httpx.Handler) httpx.Handler {
+//		return func(w http.ResponseWriter, r *http.Request) error {
+//			if r.Header.Get("X-User") == "" {
+//				return ErrUnauthorized.JSON()
+//			}
+//
+//			return next(w, r)
+//		}
+//	}
+type Middleware func(Handler) Handler
+
+// Chain wraps h with the middleware, the first middleware is the outermost one so it runs first.
+func Chain(h Handler, mw ...Middleware) Handler {
+	for i := len(mw) - 1; i >= 0; i-- {
+		h = mw[i](h)
+	}
+
+	return h
+}
@@ -0,0 +1,83 @@
+package httpx
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrMultipleErrors is the code of the responses rendered for errors joined with errors.Join. The status of the
+// response is the highest of the errors it contains.
+// Example:
+//
+//	// 500 {"code": "MULTIPLE_ERRORS", "extra": [{"code": "NOT_FOUND", "status": 404}, {"code": "INTERNAL_SERVER_ERROR", "status": 500}]}
+//	return errors.Join(ErrNotFound, httpx.ErrInternal)
+var ErrMultipleErrors = NewCode("MULTIPLE_ERRORS", http.StatusBadRequest)
+
+// ErrorItem is each of the errors listed on the extra field of a MULTIPLE_ERRORS response
+type ErrorItem struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+	Status  int    `json:"status"`
+}
+
+// joinedErrors returns the errors joined in err, looking through wrapped errors.
+// Nested joins are flattened and nil is returned if err doesn't join errors.
+func joinedErrors(err error) []error {
+	for err != nil {
+		switch e := err.(type) {
+		case *Response, *ErrorJSONCode:
+			return nil
+		case interface{ Unwrap() []error }:
+			var errs []error
+			for _, err := range e.Unwrap() {
+				if nested := joinedErrors(err); nested != nil {
+					errs = append(errs, nested...)
+				} else if err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			return errs
+		}
+
+		err = errors.Unwrap(err)
+	}
+
+	return nil
+}
+
+// joinedResponse renders errs in a single response, errors that are not codes or responses go through handler
+func joinedResponse(errs []error, handler func(error) *Response) *Response {
+	items := make([]ErrorItem, 0, len(errs))
+	status := 0
+	for _, err := range errs {
+		item := errorItem(err, handler)
+		status = max(status, item.Status)
+		items = append(items, item)
+	}
+
+	res := ErrMultipleErrors.JSON(items)
+	res.Code = status
+	return res
+}
+
+func errorItem(err error, handler func(error) *Response) ErrorItem {
+	var res *Response
+	if errors.As(err, &res) {
+		// the body isn't read, it could be a stream that can only be copied once
+		item := ErrorItem{Code: res.errorCode, Status: res.Code}
+		if res.errorCode == "" {
+			item.Message = http.StatusText(res.Code)
+		}
+
+		return item
+	}
+
+	var code *ErrorJSONCode
+	if errors.As(err, &code) {
+		return ErrorItem{Code: code.Code, Message: code.Message, Status: code.Status}
+	}
+
+	res = handler(err)
+	return ErrorItem{Code: res.errorCode, Message: err.Error(), Status: res.Code}
+}
@@ -0,0 +1,140 @@
+package httpx
+
+import (
+	"errors"
+	"io"
+	"iter"
+	"mime"
+	"mime/multipart"
+	"net/http"
+)
+
+// Codes returned by the multipart helpers
+var (
+	ErrUnsupportedMediaType = NewCode("UNSUPPORTED_MEDIA_TYPE", http.StatusUnsupportedMediaType)
+	ErrInvalidMultipart     = NewCode("INVALID_MULTIPART", http.StatusBadRequest)
+	ErrMissingFile          = NewCode("MISSING_FILE", http.StatusBadRequest)
+)
+
+// multipartOverhead is the room left in the body for the other fields and part headers of FormFile uploads
+const multipartOverhead = 1 << 20
+
+// FormFile returns the file of the multipart/form-data field, like http.Request.FormFile, but failing with httpx errors:
+// ErrUnsupportedMediaType if the body is not multipart/form-data, ErrRequestTooLarge if the file is bigger than maxSize,
+// ErrMissingFile if there's no file in the field and ErrInvalidMultipart if the body is malformed.
+func FormFile(r *http.Request, field string, maxSize int64) (multipart.File, *multipart.FileHeader, error) {
+	if err := checkMultipart(r); err != nil {
+		return nil, nil, err
+	}
+
+	r.Body = http.MaxBytesReader(nil, r.Body, maxSize+multipartOverhead)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		return nil, nil, multipartError(err, maxSize)
+	}
+
+	file, header, err := r.FormFile(field)
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil, nil, ErrMissingFile.JSON(field)
+	}
+
+	if err != nil {
+		return nil, nil, ErrInvalidMultipart.JSON(err.Error())
+	}
+
+	if header.Size > maxSize {
+		file.Close()
+		return nil, nil, ErrRequestTooLarge.JSON(maxSize)
+	}
+
+	return file, header, nil
+}
+
+// Part is a part of a multipart body, reading past its size limit fails with ErrRequestTooLarge
+type Part struct {
+	*multipart.Part
+	limit  int64
+	remain int64
+}
+
+func (p *Part) Read(b []byte) (int, error) {
+	if p.remain <= 0 {
+		if n, _ := p.Part.Read(make([]byte, 1)); n > 0 {
+			return 0, ErrRequestTooLarge.JSON(p.limit)
+		}
+
+		return 0, io.EOF
+	}
+
+	if int64(len(b)) > p.remain {
+		b = b[:p.remain]
+	}
+
+	n, err := p.Part.Read(b)
+	p.remain -= int64(n)
+	return n, err
+}
+
+// MultipartParts iterates the parts of a multipart body without buffering them, every part can have up to maxPartSize bytes.
+// The iteration stops after the first error, which is a httpx error like the ones of FormFile.
+// Example:
+//
+//	for part, err := range httpx.MultipartParts(r, 100<<20) {
+//		if err != nil {
+//			return err
+//		}
+//
+//		if _, err := io.Copy(storage.Writer(part.FileName()), part); err != nil {
+//			return err
+//		}
+//	}
+func MultipartParts(r *http.Request, maxPartSize int64) iter.Seq2[*Part, error] {
+	return func(yield func(*Part, error) bool) {
+		if err := checkMultipart(r); err != nil {
+			yield(nil, err)
+			return
+		}
+
+		reader, err := r.MultipartReader()
+		if err != nil {
+			yield(nil, ErrInvalidMultipart.JSON(err.Error()))
+			return
+		}
+
+		for {
+			part, err := reader.NextPart()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			if err != nil {
+				yield(nil, multipartError(err, maxPartSize))
+				return
+			}
+
+			ok := yield(&Part{Part: part, limit: maxPartSize, remain: maxPartSize}, nil)
+			part.Close()
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// checkMultipart returns ErrUnsupportedMediaType if the body is not multipart
+func checkMultipart(r *http.Request) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" && mediaType != "multipart/mixed" {
+		return ErrUnsupportedMediaType.JSON(r.Header.Get("Content-Type"))
+	}
+
+	return nil
+}
+
+func multipartError(err error, limit int64) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return ErrRequestTooLarge.JSON(limit)
+	}
+
+	return ErrInvalidMultipart.JSON(err.Error())
+}
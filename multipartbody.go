@@ -0,0 +1,125 @@
+package httpx
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+)
+
+// MultipartBody builds a multipart body, like several files or byte ranges in one response.
+// The boundary is generated for you and the parts are copied in order when the response is written.
+// Example:
+//
+//	body := httpx.NewMultipartBody("mixed").
+//		Bytes("application/json", manifest).
+//		File("report.pdf", "application/pdf", report, reportSize)
+//	return httpx.Code(http.StatusOK).Multipart(body)
+type MultipartBody struct {
+	subtype  string
+	boundary string
+	parts    []bodyPart
+}
+
+type bodyPart struct {
+	header textproto.MIMEHeader
+	length int64
+	copy   func(io.Writer) error
+}
+
+// NewMultipartBody returns an empty multipart body of the subtype, like "mixed" or "byteranges"
+func NewMultipartBody(subtype string) *MultipartBody {
+	return &MultipartBody{subtype: subtype, boundary: multipart.NewWriter(io.Discard).Boundary()}
+}
+
+// Part adds a part with the headers whose body is written by copyBody. length is the size of the body,
+// or -1 if it's unknown.
+func (m *MultipartBody) Part(headers map[string]string, length int64, copyBody func(io.Writer) error) *MultipartBody {
+	header := make(textproto.MIMEHeader, len(headers))
+	for key, value := range headers {
+		header.Set(key, value)
+	}
+
+	m.parts = append(m.parts, bodyPart{header: header, length: length, copy: copyBody})
+	return m
+}
+
+// Bytes adds a part with b as body and the content type
+func (m *MultipartBody) Bytes(contentType string, b []byte) *MultipartBody {
+	return m.Part(map[string]string{"Content-Type": contentType}, int64(len(b)), func(w io.Writer) error {
+		_, err := w.Write(b)
+		return err
+	})
+}
+
+// File adds a part with the content of reader as an attachment named name. size is the size of the
+// content, or -1 if it's unknown. The reader is read when the response is written, and closed then
+// if it's an io.Closer.
+func (m *MultipartBody) File(name, contentType string, reader io.Reader, size int64) *MultipartBody {
+	headers := map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": name}),
+	}
+	if contentType != "" {
+		headers["Content-Type"] = contentType
+	}
+
+	return m.Part(headers, size, func(w io.Writer) error {
+		if closer, ok := reader.(io.Closer); ok {
+			defer closer.Close()
+		}
+
+		_, err := io.Copy(w, reader)
+		return err
+	})
+}
+
+// length returns the length of the body, or -1 if any part has an unknown length
+func (m *MultipartBody) length() int64 {
+	var counter countingWriter
+	mw := multipart.NewWriter(&counter)
+	_ = mw.SetBoundary(m.boundary)
+	length := int64(0)
+	for _, part := range m.parts {
+		if part.length < 0 {
+			return -1
+		}
+
+		_, _ = mw.CreatePart(part.header)
+		length += part.length
+	}
+
+	_ = mw.Close()
+	return length + int64(counter)
+}
+
+// writeTo copies the parts to w
+func (m *MultipartBody) writeTo(w io.Writer) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(m.boundary); err != nil {
+		return err
+	}
+
+	for _, part := range m.parts {
+		pw, err := mw.CreatePart(part.header)
+		if err != nil {
+			return err
+		}
+
+		if err := part.copy(pw); err != nil {
+			return err
+		}
+	}
+
+	return mw.Close()
+}
+
+// Multipart sets m as the body of the response with its Content-Type, Content-Length is set when
+// the lengths of every part are known. Parts are streamed, so the body can only be written once.
+func (r *Response) Multipart(m *MultipartBody) *Response {
+	r = r.mutable()
+	r.resetBody(m.length())
+	r.setHeader("Content-Type", "multipart/"+m.subtype+"; boundary="+m.boundary)
+	r.once = &onceBody{}
+	r.Copy = r.once.guard(m.writeTo)
+	return r
+}
@@ -0,0 +1,332 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ErrMethodNotAllowed is the code returned by Mux when the path exists but not for the request method,
+// the response has the Allow header set with the methods of the path.
+var ErrMethodNotAllowed = NewCode("METHOD_NOT_ALLOWED", http.StatusMethodNotAllowed)
+
+// NotFoundHandler returns a handler that answers every request with code as JSON,
+// mount it as the fallback route of your mux to return JSON 404s.
+func NotFoundHandler(code *ErrorJSONCode) http.Handler {
+	return H(func(w http.ResponseWriter, r *http.Request) error {
+		return code.JSON()
+	})
+}
+
+// Mux is a router implementing http.Handler where routes are httpx handlers.
+// Paths can have parameters like "/users/{id}" and a trailing "/files/{path...}" that matches the rest of the path,
+// both are read with Param. Static segments take priority over parameters.
+// Example:
+//
+//	mux := httpx.NewMux()
+//	mux.GET("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+//		return httpx.Code(http.StatusOK).JSON(users[httpx.Param(r, "id")])
+//	})
+//	http.ListenAndServe(":8080", mux)
+type Mux struct {
+	// NotFound handles the requests that don't match any path, NotFoundHandler(ErrNotFound) by default
+	NotFound http.Handler
+
+	root  *node
+	group *Group
+}
+
+// NewMux returns a Mux, options are applied to every route.
+func NewMux(opts ...Option) *Mux {
+	m := &Mux{root: &node{}}
+	m.group = &Group{opts: opts, handle: m.handle}
+	return m
+}
+
+// Group returns a group that registers its handlers on the mux under prefix.
+func (m *Mux) Group(prefix string, opts ...Option) *Group {
+	return m.group.Group(prefix, opts...)
+}
+
+// Handle registers h for the method and path, an empty method matches every method.
+func (m *Mux) Handle(method, path string, h Handler, opts ...Option) {
+	m.group.Handle(method, path, h, opts...)
+}
+
+// GET registers h for GET requests on path
+func (m *Mux) GET(path string, h Handler, opts ...Option) {
+	m.group.GET(path, h, opts...)
+}
+
+// POST registers h for POST requests on path
+func (m *Mux) POST(path string, h Handler, opts ...Option) {
+	m.group.POST(path, h, opts...)
+}
+
+// PUT registers h for PUT requests on path
+func (m *Mux) PUT(path string, h Handler, opts ...Option) {
+	m.group.PUT(path, h, opts...)
+}
+
+// PATCH registers h for PATCH requests on path
+func (m *Mux) PATCH(path string, h Handler, opts ...Option) {
+	m.group.PATCH(path, h, opts...)
+}
+
+// DELETE registers h for DELETE requests on path
+func (m *Mux) DELETE(path string, h Handler, opts ...Option) {
+	m.group.DELETE(path, h, opts...)
+}
+
+func (m *Mux) handle(method, path string, h http.Handler) {
+	if !strings.HasPrefix(path, "/") {
+		panic(fmt.Sprintf("httpx: path %q must start with /", path))
+	}
+
+	n := m.root.insert(strings.Split(path[1:], "/"), path)
+	if n.handlers == nil {
+		n.handlers = map[string]http.Handler{}
+	}
+
+	if _, ok := n.handlers[method]; ok {
+		panic(fmt.Sprintf("httpx: %s %s is already registered", method, path))
+	}
+
+	n.handlers[method] = h
+	n.route = path
+}
+
+// ServeHTTP dispatches the request to the handler of its method and path
+func (m *Mux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/"), "/")
+	n, params := m.root.match(segments, nil)
+	if n == nil {
+		notFound := m.NotFound
+		if notFound == nil {
+			notFound = NotFoundHandler(ErrNotFound)
+		}
+
+		notFound.ServeHTTP(w, r)
+		return
+	}
+
+	h := n.handler(r.Method)
+	if h == nil {
+		allow := n.allow()
+		fireAfterMiddleware(ErrMethodNotAllowed.JSON().Headers(map[string]string{"Allow": allow}), w, r)
+		return
+	}
+
+	Set(r, routeKey, n.route)
+	r = r.WithContext(context.WithValue(r.Context(), matchKey{}, &match{route: n.route, params: params}))
+	h.ServeHTTP(w, r)
+}
+
+type matchKey struct{}
+
+const routeKey = "httpx.route"
+
+// match is the route matched by Mux and its parameters
+type match struct {
+	route  string
+	params []param
+}
+
+type param struct {
+	name  string
+	value string
+}
+
+// Param returns the value of the path parameter name, or "" if it doesn't exist.
+// It works with Mux routes and http.ServeMux patterns.
+func Param(r *http.Request, name string) string {
+	m, _ := r.Context().Value(matchKey{}).(*match)
+	if m == nil {
+		return r.PathValue(name)
+	}
+
+	for _, p := range m.params {
+		if p.name == name {
+			return p.value
+		}
+	}
+
+	return ""
+}
+
+// Route returns the path pattern of the route that matched the request, like "/users/{id}".
+// It works with Mux routes and http.ServeMux patterns.
+func Route(r *http.Request) string {
+	if m, ok := r.Context().Value(matchKey{}).(*match); ok {
+		return m.route
+	}
+
+	if route, ok := Get[string](r, routeKey); ok {
+		return route
+	}
+
+	if _, path, ok := strings.Cut(r.Pattern, " "); ok {
+		return path
+	}
+
+	return r.Pattern
+}
+
+// node is a path segment of the Mux routing tree
+type node struct {
+	static   map[string]*node
+	param    *node
+	rest     *node
+	name     string
+	route    string
+	handlers map[string]http.Handler
+}
+
+func (n *node) insert(segments []string, path string) *node {
+	if len(segments) == 0 {
+		return n
+	}
+
+	segment := segments[0]
+	if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+		if n.static == nil {
+			n.static = map[string]*node{}
+		}
+
+		child, ok := n.static[segment]
+		if !ok {
+			child = &node{}
+			n.static[segment] = child
+		}
+
+		return child.insert(segments[1:], path)
+	}
+
+	name := segment[1 : len(segment)-1]
+	if rest, ok := strings.CutSuffix(name, "..."); ok {
+		if len(segments) > 1 {
+			panic(fmt.Sprintf("httpx: %s in %q must be the last segment", segment, path))
+		}
+
+		n.rest = n.rest.withName(rest, path)
+		return n.rest
+	}
+
+	n.param = n.param.withName(name, path)
+	return n.param.insert(segments[1:], path)
+}
+
+// withName returns n, or a new node if it's nil, checking that the parameter names match
+func (n *node) withName(name, path string) *node {
+	if n == nil {
+		return &node{name: name}
+	}
+
+	if n.name != name {
+		panic(fmt.Sprintf("httpx: parameter {%s} in %q conflicts with {%s}", name, path, n.name))
+	}
+
+	return n
+}
+
+func (n *node) match(segments []string, params []param) (*node, []param) {
+	if len(segments) == 0 {
+		if len(n.handlers) > 0 {
+			return n, params
+		}
+
+		return nil, nil
+	}
+
+	segment, err := url.PathUnescape(segments[0])
+	if err != nil {
+		return nil, nil
+	}
+
+	if child, ok := n.static[segment]; ok {
+		if found, p := child.match(segments[1:], params); found != nil {
+			return found, p
+		}
+	}
+
+	if n.param != nil && segment != "" {
+		if found, p := n.param.match(segments[1:], append(params, param{name: n.param.name, value: segment})); found != nil {
+			return found, p
+		}
+	}
+
+	if n.rest != nil && len(n.rest.handlers) > 0 {
+		rest, err := url.PathUnescape(strings.Join(segments, "/"))
+		if err != nil {
+			return nil, nil
+		}
+
+		return n.rest, append(params, param{name: n.rest.name, value: rest})
+	}
+
+	return nil, nil
+}
+
+// handler returns the handler for the method, HEAD requests fall back to GET handlers
+func (n *node) handler(method string) http.Handler {
+	if h, ok := n.handlers[method]; ok {
+		return h
+	}
+
+	if h, ok := n.handlers[""]; ok {
+		return h
+	}
+
+	if method == http.MethodHead {
+		return n.handlers[http.MethodGet]
+	}
+
+	return nil
+}
+
+// allow returns the value of the Allow header for the node
+func (n *node) allow() string {
+	methods := make([]string, 0, len(n.handlers)+1)
+	for method := range n.handlers {
+		methods = append(methods, method)
+	}
+
+	if _, ok := n.handlers[http.MethodGet]; ok {
+		if _, ok := n.handlers[http.MethodHead]; !ok {
+			methods = append(methods, http.MethodHead)
+		}
+	}
+
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
+}
+
+// ServeMux wraps a http.ServeMux so requests matching a path pattern but not its method get
+// ErrMethodNotAllowed as JSON with the Allow header, and requests not matching any pattern get ErrNotFound,
+// instead of the plain text bodies of the stdlib.
+func ServeMux(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, pattern := mux.Handler(r)
+		if pattern != "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		rec := newRecorder()
+		h.ServeHTTP(rec, r)
+		switch rec.code {
+		case http.StatusMethodNotAllowed:
+			allow := rec.header.Get("Allow")
+			fireAfterMiddleware(ErrMethodNotAllowed.JSON().Headers(map[string]string{"Allow": allow}), w, r)
+		case http.StatusNotFound:
+			fireAfterMiddleware(ErrNotFound.JSON(), w, r)
+		default:
+			if err := rec.replay(w); err != nil {
+				handleCopyError(r, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMux(t *testing.T) {
+	mux := NewMux()
+	route := func(w http.ResponseWriter, r *http.Request) error {
+		return Code(http.StatusOK).Text(fmt.Sprintf("%s %s id=%s path=%s", r.Method, Route(r), Param(r, "id"), Param(r, "path")))
+	}
+
+	mux.GET("/users/{id}", route)
+	mux.DELETE("/users/{id}", route)
+	mux.GET("/users/me", route)
+	mux.GET("/users/{id}/posts", route)
+	mux.GET("/files/{path...}", route)
+	mux.Handle("", "/any", route)
+	mux.Group("/v1").POST("/users", route)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		body   string
+		allow  string
+	}{
+		{name: "parameter", method: http.MethodGet, path: "/users/1", status: http.StatusOK, body: "GET /users/{id} id=1 path="},
+		{name: "escaped parameter", method: http.MethodGet, path: "/users/a%2Fb", status: http.StatusOK, body: "GET /users/{id} id=a/b path="},
+		{name: "static before parameter", method: http.MethodGet, path: "/users/me", status: http.StatusOK, body: "GET /users/me id= path="},
+		{name: "static falls back to parameter", method: http.MethodGet, path: "/users/me/posts", status: http.StatusOK, body: "GET /users/{id}/posts id=me path="},
+		{name: "rest of the path", method: http.MethodGet, path: "/files/a/b%20c.txt", status: http.StatusOK, body: "GET /files/{path...} id= path=a/b c.txt"},
+		{name: "any method", method: http.MethodPatch, path: "/any", status: http.StatusOK, body: "PATCH /any id= path="},
+		{name: "group", method: http.MethodPost, path: "/v1/users", status: http.StatusOK, body: "POST /v1/users id= path="},
+		{name: "head falls back to get", method: http.MethodHead, path: "/users/1", status: http.StatusOK},
+		{name: "method not allowed", method: http.MethodPost, path: "/users/1", status: http.StatusMethodNotAllowed, body: `{"code":"METHOD_NOT_ALLOWED"}`, allow: "DELETE, GET, HEAD"},
+		{name: "empty parameter", method: http.MethodGet, path: "/users/", status: http.StatusNotFound, body: `{"code":"NOT_FOUND"}`},
+		{name: "too long", method: http.MethodGet, path: "/users/1/posts/2", status: http.StatusNotFound, body: `{"code":"NOT_FOUND"}`},
+		{name: "prefix without handlers", method: http.MethodGet, path: "/v1", status: http.StatusNotFound, body: `{"code":"NOT_FOUND"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.status || w.Body.String() != tt.body && w.Body.String() != tt.body+"\n" {
+				t.Fatalf("expected %d %q, got %d %q", tt.status, tt.body, w.Code, w.Body.String())
+			}
+
+			if got := w.Header().Get("Allow"); got != tt.allow {
+				t.Fatalf("expected Allow %q, got %q", tt.allow, got)
+			}
+		})
+	}
+}
+
+func TestMuxNotFound(t *testing.T) {
+	mux := NewMux()
+	mux.NotFound = NotFoundHandler(ErrBadRequest)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected the NotFound handler to answer, got %d", w.Code)
+	}
+}
+
+func TestMuxInvalidRoutes(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []string
+	}{
+		{name: "duplicated", paths: []string{"/users/{id}", "/users/{id}"}},
+		{name: "rest not last", paths: []string{"/files/{path...}/edit"}},
+		{name: "conflicting parameters", paths: []string{"/users/{id}", "/users/{name}/posts"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("expected %v to panic", tt.paths)
+				}
+			}()
+
+			mux := NewMux()
+			for _, path := range tt.paths {
+				mux.GET(path, func(w http.ResponseWriter, r *http.Request) error { return nil })
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package httpx
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// acceptValue is a single value of an Accept-like header with its quality
+type acceptValue struct {
+	value string
+	q     float64
+}
+
+// parseAccept parses Accept-like headers (Accept, Accept-Encoding, Accept-Language...)
+// returning the values sorted by quality, keeping header order between same qualities.
+func parseAccept(header string) []acceptValue {
+	if header == "" {
+		return nil
+	}
+
+	values := []acceptValue{}
+	for _, part := range strings.Split(header, ",") {
+		value, params, _ := strings.Cut(part, ";")
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, raw, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(key) != "q" {
+				continue
+			}
+
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				parsed = 0
+			}
+
+			q = parsed
+		}
+
+		values = append(values, acceptValue{value: value, q: q})
+	}
+
+	sort.SliceStable(values, func(i, j int) bool { return values[i].q > values[j].q })
+	return values
+}
+
+// negotiate returns the offer that the Accept-like header prefers, or "" if none is acceptable.
+// Offers are tried in order when the header gives them the same quality.
+func negotiate(header string, offers ...string) string {
+	accepted := parseAccept(header)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q, explicit := -1.0, false
+		for _, value := range accepted {
+			switch value.value {
+			case strings.ToLower(offer):
+				q, explicit = value.q, true
+			case "*":
+				if q < 0 {
+					q = value.q
+				}
+			}
+
+			if explicit {
+				break
+			}
+		}
+
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+
+	return best
+}
+
+// Negotiate returns the content type of offers that the Accept header of the request prefers,
+// or "" if none is acceptable. Requests without Accept header get the first offer.
+// Example:
+//
+//	switch httpx.Negotiate(r, "application/json", xmsgpack.ContentType) {
+//	case xmsgpack.ContentType:
+//		return httpx.Code(http.StatusOK).Encode(xmsgpack.MsgPack(rows))
+//	case "application/json":
+//		return httpx.Code(http.StatusOK).JSON(rows)
+//	}
+//
+//	return httpx.Code(http.StatusNotAcceptable)
+func Negotiate(r *http.Request, offers ...string) string {
+	header := r.Header.Get("Accept")
+	if header == "" {
+		if len(offers) == 0 {
+			return ""
+		}
+
+		return offers[0]
+	}
+
+	accepted := parseAccept(header)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q, specificity := 0.0, -1
+		for _, value := range accepted {
+			if s := mediaSpecificity(value.value, offer); s > specificity {
+				q, specificity = value.q, s
+			}
+		}
+
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+
+	return best
+}
+
+// mediaSpecificity returns how specific the media range is matching the media type,
+// -1 if it doesn't match, 0 for */*, 1 for type/* and 2 for type/subtype
+func mediaSpecificity(mediaRange, mediaType string) int {
+	mediaType, _, _ = strings.Cut(strings.ToLower(mediaType), ";")
+	rangeType, rangeSubtype, _ := strings.Cut(mediaRange, "/")
+	typ, subtype, _ := strings.Cut(strings.TrimSpace(mediaType), "/")
+	switch {
+	case rangeType == "*" && rangeSubtype == "*":
+		return 0
+	case rangeType == typ && rangeSubtype == "*":
+		return 1
+	case rangeType == typ && rangeSubtype == subtype:
+		return 2
+	}
+
+	return -1
+}
+
+// Language returns the language of supported that the Accept-Language header of the request prefers,
+// following the lookup of RFC 4647: each range is tried in order of quality, removing its last subtags
+// until one matches, so "de-CH" matches a supported "de". When nothing matches, or the request has no
+// Accept-Language, the first supported language is returned.
+// Example:
+//
+//	switch httpx.Language(r, "en", "es", "pt-BR") {
+//	case "es":
+//		...
+//	}
+func Language(r *http.Request, supported ...string) string {
+	if len(supported) == 0 {
+		return ""
+	}
+
+	for _, value := range parseAccept(r.Header.Get("Accept-Language")) {
+		if value.q == 0 || value.value == "*" {
+			continue
+		}
+
+		for tag := value.value; tag != ""; tag = truncateLanguage(tag) {
+			for _, language := range supported {
+				if strings.EqualFold(language, tag) {
+					return language
+				}
+			}
+		}
+	}
+
+	return supported[0]
+}
+
+// truncateLanguage removes the last subtag of a language range, and the single letter subtag before it
+// as RFC 4647 lookup requires
+func truncateLanguage(tag string) string {
+	i := strings.LastIndexByte(tag, '-')
+	if i < 0 {
+		return ""
+	}
+
+	tag = tag[:i]
+	if i = strings.LastIndexByte(tag, '-'); i >= 0 && len(tag)-i == 2 {
+		tag = tag[:i]
+	}
+
+	return tag
+}
@@ -0,0 +1,354 @@
+// package openapivalidate validates the requests of httpx handlers against an OpenAPI 3 document.
+package openapivalidate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gabivlj/httpx"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidRequest is the code returned when the request doesn't match the document, its extra payload lists each Violation
+var ErrInvalidRequest = httpx.NewCode("INVALID_REQUEST", http.StatusBadRequest)
+
+// Violation is a part of the request that doesn't match the document. In is "path", "query", "header" or "body",
+// and Name the parameter or, for bodies, the path of the field like "items.0.price".
+type Violation struct {
+	In      string `json:"in"`
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
+
+// Document is the part of an OpenAPI 3 document used to validate requests
+type Document struct {
+	Paths      map[string]*PathItem `yaml:"paths"`
+	Components struct {
+		Schemas    map[string]*Schema    `yaml:"schemas"`
+		Parameters map[string]*Parameter `yaml:"parameters"`
+	} `yaml:"components"`
+}
+
+// PathItem are the operations of a path
+type PathItem struct {
+	Parameters []*Parameter `yaml:"parameters"`
+	Get        *Operation   `yaml:"get"`
+	Put        *Operation   `yaml:"put"`
+	Post       *Operation   `yaml:"post"`
+	Delete     *Operation   `yaml:"delete"`
+	Options    *Operation   `yaml:"options"`
+	Head       *Operation   `yaml:"head"`
+	Patch      *Operation   `yaml:"patch"`
+	Trace      *Operation   `yaml:"trace"`
+}
+
+// operations returns the operations of the path by method
+func (p *PathItem) operations() map[string]*Operation {
+	ops := map[string]*Operation{}
+	for method, op := range map[string]*Operation{
+		http.MethodGet: p.Get, http.MethodPut: p.Put, http.MethodPost: p.Post, http.MethodDelete: p.Delete,
+		http.MethodOptions: p.Options, http.MethodHead: p.Head, http.MethodPatch: p.Patch, http.MethodTrace: p.Trace,
+	} {
+		if op != nil {
+			ops[method] = op
+		}
+	}
+
+	return ops
+}
+
+// Operation is an operation of a path
+type Operation struct {
+	Parameters  []*Parameter `yaml:"parameters"`
+	RequestBody *struct {
+		Required bool `yaml:"required"`
+		Content  map[string]struct {
+			Schema *Schema `yaml:"schema"`
+		} `yaml:"content"`
+	} `yaml:"requestBody"`
+}
+
+// Parameter is a path, query or header parameter
+type Parameter struct {
+	Ref      string  `yaml:"$ref"`
+	Name     string  `yaml:"name"`
+	In       string  `yaml:"in"`
+	Required bool    `yaml:"required"`
+	Schema   *Schema `yaml:"schema"`
+}
+
+// Config configures a Validator
+type Config struct {
+	// RejectUnknown rejects with httpx.ErrNotFound the requests whose path and method aren't in the document,
+	// by default they're let through
+	RejectUnknown bool
+	// MaxBodySize is the size of the bodies read to validate them, 1MB by default
+	MaxBodySize int64
+}
+
+// Validator validates requests against a Document
+type Validator struct {
+	cfg    Config
+	doc    *Document
+	routes []route
+}
+
+type route struct {
+	segments []string
+	params   []*Parameter
+	ops      map[string]*Operation
+}
+
+// Load parses an OpenAPI 3 document in JSON or YAML and returns a Validator for it
+// Example:
+//
+//	spec, _ := os.ReadFile("openapi.yaml")
+//	v, err := openapivalidate.Load(spec, openapivalidate.Config{})
+//	if err != nil {
+//		return err
+//	}
+//
+//	mux := httpx.NewMux(httpx.WithMiddleware(v.Middleware))
+func Load(data []byte, cfg Config) (*Validator, error) {
+	var doc struct {
+		Document `yaml:",inline"`
+		OpenAPI  string `yaml:"openapi"`
+	}
+
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("openapivalidate: parsing the document: %w", err)
+	}
+
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("openapivalidate: unsupported OpenAPI version %q", doc.OpenAPI)
+	}
+
+	if err := doc.compilePatterns(); err != nil {
+		return nil, fmt.Errorf("openapivalidate: %w", err)
+	}
+
+	return New(&doc.Document, cfg), nil
+}
+
+// New returns a Validator for the document. Paths with literal segments are matched before the templated ones,
+// so /users/me wins over /users/{id}. Unlike Load, patterns that don't compile are ignored.
+func New(doc *Document, cfg Config) *Validator {
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = 1 << 20
+	}
+
+	v := &Validator{cfg: cfg, doc: doc}
+	for path, item := range doc.Paths {
+		v.routes = append(v.routes, route{
+			segments: strings.Split(strings.Trim(path, "/"), "/"),
+			params:   item.Parameters,
+			ops:      item.operations(),
+		})
+	}
+
+	slices.SortFunc(v.routes, func(a, b route) int {
+		for i := range min(len(a.segments), len(b.segments)) {
+			if aTemplate, bTemplate := isTemplate(a.segments[i]), isTemplate(b.segments[i]); aTemplate != bTemplate {
+				if aTemplate {
+					return 1
+				}
+
+				return -1
+			}
+		}
+
+		return slices.Compare(a.segments, b.segments)
+	})
+
+	return v
+}
+
+// isTemplate returns true if the segment of a path is a parameter like {id}
+func isTemplate(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// Middleware rejects the requests that don't match the document with a 400 ErrInvalidRequest listing the violations
+func (v *Validator) Middleware(next httpx.Handler) httpx.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		violations, known, err := v.Validate(r)
+		if err != nil {
+			return err
+		}
+
+		if !known && v.cfg.RejectUnknown {
+			return httpx.ErrNotFound.JSON()
+		}
+
+		if len(violations) > 0 {
+			return ErrInvalidRequest.JSON(violations)
+		}
+
+		return next(w, r)
+	}
+}
+
+// Validate returns the violations of the request and whether its operation is in the document.
+// The body is read to validate it and replaced, so handlers can still read it.
+func (v *Validator) Validate(r *http.Request) ([]Violation, bool, error) {
+	op, params, pathValues := v.find(r)
+	if op == nil {
+		return nil, false, nil
+	}
+
+	violations := []Violation{}
+	for _, p := range append(params, op.Parameters...) {
+		p = v.parameter(p)
+		var values []string
+		switch p.In {
+		case "path":
+			values = []string{pathValues[p.Name]}
+		case "query":
+			values = r.URL.Query()[p.Name]
+		case "header":
+			values = r.Header.Values(p.Name)
+		default:
+			continue
+		}
+
+		if len(values) == 0 || (len(values) == 1 && values[0] == "" && p.In != "query") {
+			if p.Required {
+				violations = append(violations, Violation{In: p.In, Name: p.Name, Message: "is required"})
+			}
+
+			continue
+		}
+
+		for _, problem := range v.validateParameter(p.Schema, values) {
+			violations = append(violations, Violation{In: p.In, Name: p.Name, Message: problem.message})
+		}
+	}
+
+	bodyViolations, err := v.validateBody(r, op)
+	if err != nil {
+		return nil, true, err
+	}
+
+	return append(violations, bodyViolations...), true, nil
+}
+
+// find returns the operation of the request with the parameters of its path and their values
+func (v *Validator) find(r *http.Request) (*Operation, []*Parameter, map[string]string) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	for _, rt := range v.routes {
+		if len(rt.segments) != len(segments) {
+			continue
+		}
+
+		values := map[string]string{}
+		matched := true
+		for i, segment := range rt.segments {
+			if isTemplate(segment) {
+				values[segment[1:len(segment)-1]] = segments[i]
+				continue
+			}
+
+			if segment != segments[i] {
+				matched = false
+				break
+			}
+		}
+
+		if !matched {
+			continue
+		}
+
+		if op, ok := rt.ops[r.Method]; ok {
+			return op, rt.params, values
+		}
+	}
+
+	return nil, nil, nil
+}
+
+// parameter resolves the $ref of a parameter
+func (v *Validator) parameter(p *Parameter) *Parameter {
+	if name, ok := strings.CutPrefix(p.Ref, "#/components/parameters/"); ok {
+		if resolved, ok := v.doc.Components.Parameters[name]; ok {
+			return resolved
+		}
+	}
+
+	return p
+}
+
+// validateParameter converts the string values of a parameter to the type of its schema and validates them
+func (v *Validator) validateParameter(schema *Schema, values []string) []problem {
+	schema = v.resolve(schema)
+	if schema == nil {
+		return nil
+	}
+
+	if schema.is("array") {
+		if len(values) == 1 {
+			values = strings.Split(values[0], ",")
+		}
+
+		items := make([]any, len(values))
+		for i, value := range values {
+			items[i] = parseValue(v.resolve(schema.Items), value)
+		}
+
+		return v.validate(schema, items, "")
+	}
+
+	return v.validate(schema, parseValue(schema, values[0]), "")
+}
+
+// validateBody validates the JSON body of the request
+func (v *Validator) validateBody(r *http.Request, op *Operation) ([]Violation, error) {
+	if op.RequestBody == nil {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, v.cfg.MaxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > v.cfg.MaxBodySize {
+		return nil, httpx.ErrRequestTooLarge.JSON(v.cfg.MaxBodySize)
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	if len(data) == 0 {
+		if op.RequestBody.Required {
+			return []Violation{{In: "body", Message: "is required"}}, nil
+		}
+
+		return nil, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	content, ok := op.RequestBody.Content[mediaType]
+	if !ok {
+		return []Violation{{In: "body", Message: fmt.Sprintf("content type %q isn't supported", mediaType)}}, nil
+	}
+
+	if content.Schema == nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+		return nil, nil
+	}
+
+	var body any
+	if err := json.Unmarshal(data, &body); err != nil {
+		return []Violation{{In: "body", Message: "isn't valid JSON"}}, nil
+	}
+
+	violations := []Violation{}
+	for _, problem := range v.validate(content.Schema, body, "") {
+		violations = append(violations, Violation{In: "body", Name: problem.path, Message: problem.message})
+	}
+
+	return violations, nil
+}
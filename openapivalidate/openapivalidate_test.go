@@ -0,0 +1,253 @@
+package openapivalidate
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gabivlj/httpx"
+)
+
+const spec = `
+openapi: 3.0.3
+paths:
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema: {type: integer, minimum: 1}
+    get:
+      parameters:
+        - $ref: '#/components/parameters/Fields'
+    put:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/User'}
+  /users/me:
+    get:
+      parameters:
+        - name: X-Session
+          in: header
+          required: true
+  /users:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema: {type: integer, maximum: 100}
+        - name: tag
+          in: query
+          schema:
+            type: array
+            maxItems: 2
+            items: {type: string, enum: [a, b, c]}
+components:
+  parameters:
+    Fields:
+      name: fields
+      in: query
+      schema: {type: string, pattern: '^[a-z,]+$'}
+  schemas:
+    User:
+      type: object
+      required: [name]
+      additionalProperties: false
+      properties:
+        name: {type: string, minLength: 1, maxLength: 10}
+        email: {type: string, nullable: true}
+        labels:
+          type: object
+          additionalProperties: {type: string, maxLength: 3}
+        items:
+          type: array
+          items:
+            type: object
+            properties:
+              price: {type: number, minimum: 0}
+`
+
+func TestValidate(t *testing.T) {
+	v, err := Load([]byte(spec), Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		header     http.Header
+		body       string
+		known      bool
+		violations []Violation
+	}{
+		{name: "valid path parameter", method: http.MethodGet, target: "/users/3", known: true},
+		{name: "invalid path parameter", method: http.MethodGet, target: "/users/0", known: true, violations: []Violation{
+			{In: "path", Name: "id", Message: "must be at least 1"},
+		}},
+		{name: "path parameter of the wrong type", method: http.MethodGet, target: "/users/al", known: true, violations: []Violation{
+			{In: "path", Name: "id", Message: "must be integer"},
+		}},
+		{name: "concrete path wins", method: http.MethodGet, target: "/users/me", known: true, violations: []Violation{
+			{In: "header", Name: "X-Session", Message: "is required"},
+		}},
+		{name: "referenced parameter", method: http.MethodGet, target: "/users/3?fields=Name", known: true, violations: []Violation{
+			{In: "query", Name: "fields", Message: "must match ^[a-z,]+$"},
+		}},
+		{name: "array parameter", method: http.MethodGet, target: "/users?tag=a,d&limit=101", known: true, violations: []Violation{
+			{In: "query", Name: "limit", Message: "must be at most 100"},
+			{In: "query", Name: "tag", Message: "must be one of [a b c]"},
+		}},
+		{name: "repeated array parameter", method: http.MethodGet, target: "/users?tag=a&tag=b&tag=c", known: true, violations: []Violation{
+			{In: "query", Name: "tag", Message: "must have at most 2 items"},
+		}},
+		{name: "unknown operation", method: http.MethodDelete, target: "/users/3"},
+		{name: "unknown path", method: http.MethodGet, target: "/teams"},
+		{name: "valid body", method: http.MethodPut, target: "/users/3", body: `{"name": "al", "email": null, "labels": {"a": "b"}, "items": [{"price": 1}]}`, known: true},
+		{name: "missing body", method: http.MethodPut, target: "/users/3", known: true, violations: []Violation{
+			{In: "body", Message: "is required"},
+		}},
+		{name: "invalid JSON", method: http.MethodPut, target: "/users/3", body: `{`, known: true, violations: []Violation{
+			{In: "body", Message: "isn't valid JSON"},
+		}},
+		{name: "unsupported content type", method: http.MethodPut, target: "/users/3", body: `name=al`, header: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}, known: true, violations: []Violation{
+			{In: "body", Message: `content type "application/x-www-form-urlencoded" isn't supported`},
+		}},
+		{name: "body violations", method: http.MethodPut, target: "/users/3", body: `{"admin": true, "labels": {"a": "long"}, "items": [{"price": 1}, {"price": -1}]}`, known: true, violations: []Violation{
+			{In: "body", Name: "name", Message: "is required"},
+			{In: "body", Name: "admin", Message: "isn't allowed"},
+			{In: "body", Name: "items.1.price", Message: "must be at least 0"},
+			{In: "body", Name: "labels.a", Message: "must have at most 3 characters"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			for key, values := range tt.header {
+				r.Header[key] = values
+			}
+
+			violations, known, err := v.Validate(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if known != tt.known {
+				t.Fatalf("expected known to be %v", tt.known)
+			}
+
+			if len(violations) == 0 && len(tt.violations) == 0 {
+				return
+			}
+
+			if !reflect.DeepEqual(violations, tt.violations) {
+				t.Fatalf("expected %+v, got %+v", tt.violations, violations)
+			}
+		})
+	}
+}
+
+func TestValidateKeepsBody(t *testing.T) {
+	v, err := Load([]byte(spec), Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodPut, "/users/3", strings.NewReader(`{"name": "al"}`))
+	r.Header.Set("Content-Type", "application/json")
+	if _, _, err := v.Validate(r); err != nil {
+		t.Fatal(err)
+	}
+
+	if body, _ := io.ReadAll(r.Body); string(body) != `{"name": "al"}` {
+		t.Fatalf("expected the body to be readable again, got %q", body)
+	}
+}
+
+func TestConcretePathsWinOverTemplates(t *testing.T) {
+	for range 20 {
+		v, err := Load([]byte(spec), Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		index := map[string]int{}
+		for i, rt := range v.routes {
+			index[strings.Join(rt.segments, "/")] = i
+		}
+
+		if index["users/me"] > index["users/{id}"] {
+			t.Fatal("expected users/me to be matched before users/{id}")
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	v, err := Load([]byte(spec), Config{RejectUnknown: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := httpx.H(v.Middleware(func(w http.ResponseWriter, r *http.Request) error {
+		return httpx.Code(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		target string
+		status int
+		code   string
+	}{
+		{target: "/users/3", status: http.StatusNoContent},
+		{target: "/users/0", status: http.StatusBadRequest, code: ErrInvalidRequest.Code},
+		{target: "/teams", status: http.StatusNotFound, code: httpx.ErrNotFound.Code},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if w.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, w.Code)
+			}
+
+			if tt.code == "" {
+				return
+			}
+
+			var body struct {
+				Code string `json:"code"`
+			}
+
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Code != tt.code {
+				t.Fatalf("expected %s, got %s", tt.code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := map[string]string{
+		"invalid document":   `paths: [`,
+		"swagger 2":          "swagger: '2.0'\npaths: {}",
+		"invalid pattern":    "openapi: 3.0.0\ncomponents:\n  schemas:\n    A: {type: string, pattern: '('}",
+		"nested pattern":     "openapi: 3.0.0\npaths:\n  /a:\n    post:\n      requestBody:\n        content:\n          application/json:\n            schema:\n              properties:\n                b: {items: {pattern: '[a-'}}",
+		"additional pattern": "openapi: 3.0.0\ncomponents:\n  schemas:\n    A: {additionalProperties: {pattern: '*'}}",
+	}
+
+	for name, doc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Load([]byte(doc), Config{}); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package openapivalidate
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema is the subset of JSON Schema used by OpenAPI to describe values
+type Schema struct {
+	Ref                  string             `yaml:"$ref"`
+	Type                 any                `yaml:"type"`
+	Nullable             bool               `yaml:"nullable"`
+	Enum                 []any              `yaml:"enum"`
+	Properties           map[string]*Schema `yaml:"properties"`
+	Required             []string           `yaml:"required"`
+	AdditionalProperties *Additional        `yaml:"additionalProperties"`
+	Items                *Schema            `yaml:"items"`
+	MinItems             *int               `yaml:"minItems"`
+	MaxItems             *int               `yaml:"maxItems"`
+	MinLength            *int               `yaml:"minLength"`
+	MaxLength            *int               `yaml:"maxLength"`
+	Pattern              string             `yaml:"pattern"`
+	Minimum              *float64           `yaml:"minimum"`
+	Maximum              *float64           `yaml:"maximum"`
+	AllOf                []*Schema          `yaml:"allOf"`
+	AnyOf                []*Schema          `yaml:"anyOf"`
+	OneOf                []*Schema          `yaml:"oneOf"`
+
+	patternOnce sync.Once
+	pattern     *regexp.Regexp
+	patternErr  error
+}
+
+// types returns the types of the schema, OpenAPI 3.1 allows a list of them
+func (s *Schema) types() []string {
+	switch t := s.Type.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, v := range t {
+			if name, ok := v.(string); ok {
+				types = append(types, name)
+			}
+		}
+
+		return types
+	}
+
+	return nil
+}
+
+func (s *Schema) is(t string) bool {
+	return slices.Contains(s.types(), t)
+}
+
+// problem is a value that doesn't match its schema, at the path of the field
+type problem struct {
+	path    string
+	message string
+}
+
+// resolve follows the $ref of a schema to the components
+func (v *Validator) resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/")
+		if !ok {
+			return nil
+		}
+
+		s = v.doc.Components.Schemas[name]
+	}
+
+	return s
+}
+
+// validate returns the problems of value, decoded from JSON, against the schema
+func (v *Validator) validate(s *Schema, value any, path string) []problem {
+	s = v.resolve(s)
+	if s == nil {
+		return nil
+	}
+
+	fail := func(format string, args ...any) []problem {
+		return []problem{{path: path, message: fmt.Sprintf(format, args...)}}
+	}
+
+	if value == nil {
+		if s.Nullable || s.is("null") || len(s.types()) == 0 {
+			return nil
+		}
+
+		return fail("must not be null")
+	}
+
+	if types := s.types(); len(types) > 0 && !slices.ContainsFunc(types, func(t string) bool { return hasType(value, t) }) {
+		return fail("must be %s", strings.Join(types, " or "))
+	}
+
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return equal(e, value) }) {
+		return fail("must be one of %v", s.Enum)
+	}
+
+	var problems []problem
+	switch value := value.(type) {
+	case string:
+		length := len([]rune(value))
+		if s.MinLength != nil && length < *s.MinLength {
+			problems = append(problems, fail("must have at least %d characters", *s.MinLength)...)
+		}
+
+		if s.MaxLength != nil && length > *s.MaxLength {
+			problems = append(problems, fail("must have at most %d characters", *s.MaxLength)...)
+		}
+
+		if re := s.regexp(); re != nil && !re.MatchString(value) {
+			problems = append(problems, fail("must match %s", s.Pattern)...)
+		}
+	case float64:
+		if s.Minimum != nil && value < *s.Minimum {
+			problems = append(problems, fail("must be at least %v", *s.Minimum)...)
+		}
+
+		if s.Maximum != nil && value > *s.Maximum {
+			problems = append(problems, fail("must be at most %v", *s.Maximum)...)
+		}
+	case []any:
+		if s.MinItems != nil && len(value) < *s.MinItems {
+			problems = append(problems, fail("must have at least %d items", *s.MinItems)...)
+		}
+
+		if s.MaxItems != nil && len(value) > *s.MaxItems {
+			problems = append(problems, fail("must have at most %d items", *s.MaxItems)...)
+		}
+
+		for i, item := range value {
+			problems = append(problems, v.validate(s.Items, item, join(path, strconv.Itoa(i)))...)
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := value[name]; !ok {
+				problems = append(problems, problem{path: join(path, name), message: "is required"})
+			}
+		}
+
+		names := slices.Sorted(maps.Keys(value))
+		for _, name := range names {
+			field := value[name]
+			if schema, ok := s.Properties[name]; ok {
+				problems = append(problems, v.validate(schema, field, join(path, name))...)
+				continue
+			}
+
+			if additional := s.AdditionalProperties; additional != nil && additional.Schema != nil {
+				problems = append(problems, v.validate(additional.Schema, field, join(path, name))...)
+			} else if additional != nil && !additional.Allowed {
+				problems = append(problems, problem{path: join(path, name), message: "isn't allowed"})
+			}
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		problems = append(problems, v.validate(sub, value, path)...)
+	}
+
+	if len(s.AnyOf) > 0 && !slices.ContainsFunc(s.AnyOf, func(sub *Schema) bool { return len(v.validate(sub, value, path)) == 0 }) {
+		problems = append(problems, fail("must match any of the schemas")...)
+	}
+
+	if len(s.OneOf) > 0 {
+		matches := 0
+		for _, sub := range s.OneOf {
+			if len(v.validate(sub, value, path)) == 0 {
+				matches++
+			}
+		}
+
+		if matches != 1 {
+			problems = append(problems, fail("must match exactly one of the schemas")...)
+		}
+	}
+
+	return problems
+}
+
+// Additional is the additionalProperties of a schema, false or a schema the properties that aren't in
+// Properties must match
+type Additional struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+// UnmarshalYAML decodes additionalProperties from a bool or a schema
+func (a *Additional) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&a.Allowed)
+	}
+
+	a.Allowed = true
+	return node.Decode(&a.Schema)
+}
+
+// regexp compiles the pattern of the schema once, invalid patterns are ignored
+func (s *Schema) regexp() *regexp.Regexp {
+	if s.Pattern == "" {
+		return nil
+	}
+
+	s.patternOnce.Do(func() { s.pattern, s.patternErr = regexp.Compile(s.Pattern) })
+	return s.pattern
+}
+
+// compilePatterns returns an error for the first pattern of the document that doesn't compile
+func (d *Document) compilePatterns() error {
+	var schemas []*Schema
+	parameters := slices.Collect(maps.Values(d.Components.Parameters))
+	schemas = slices.AppendSeq(schemas, maps.Values(d.Components.Schemas))
+	for _, item := range d.Paths {
+		parameters = append(parameters, item.Parameters...)
+		for _, op := range item.operations() {
+			parameters = append(parameters, op.Parameters...)
+			if op.RequestBody != nil {
+				for _, content := range op.RequestBody.Content {
+					schemas = append(schemas, content.Schema)
+				}
+			}
+		}
+	}
+
+	for _, p := range parameters {
+		schemas = append(schemas, p.Schema)
+	}
+
+	seen := map[*Schema]bool{}
+	for len(schemas) > 0 {
+		s := schemas[len(schemas)-1]
+		schemas = schemas[:len(schemas)-1]
+		if s == nil || seen[s] {
+			continue
+		}
+
+		seen[s] = true
+		if s.regexp(); s.patternErr != nil {
+			return fmt.Errorf("invalid pattern %q: %w", s.Pattern, s.patternErr)
+		}
+
+		schemas = slices.AppendSeq(schemas, maps.Values(s.Properties))
+		schemas = append(schemas, s.Items)
+		schemas = append(schemas, s.AllOf...)
+		schemas = append(schemas, s.AnyOf...)
+		schemas = append(schemas, s.OneOf...)
+		if s.AdditionalProperties != nil {
+			schemas = append(schemas, s.AdditionalProperties.Schema)
+		}
+	}
+
+	return nil
+}
+
+// hasType returns true if value, decoded from JSON, is of the JSON Schema type t
+func hasType(value any, t string) bool {
+	switch value := value.(type) {
+	case string:
+		return t == "string"
+	case bool:
+		return t == "boolean"
+	case float64:
+		return t == "number" || (t == "integer" && value == math.Trunc(value))
+	case []any:
+		return t == "array"
+	case map[string]any:
+		return t == "object"
+	}
+
+	return false
+}
+
+// equal compares an enum value decoded from YAML with a value decoded from JSON
+func equal(enum, value any) bool {
+	switch enum := enum.(type) {
+	case int:
+		return value == float64(enum)
+	case float64:
+		return value == enum
+	}
+
+	return enum == value
+}
+
+// parseValue converts a parameter to the type of its schema, values that can't be converted stay strings
+// so they fail the validation
+func parseValue(s *Schema, value string) any {
+	if s == nil {
+		return value
+	}
+
+	switch {
+	case s.is("integer"), s.is("number"):
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case s.is("boolean"):
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+
+	return value
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
@@ -0,0 +1,67 @@
+package httpx
+
+import (
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// MethodOverrideHeader is the header read by MethodOverride
+var MethodOverrideHeader = "X-HTTP-Method-Override"
+
+// MethodOverride returns a middleware that lets POST requests tunnel other methods, for clients like HTML forms
+// that can only send GET and POST. The method is read from the MethodOverrideHeader header, or from the _method
+// field of url-encoded forms. Only the allowed methods can be tunneled, PUT, PATCH and DELETE if none is passed,
+// other methods get a 405 ErrMethodNotAllowed. It has to wrap the router, so routing sees the new method.
+// Example:
+//
+//	http.ListenAndServe(":8080", httpx.MethodOverride()(mux))
+func MethodOverride(allowed ...string) func(http.Handler) http.Handler {
+	if len(allowed) == 0 {
+		allowed = []string{http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+
+	allowed = slices.Clone(allowed)
+	for i, method := range allowed {
+		allowed[i] = strings.ToUpper(method)
+	}
+
+	allow := strings.Join(append([]string{http.MethodPost}, allowed...), ", ")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			method := strings.ToUpper(strings.TrimSpace(overriddenMethod(r)))
+			if method == "" || method == http.MethodPost {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !slices.Contains(allowed, method) {
+				res := ErrMethodNotAllowed.JSON(method).Headers(map[string]string{"Allow": allow})
+				fireAfterMiddleware(res, w, withValues(r))
+				return
+			}
+
+			r.Method = method
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// overriddenMethod returns the method tunneled by r, or "" if there's none
+func overriddenMethod(r *http.Request) string {
+	if method := r.Header.Get(MethodOverrideHeader); method != "" {
+		return method
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/x-www-form-urlencoded" {
+		return ""
+	}
+
+	return r.PostFormValue("_method")
+}
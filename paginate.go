@@ -0,0 +1,71 @@
+package httpx
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PageCursorParam is the query parameter that carries the cursor in the Link headers of Paginated
+var PageCursorParam = "cursor"
+
+// PageInfo describes the page of a list returned with Paginated. Next and Prev are opaque cursors,
+// empty when there are no more pages in that direction.
+type PageInfo struct {
+	Total int
+	Next  string
+	Prev  string
+}
+
+// Page is the envelope rendered by Paginated
+type Page struct {
+	Items any    `json:"items"`
+	Total int    `json:"total"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+}
+
+// Paginated returns a 200 response with the items of a list and its page in a Page envelope.
+// Link headers with rel=next and rel=prev point to the request URL with the PageCursorParam
+// set to the cursors, keeping the rest of the query.
+// Example:
+//
+//	users, next, err := db.Users(r.Context(), r.URL.Query().Get("cursor"), 50)
+//	if err != nil {
+//		return err
+//	}
+//
+//	return httpx.Paginated(users, httpx.PageInfo{Total: total, Next: next})
+func Paginated(items any, page PageInfo) *Response {
+	res := OK(Page{Items: items, Total: page.Total, Next: page.Next, Prev: page.Prev})
+	res.page = &page
+	return res
+}
+
+// links returns the Link header of the page for the request URL u
+func (p *PageInfo) links(u *url.URL) string {
+	links := make([]string, 0, 2)
+	for _, link := range [...]struct{ cursor, rel string }{{p.Next, "next"}, {p.Prev, "prev"}} {
+		if link.cursor == "" {
+			continue
+		}
+
+		query := u.Query()
+		query.Set(PageCursorParam, link.cursor)
+		target := url.URL{Path: u.Path, RawQuery: query.Encode()}
+		links = append(links, "<"+target.String()+`>; rel="`+link.rel+`"`)
+	}
+
+	return strings.Join(links, ", ")
+}
+
+// writeLinks adds the pagination links of the response
+func (r *Response) writeLinks(w http.ResponseWriter, req *http.Request) {
+	if r.page == nil {
+		return
+	}
+
+	if links := r.page.links(req.URL); links != "" {
+		w.Header().Add("Link", links)
+	}
+}
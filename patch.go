@@ -0,0 +1,596 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Content types of the patch documents
+const (
+	JSONPatchType  = "application/json-patch+json"
+	MergePatchType = "application/merge-patch+json"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// Codes returned by the patch helpers, their extra payload is a PatchError
+var (
+	// ErrInvalidPatch is returned for patch documents that are malformed
+	ErrInvalidPatch = NewCode("INVALID_PATCH", http.StatusBadRequest)
+	// ErrPatchConflict is returned when a test operation fails, the resource isn't in the state the client expected
+	ErrPatchConflict = NewCode("PATCH_CONFLICT", http.StatusConflict)
+	// ErrPatchNotApplicable is returned when the patch can't be applied to the resource, like removing a field
+	// that doesn't exist, or the result doesn't fit in the target
+	ErrPatchNotApplicable = NewCode("PATCH_NOT_APPLICABLE", http.StatusUnprocessableEntity)
+)
+
+// PatchError describes which operation of a patch failed and why. Index is -1 for merge patches and
+// for errors of the patched document.
+type PatchError struct {
+	Index   int    `json:"index"`
+	Op      string `json:"op,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// patchOperation is an operation of a JSON Patch document
+type patchOperation struct {
+	Op    string           `json:"op"`
+	Path  *string          `json:"path"`
+	From  *string          `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// ApplyPatch applies the JSON Patch or the JSON Merge Patch of the body of r to target, depending on its
+// Content-Type. Other content types get a 415 ErrUnsupportedMediaType with the Accept-Patch header.
+// Example:
+//
+//	mux.PATCH("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+//		user := users.Get(httpx.Param(r, "id"))
+//		if err := httpx.ApplyPatch(r, &user); err != nil {
+//			return err
+//		}
+//
+//		users.Put(user)
+//		return httpx.OK(user)
+//	})
+func ApplyPatch(r *http.Request, target any, opts ...DecodeOption) error {
+	switch patchType(r) {
+	case JSONPatchType:
+		return ApplyJSONPatch(r, target, opts...)
+	case MergePatchType:
+		return ApplyMergePatch(r, target, opts...)
+	}
+
+	return unsupportedPatch(r)
+}
+
+// ApplyJSONPatch applies the JSON Patch (RFC 6902) of the body of r to target, which is encoded as JSON,
+// patched and decoded back. Fields JSON doesn't see, like unexported or json:"-" ones, keep their values.
+// Target is only changed when every operation succeeds. Malformed patches get a
+// 400 ErrInvalidPatch, failed test operations a 409 ErrPatchConflict and operations that can't be applied
+// a 422 ErrPatchNotApplicable. Bodies without the application/json-patch+json content type get a 415.
+func ApplyJSONPatch(r *http.Request, target any, opts ...DecodeOption) error {
+	if patchType(r) != JSONPatchType {
+		return unsupportedPatch(r)
+	}
+
+	var ops []patchOperation
+	if err := DecodeJSON(r, &ops, patchOptions(opts)...); err != nil {
+		return err
+	}
+
+	doc, err := patchDocument(target)
+	if err != nil {
+		return err
+	}
+
+	for i, op := range ops {
+		if doc, err = op.apply(doc); err != nil {
+			return patchError(err, PatchError{Index: i, Op: op.Op, Path: op.path()})
+		}
+	}
+
+	return patchResult(doc, target)
+}
+
+// ApplyMergePatch applies the JSON Merge Patch (RFC 7396) of the body of r to target: fields of the patch
+// replace the ones of target and null fields remove them. Like ApplyJSONPatch target is only changed when
+// the patch applies, and bodies without the application/merge-patch+json content type get a 415.
+func ApplyMergePatch(r *http.Request, target any, opts ...DecodeOption) error {
+	if patchType(r) != MergePatchType {
+		return unsupportedPatch(r)
+	}
+
+	var raw json.RawMessage
+	if err := DecodeJSON(r, &raw, patchOptions(opts)...); err != nil {
+		return err
+	}
+
+	patch, err := decodeNumbers(raw)
+	if err != nil {
+		return ErrInvalidPatch.JSON(PatchError{Index: -1, Message: err.Error()})
+	}
+
+	doc, err := patchDocument(target)
+	if err != nil {
+		return err
+	}
+
+	return patchResult(mergePatch(doc, patch), target)
+}
+
+func patchType(r *http.Request) string {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType
+}
+
+func unsupportedPatch(r *http.Request) error {
+	return ErrUnsupportedMediaType.JSON(r.Header.Get("Content-Type")).
+		Headers(map[string]string{"Accept-Patch": JSONPatchType + ", " + MergePatchType})
+}
+
+// patchOptions limits the size of patches to DefaultJSONMaxBytes unless opts set another limit
+func patchOptions(opts []DecodeOption) []DecodeOption {
+	return append([]DecodeOption{DecodeMaxBytes(DefaultJSONMaxBytes)}, opts...)
+}
+
+// patchDocument returns target as a generic JSON document
+func patchDocument(target any) (any, error) {
+	data, err := json.Marshal(target)
+	if err != nil {
+		return nil, fmt.Errorf("encoding patch target: %w", err)
+	}
+
+	return decodeNumbers(data)
+}
+
+// patchResult decodes the patched document into target, resetting it first so removed fields are cleared
+func patchResult(doc any, target any) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("encoding patched document: %w", err)
+	}
+
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("httpx: patch target must be a non nil pointer, got %T", target)
+	}
+
+	// the result starts as a copy of target without the fields JSON sees, so the fields it doesn't,
+	// like unexported or json:"-" ones, keep their values
+	result := reflect.New(v.Elem().Type())
+	if result.Elem().Kind() == reflect.Struct {
+		result.Elem().Set(v.Elem())
+		clearJSONFields(result.Elem())
+	}
+
+	if err := json.Unmarshal(data, result.Interface()); err != nil {
+		return ErrPatchNotApplicable.JSON(PatchError{Index: -1, Message: err.Error()})
+	}
+
+	v.Elem().Set(result.Elem())
+	return nil
+}
+
+// clearJSONFields zeroes the fields of the struct v that encoding/json encodes and decodes. Nested structs
+// are cleared field by field and the ones behind pointers are copied first, so target isn't modified.
+func clearJSONFields(v reflect.Value) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field, value := t.Field(i), v.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		// fields promoted from unexported embedded structs can't be set with reflect, they are only overwritten
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+
+		switch {
+		case decodesItself(field.Type):
+			value.SetZero()
+		case field.Type.Kind() == reflect.Struct:
+			clearJSONFields(value)
+		case field.Type.Kind() == reflect.Pointer && field.Type.Elem().Kind() == reflect.Struct && !decodesItself(field.Type.Elem()):
+			if value.IsNil() {
+				continue
+			}
+
+			copied := reflect.New(field.Type.Elem())
+			copied.Elem().Set(value.Elem())
+			clearJSONFields(copied.Elem())
+			value.Set(copied)
+		default:
+			value.SetZero()
+		}
+	}
+}
+
+// decodesItself returns true if encoding/json decodes t with its own UnmarshalJSON or UnmarshalText, like time.Time
+func decodesItself(t reflect.Type) bool {
+	ptr := reflect.PointerTo(t)
+	return ptr.Implements(jsonUnmarshalerType) || ptr.Implements(textUnmarshalerType)
+}
+
+// decodeNumbers decodes data keeping numbers as json.Number, so big integers don't lose precision
+func decodeNumbers(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// mergePatch applies patch to doc following RFC 7396
+func mergePatch(doc, patch any) any {
+	fields, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	target, ok := doc.(map[string]any)
+	if !ok {
+		target = map[string]any{}
+	}
+
+	for key, value := range fields {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+
+		target[key] = mergePatch(target[key], value)
+	}
+
+	return target
+}
+
+// errors of the operations, mapped to the codes by patchError
+type (
+	invalidPatch  string
+	patchConflict string
+	notApplicable string
+)
+
+func (e invalidPatch) Error() string  { return string(e) }
+func (e patchConflict) Error() string { return string(e) }
+func (e notApplicable) Error() string { return string(e) }
+
+func patchError(err error, info PatchError) error {
+	info.Message = err.Error()
+	switch err.(type) {
+	case invalidPatch:
+		return ErrInvalidPatch.JSON(info)
+	case patchConflict:
+		return ErrPatchConflict.JSON(info)
+	}
+
+	return ErrPatchNotApplicable.JSON(info)
+}
+
+func (op patchOperation) path() string {
+	if op.Path == nil {
+		return ""
+	}
+
+	return *op.Path
+}
+
+// apply applies the operation to doc and returns the result
+func (op patchOperation) apply(doc any) (any, error) {
+	if op.Path == nil {
+		return nil, invalidPatch("missing path")
+	}
+
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, invalidPatch("missing value")
+		}
+
+		if value, err = decodeNumbers(*op.Value); err != nil {
+			return nil, invalidPatch("invalid value: " + err.Error())
+		}
+	case "move", "copy":
+		if op.From == nil {
+			return nil, invalidPatch("missing from")
+		}
+
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		if op.Op == "move" && len(path) > len(from) && path.hasPrefix(from) {
+			return nil, invalidPatch("a value can't be moved into itself")
+		}
+
+		if value, err = from.get(doc); err != nil {
+			return nil, err
+		}
+
+		if op.Op == "move" {
+			if doc, err = from.remove(doc); err != nil {
+				return nil, err
+			}
+		} else {
+			value = deepCopyJSON(value)
+		}
+	case "remove":
+	default:
+		return nil, invalidPatch(fmt.Sprintf("unknown operation %q", op.Op))
+	}
+
+	switch op.Op {
+	case "remove":
+		return path.remove(doc)
+	case "replace":
+		if _, err := path.get(doc); err != nil || len(path) == 0 {
+			return value, err
+		}
+
+		if doc, err = path.remove(doc); err != nil {
+			return nil, err
+		}
+
+		return path.add(doc, value)
+	case "test":
+		current, err := path.get(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if !equalJSON(current, value) {
+			return nil, patchConflict("test failed")
+		}
+
+		return doc, nil
+	}
+
+	return path.add(doc, value)
+}
+
+// jsonPointer is a parsed JSON Pointer (RFC 6901), the empty pointer is the whole document
+type jsonPointer []string
+
+var (
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+)
+
+func parsePointer(s string) (jsonPointer, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(s, "/") {
+		return nil, invalidPatch(fmt.Sprintf("invalid pointer %q", s))
+	}
+
+	tokens := strings.Split(s[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = pointerUnescaper.Replace(token)
+	}
+
+	return tokens, nil
+}
+
+func (p jsonPointer) hasPrefix(prefix jsonPointer) bool {
+	return len(p) >= len(prefix) && reflect.DeepEqual([]string(p[:len(prefix)]), []string(prefix))
+}
+
+func (p jsonPointer) String() string {
+	var b strings.Builder
+	for _, token := range p {
+		b.WriteString("/" + pointerEscaper.Replace(token))
+	}
+
+	return b.String()
+}
+
+// get returns the value p points to
+func (p jsonPointer) get(doc any) (any, error) {
+	for i, token := range p {
+		switch container := doc.(type) {
+		case map[string]any:
+			value, ok := container[token]
+			if !ok {
+				return nil, notApplicable(p[:i+1].String() + " doesn't exist")
+			}
+
+			doc = value
+		case []any:
+			index, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+
+			doc = container[index]
+		default:
+			return nil, notApplicable(p[:i].String() + " is not an object or array")
+		}
+	}
+
+	return doc, nil
+}
+
+// add adds value where p points to, replacing members of objects and inserting into arrays
+func (p jsonPointer) add(doc, value any) (any, error) {
+	if len(p) == 0 {
+		return value, nil
+	}
+
+	return p.update(doc, func(container any, token string) (any, error) {
+		switch container := container.(type) {
+		case map[string]any:
+			container[token] = value
+			return container, nil
+		case []any:
+			if token == "-" {
+				return append(container, value), nil
+			}
+
+			index, err := arrayIndex(token, len(container))
+			if err != nil {
+				return nil, err
+			}
+
+			container = append(container, nil)
+			copy(container[index+1:], container[index:])
+			container[index] = value
+			return container, nil
+		}
+
+		return nil, notApplicable(p[:len(p)-1].String() + " is not an object or array")
+	})
+}
+
+// remove removes the value p points to
+func (p jsonPointer) remove(doc any) (any, error) {
+	if len(p) == 0 {
+		return nil, notApplicable("the whole document can't be removed")
+	}
+
+	return p.update(doc, func(container any, token string) (any, error) {
+		switch container := container.(type) {
+		case map[string]any:
+			if _, ok := container[token]; !ok {
+				return nil, notApplicable(p.String() + " doesn't exist")
+			}
+
+			delete(container, token)
+			return container, nil
+		case []any:
+			index, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+
+			return append(container[:index], container[index+1:]...), nil
+		}
+
+		return nil, notApplicable(p[:len(p)-1].String() + " is not an object or array")
+	})
+}
+
+// update replaces the parent of p with the result of fn called with it and the last token of p
+func (p jsonPointer) update(doc any, fn func(container any, token string) (any, error)) (any, error) {
+	if len(p) == 1 {
+		return fn(doc, p[0])
+	}
+
+	child, err := p[:1].get(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := p[1:].update(child, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch container := doc.(type) {
+	case map[string]any:
+		container[p[0]] = updated
+	case []any:
+		index, _ := arrayIndex(p[0], len(container)-1)
+		container[index] = updated
+	}
+
+	return doc, nil
+}
+
+// arrayIndex parses an array index that can't be bigger than max
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || token != "0" && token[0] == '0' || strings.TrimLeft(token, "0123456789") != "" {
+		return 0, invalidPatch(fmt.Sprintf("invalid array index %q", token))
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index > max {
+		return 0, notApplicable(fmt.Sprintf("array index %s out of bounds", token))
+	}
+
+	return index, nil
+}
+
+// equalJSON compares generic JSON values, numbers are compared by value
+func equalJSON(a, b any) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+
+		x, okX := new(big.Float).SetString(a.String())
+		y, okY := new(big.Float).SetString(b.String())
+		return okX && okY && x.Cmp(y) == 0
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+
+		for key, value := range a {
+			other, ok := b[key]
+			if !ok || !equalJSON(value, other) {
+				return false
+			}
+		}
+
+		return true
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+
+		for i := range a {
+			if !equalJSON(a[i], b[i]) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return a == b
+}
+
+// deepCopyJSON copies a generic JSON value, so copied values aren't shared
+func deepCopyJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			m[key] = deepCopyJSON(value)
+		}
+
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, value := range v {
+			s[i] = deepCopyJSON(value)
+		}
+
+		return s
+	}
+
+	return v
+}
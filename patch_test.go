@@ -0,0 +1,100 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type patchProfile struct {
+	Bio    string `json:"bio,omitempty"`
+	Secret string `json:"-"`
+}
+
+type patchUser struct {
+	ID           int64         `json:"id"`
+	Name         string        `json:"name"`
+	Email        string        `json:"email,omitempty"`
+	Tags         []string      `json:"tags"`
+	Created      time.Time     `json:"created"`
+	Profile      patchProfile  `json:"profile"`
+	Settings     *patchProfile `json:"settings,omitempty"`
+	PasswordHash string        `json:"-"`
+	internalID   int
+}
+
+func newPatchUser() patchUser {
+	return patchUser{
+		ID:           1,
+		Name:         "al",
+		Email:        "al@example.com",
+		Tags:         []string{"a", "b"},
+		Created:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Profile:      patchProfile{Bio: "hi", Secret: "profile secret"},
+		Settings:     &patchProfile{Bio: "dark", Secret: "settings secret"},
+		PasswordHash: "hash",
+		internalID:   42,
+	}
+}
+
+func patchRequest(contentType, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+	return r
+}
+
+func TestApplyPatchKeepsHiddenFields(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"json patch", JSONPatchType, `[{"op":"replace","path":"/name","value":"bob"},{"op":"remove","path":"/email"},{"op":"remove","path":"/profile/bio"},{"op":"remove","path":"/settings/bio"}]`},
+		{"merge patch", MergePatchType, `{"name":"bob","email":null,"profile":{"bio":null},"settings":{"bio":null}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newPatchUser()
+			settings := user.Settings
+			if err := ApplyPatch(patchRequest(tt.contentType, tt.body), &user); err != nil {
+				t.Fatalf("applying patch: %v", err)
+			}
+
+			if user.Name != "bob" || user.Email != "" || user.Profile.Bio != "" || user.Settings.Bio != "" {
+				t.Errorf("patch not applied: %+v", user)
+			}
+
+			if user.PasswordHash != "hash" || user.internalID != 42 {
+				t.Errorf("hidden fields lost: %+v", user)
+			}
+
+			if user.Profile.Secret != "profile secret" || user.Settings.Secret != "settings secret" {
+				t.Errorf("hidden fields of nested structs lost: %+v %+v", user.Profile, user.Settings)
+			}
+
+			if !user.Created.Equal(newPatchUser().Created) || len(user.Tags) != 2 {
+				t.Errorf("untouched fields changed: %+v", user)
+			}
+
+			if settings.Bio != "dark" {
+				t.Errorf("the previous value was modified: %+v", settings)
+			}
+		})
+	}
+}
+
+func TestApplyPatchFailureKeepsTarget(t *testing.T) {
+	user := newPatchUser()
+	err := ApplyJSONPatch(patchRequest(JSONPatchType, `[{"op":"replace","path":"/name","value":"bob"},{"op":"test","path":"/id","value":2}]`), &user)
+	res, ok := err.(*Response)
+	if !ok || res.Code != http.StatusConflict {
+		t.Fatalf("expected a 409, got %v", err)
+	}
+
+	if user.Name != "al" {
+		t.Errorf("target modified by a failed patch: %+v", user)
+	}
+}
@@ -0,0 +1,113 @@
+package httpx
+
+import (
+	"net/http"
+)
+
+// BeforeWrite is fired with the prepared response of each httpx handler before it's written, it can change
+// its status and headers or replace it. Nothing has been sent to the client yet.
+// Example:
+//
+//	httpx.BeforeWrite = func(p *httpx.Prepared, r *http.Request) {
+//		if p.Status() == http.StatusInternalServerError && deploying.Load() {
+//			p.Replace(httpx.ServiceUnavailable(30 * time.Second))
+//		}
+//	}
+var BeforeWrite = func(p *Prepared, r *http.Request) {}
+
+// Prepared is a response ready to be written, returned by Prepare. Its status and headers can be changed,
+// or the response replaced, until it's committed. Nothing reaches the client before Commit.
+type Prepared struct {
+	res    *Response
+	w      http.ResponseWriter
+	req    *http.Request
+	status int
+	header http.Header
+	done   bool
+}
+
+// Prepare stages the response to be written to w, the headers already set on w are kept.
+// Middleware that write responses themselves can use it to let others change them before they're sent.
+// Example:
+//
+//	p := res.Prepare(w, r)
+//	p.Header().Set("X-Served-By", hostname)
+//	return p.Commit()
+func (r *Response) Prepare(w http.ResponseWriter, req *http.Request) *Prepared {
+	p := &Prepared{w: w, req: req}
+	p.stage(r)
+	return p
+}
+
+// stage makes res the response to write
+func (p *Prepared) stage(res *Response) {
+	p.res, p.status, p.header = res, res.Code, nil
+}
+
+// Response returns the prepared response
+func (p *Prepared) Response() *Response {
+	return p.res
+}
+
+// Status returns the status that will be sent
+func (p *Prepared) Status() int {
+	return p.status
+}
+
+// SetStatus changes the status that will be sent
+func (p *Prepared) SetStatus(code int) {
+	p.status = code
+}
+
+// Header returns the headers that will be sent, they can be modified until Commit
+func (p *Prepared) Header() http.Header {
+	if p.header != nil {
+		return p.header
+	}
+
+	p.header = p.w.Header().Clone()
+	p.res.applyHeaders(p.header)
+	return p.header
+}
+
+// Replace discards the prepared response and its changes, preparing res instead
+func (p *Prepared) Replace(res *Response) {
+	_ = p.res.discard()
+	p.stage(res)
+}
+
+// Commit writes the response with the staged status and headers. It does nothing if it was already committed or aborted.
+func (p *Prepared) Commit() error {
+	if p.done {
+		return nil
+	}
+
+	p.done = true
+	if p.header == nil && p.status == p.res.Code {
+		return p.res.Write(p.w, p.req)
+	}
+
+	res := *p.res
+	res.Code, res.frozen = p.status, false
+	if p.header != nil {
+		header := p.w.Header()
+		clear(header)
+		for key, values := range p.header {
+			header[key] = values
+		}
+
+		res.headers, res.multiHeaders = nil, nil
+	}
+
+	return res.Write(p.w, p.req)
+}
+
+// Abort discards the response without writing anything, closing its body if it has to.
+func (p *Prepared) Abort() error {
+	if p.done {
+		return nil
+	}
+
+	p.done = true
+	return p.res.discard()
+}
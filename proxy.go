@@ -0,0 +1,53 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// ErrUpstreamUnreachable is the code ProxyErrorHandler returns when the proxy can't connect to the upstream
+var ErrUpstreamUnreachable = NewCode("UPSTREAM_UNREACHABLE", http.StatusBadGateway)
+
+// ProxyErrorHandler returns an ErrorHandler for httputil.ReverseProxy that responds with error codes
+// instead of a blank 502: ErrUpstreamUnreachable when the upstream can't be dialed, ErrTimeout when it
+// takes too long and code for any other error. When the client canceled the request nothing is written
+// and ClientAbortHandler is fired.
+// Example:
+//
+//	var ErrUpstream = httpx.NewCode("UPSTREAM_ERROR", http.StatusBadGateway)
+//
+//	proxy := httputil.NewSingleHostReverseProxy(target)
+//	proxy.ErrorHandler = httpx.ProxyErrorHandler(ErrUpstream)
+func ProxyErrorHandler(code *ErrorJSONCode) func(http.ResponseWriter, *http.Request, error) {
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		if errors.Is(err, context.Canceled) || errors.Is(r.Context().Err(), context.Canceled) {
+			ClientAbortHandler(r, err)
+			return
+		}
+
+		r = withValues(r)
+		fireAfterMiddleware(proxyError(code, err), w, r)
+	}
+}
+
+// proxyError returns the response of an error proxying a request
+func proxyError(code *ErrorJSONCode, err error) *Response {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrTimeout.JSON()
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return ErrUpstreamUnreachable.JSON()
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrUpstreamUnreachable.JSON()
+	}
+
+	return code.JSON()
+}
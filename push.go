@@ -0,0 +1,109 @@
+package httpx
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// PushHint hints the client to fetch the critical subresources of the response, like the stylesheets
+// and scripts of a HTML page. A Link rel=preload header is added for each path, and they're pushed
+// with HTTP/2 server push when the connection supports it.
+// Example:
+//
+//	return httpx.Code(http.StatusOK).PushHint("/static/app.css", "/static/app.js").Blob("text/html; charset=utf-8", page)
+func (r *Response) PushHint(paths ...string) *Response {
+	r = r.mutable()
+	for _, p := range paths {
+		r.addLink(preloadLink(p))
+	}
+
+	r.pushes = append(r.pushes[:len(r.pushes):len(r.pushes)], paths...)
+	return r
+}
+
+// addLink adds a value to the Link header of the response
+func (r *Response) addLink(link string) {
+	if current := r.headers["Link"]; current != "" {
+		link = current + ", " + link
+	}
+
+	r.setHeader("Link", link)
+}
+
+// push pushes the hinted paths if w supports it, errors are ignored because pushing is only an optimization
+func (r *Response) push(w http.ResponseWriter) {
+	if len(r.pushes) == 0 {
+		return
+	}
+
+	pusher, ok := w.(http.Pusher)
+	if !ok {
+		return
+	}
+
+	for _, p := range r.pushes {
+		if err := pusher.Push(p, nil); err != nil {
+			return
+		}
+	}
+}
+
+// EarlyHints sends a 103 Early Hints response with a Link header for each link, so the client can start
+// fetching them while the final response is prepared. Links can be paths, that are preloaded like
+// with PushHint, or complete Link values. The Link headers are kept for the final response.
+// Example:
+//
+//	func page(w http.ResponseWriter, r *http.Request) error {
+//		httpx.EarlyHints(w, "/static/app.css", "</static/app.js>; rel=modulepreload")
+//		data, err := slowQuery(r.Context())
+//		...
+//	}
+func EarlyHints(w http.ResponseWriter, links ...string) {
+	for _, link := range links {
+		if !strings.HasPrefix(link, "<") {
+			link = preloadLink(link)
+		}
+
+		w.Header().Add("Link", link)
+	}
+
+	w.WriteHeader(http.StatusEarlyHints)
+}
+
+// EarlyHints makes the response send a 103 Early Hints response with the links before its header.
+// It's useful for responses with slow bodies, like Stream, most of the time calling the EarlyHints
+// function at the start of the handler gives the client more time.
+func (r *Response) EarlyHints(links ...string) *Response {
+	r = r.mutable()
+	r.earlyHints = append(r.earlyHints[:len(r.earlyHints):len(r.earlyHints)], links...)
+	return r
+}
+
+// preloadLink returns the Link rel=preload value of p
+func preloadLink(p string) string {
+	link := "<" + p + ">; rel=preload"
+	if as := preloadAs(p); as != "" {
+		link += "; as=" + as
+	}
+
+	return link
+}
+
+// preloadAs returns the destination of a preloaded path from its extension
+func preloadAs(p string) string {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".css":
+		return "style"
+	case ".js", ".mjs":
+		return "script"
+	case ".woff", ".woff2", ".ttf", ".otf":
+		return "font"
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".avif", ".svg", ".ico":
+		return "image"
+	case ".json":
+		return "fetch"
+	}
+
+	return ""
+}
@@ -0,0 +1,172 @@
+package httpx
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrRangeNotSatisfiable is the code returned by ParseRange when none of the ranges of the request
+// overlap with the content
+var ErrRangeNotSatisfiable = NewCode("RANGE_NOT_SATISFIABLE", http.StatusRequestedRangeNotSatisfiable)
+
+// ByteRange is a validated range of bytes of a content, starting at Start
+type ByteRange struct {
+	Start  int64
+	Length int64
+}
+
+// contentRange returns the Content-Range value of the range in a content of size bytes
+func (b ByteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", b.Start, b.Start+b.Length-1, size)
+}
+
+// ParseRange parses the Range header of r for a content of size bytes, for handlers serving content
+// that isn't a local file, like objects of a bucket. It returns no ranges when the whole content must
+// be sent: the request has no Range header, it's not valid or it asks for more bytes than the content has.
+// When none of the ranges can be satisfied the error is a 416 ErrRangeNotSatisfiable response.
+// The If-Range header isn't checked, handlers with an ETag must ignore the ranges when it doesn't match.
+// Example:
+//
+//	ranges, err := httpx.ParseRange(r, obj.Size)
+//	if err != nil {
+//		return err
+//	}
+//
+//	return httpx.Code(http.StatusOK).Headers(map[string]string{"Content-Type": obj.ContentType}).PartialContent(ranges, obj.Size, obj)
+func ParseRange(r *http.Request, size int64) ([]ByteRange, error) {
+	header := r.Header.Get("Range")
+	unit, specs, ok := strings.Cut(header, "=")
+	if !ok || strings.TrimSpace(unit) != "bytes" {
+		return nil, nil
+	}
+
+	var ranges []ByteRange
+	var total int64
+	for _, spec := range strings.Split(specs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		br, valid, satisfiable := parseByteRange(spec, size)
+		if !valid {
+			return nil, nil
+		}
+
+		if satisfiable {
+			ranges = append(ranges, br)
+			total += br.Length
+		}
+	}
+
+	if len(ranges) == 0 {
+		return nil, ErrRangeNotSatisfiable.JSON().Headers(map[string]string{
+			"Content-Range": "bytes */" + strconv.FormatInt(size, 10),
+		})
+	}
+
+	if total > size {
+		// the ranges overlap, sending the whole content is cheaper
+		return nil, nil
+	}
+
+	return ranges, nil
+}
+
+// parseByteRange parses a range spec like "0-99", "100-" or "-100"
+func parseByteRange(spec string, size int64) (br ByteRange, valid, satisfiable bool) {
+	first, last, ok := strings.Cut(spec, "-")
+	if !ok {
+		return br, false, false
+	}
+
+	first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+	if first == "" {
+		// suffix range, the last bytes of the content
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return br, false, false
+		}
+
+		n = min(n, size)
+		return ByteRange{Start: size - n, Length: n}, true, n > 0
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return br, false, false
+	}
+
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return br, false, false
+		}
+
+		end = min(end, size-1)
+	}
+
+	if start >= size {
+		return br, true, false
+	}
+
+	return ByteRange{Start: start, Length: end - start + 1}, true, true
+}
+
+// PartialContent sends the ranges of a content of size bytes read from reader, usually returned by ParseRange.
+// Without ranges the whole content is sent with the status code of the response, a single range is
+// sent as a 206 with its Content-Range and many as a 206 multipart/byteranges, whose parts get
+// the Content-Type the response has when PartialContent is called.
+func (r *Response) PartialContent(ranges []ByteRange, size int64, reader io.ReaderAt) *Response {
+	r = r.mutable()
+	r.setHeader("Accept-Ranges", "bytes")
+	switch len(ranges) {
+	case 0:
+		return r.section(reader, ByteRange{Start: 0, Length: size})
+	case 1:
+		r.Code = http.StatusPartialContent
+		r.setHeader("Content-Range", ranges[0].contentRange(size))
+		return r.section(reader, ranges[0])
+	}
+
+	contentType := r.headers["Content-Type"]
+	body := NewMultipartBody("byteranges")
+	for _, br := range ranges {
+		body.Part(br.partHeader(size, contentType), br.Length, func(w io.Writer) error {
+			_, err := io.Copy(w, io.NewSectionReader(reader, br.Start, br.Length))
+			return err
+		})
+	}
+
+	r.Code = http.StatusPartialContent
+	return r.Multipart(body)
+}
+
+// section sets the range of reader as the body
+func (r *Response) section(reader io.ReaderAt, br ByteRange) *Response {
+	r.resetBody(br.Length)
+	r.Copy = func(w io.Writer) error { return copyReader(w, io.NewSectionReader(reader, br.Start, br.Length)) }
+	return r
+}
+
+// partHeader returns the header of the range in a multipart/byteranges body
+func (b ByteRange) partHeader(size int64, contentType string) map[string]string {
+	header := map[string]string{"Content-Range": b.contentRange(size)}
+	if contentType != "" {
+		header["Content-Type"] = contentType
+	}
+
+	return header
+}
+
+// countingWriter counts the bytes written to it
+type countingWriter int64
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	*c += countingWriter(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,130 @@
+package httpx
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   []ByteRange
+		err    bool
+	}{
+		{name: "no header"},
+		{name: "other unit", header: "items=0-1"},
+		{name: "first bytes", header: "bytes=0-9", want: []ByteRange{{Start: 0, Length: 10}}},
+		{name: "open end", header: "bytes=90-", want: []ByteRange{{Start: 90, Length: 10}}},
+		{name: "suffix", header: "bytes=-5", want: []ByteRange{{Start: 95, Length: 5}}},
+		{name: "suffix longer than the content", header: "bytes=-500", want: []ByteRange{{Start: 0, Length: 100}}},
+		{name: "end past the content", header: "bytes=95-200", want: []ByteRange{{Start: 95, Length: 5}}},
+		{name: "many", header: "bytes=0-0, -1", want: []ByteRange{{Start: 0, Length: 1}, {Start: 99, Length: 1}}},
+		{name: "unsatisfiable ranges are skipped", header: "bytes=0-0,200-300", want: []ByteRange{{Start: 0, Length: 1}}},
+		{name: "overlapping", header: "bytes=0-79,20-99"},
+		{name: "invalid", header: "bytes=a-b"},
+		{name: "end before start", header: "bytes=9-0"},
+		{name: "invalid among valid", header: "bytes=0-9,x"},
+		{name: "not satisfiable", header: "bytes=100-", err: true},
+		{name: "empty suffix", header: "bytes=-0", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set("Range", tt.header)
+			}
+
+			ranges, err := ParseRange(r, 100)
+			if !reflect.DeepEqual(ranges, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, ranges)
+			}
+
+			if !tt.err {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+
+				return
+			}
+
+			var res *Response
+			if !errors.As(err, &res) || res.Code != http.StatusRequestedRangeNotSatisfiable || res.headers["Content-Range"] != "bytes */100" {
+				t.Fatalf("expected a 416 with the Content-Range, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPartialContent(t *testing.T) {
+	content := strings.NewReader("0123456789")
+	tests := []struct {
+		name         string
+		ranges       []ByteRange
+		status       int
+		contentRange string
+		body         string
+		parts        []string
+	}{
+		{name: "whole content", status: http.StatusOK, body: "0123456789"},
+		{name: "single range", ranges: []ByteRange{{Start: 2, Length: 3}}, status: http.StatusPartialContent, contentRange: "bytes 2-4/10", body: "234"},
+		{name: "many ranges", ranges: []ByteRange{{Start: 0, Length: 2}, {Start: 8, Length: 2}}, status: http.StatusPartialContent, parts: []string{"01", "89"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Code(http.StatusOK).Headers(map[string]string{"Content-Type": "text/plain"}).PartialContent(tt.ranges, 10, content)
+			w := httptest.NewRecorder()
+			H(func(w http.ResponseWriter, r *http.Request) error { return res })(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != tt.status || w.Header().Get("Accept-Ranges") != "bytes" || w.Header().Get("Content-Range") != tt.contentRange {
+				t.Fatalf("expected %d with Content-Range %q, got %d %v", tt.status, tt.contentRange, w.Code, w.Header())
+			}
+
+			if tt.parts == nil {
+				if w.Body.String() != tt.body {
+					t.Fatalf("expected %q, got %q", tt.body, w.Body.String())
+				}
+
+				return
+			}
+
+			mediaType, params, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+			if err != nil || mediaType != "multipart/byteranges" {
+				t.Fatalf("expected a multipart/byteranges body, got %q", w.Header().Get("Content-Type"))
+			}
+
+			mr := multipart.NewReader(w.Body, params["boundary"])
+			for i, want := range tt.parts {
+				part, err := mr.NextPart()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got, _ := io.ReadAll(part)
+				if string(got) != want || part.Header.Get("Content-Type") != "text/plain" || part.Header.Get("Content-Range") != tt.ranges[i].contentRange(10) {
+					t.Fatalf("expected part %q, got %q %v", want, got, part.Header)
+				}
+			}
+		})
+	}
+}
+
+func TestPartialContentCopiesAgain(t *testing.T) {
+	// every copy reads its own section of the content
+	res := Code(http.StatusOK).PartialContent([]ByteRange{{Start: 2, Length: 3}}, 10, strings.NewReader("0123456789"))
+	for i := range 2 {
+		w := httptest.NewRecorder()
+		H(func(w http.ResponseWriter, r *http.Request) error { return res })(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Body.String() != "234" {
+			t.Fatalf("copy %d: expected %q, got %q", i, "234", w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// package ratelimit has a token bucket rate limiting middleware for httpx handlers.
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gabivlj/httpx"
+)
+
+// KeyFunc returns the key that requests are limited by, like the client IP or an API key
+type KeyFunc func(r *http.Request) string
+
+// Config configures a Limiter
+type Config struct {
+	// Limit is the number of requests allowed per Period, it's also the size of the burst
+	Limit int
+	// Period is the time it takes to refill Limit tokens
+	Period time.Duration
+	// KeyFunc returns the key of the request, the IP of the peer by default
+	KeyFunc KeyFunc
+	// Code is the code rejected requests get, httpx.ErrTooManyRequests by default
+	Code *httpx.ErrorJSONCode
+}
+
+// Limiter is a token bucket rate limiter by key
+type Limiter struct {
+	cfg       Config
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// New returns a Limiter allowing cfg.Limit requests per cfg.Period for each key, it panics if
+// Limit or Period aren't positive
+func New(cfg Config) *Limiter {
+	if cfg.Limit <= 0 || cfg.Period <= 0 {
+		panic("httpx/ratelimit: Config.Limit and Config.Period must be positive")
+	}
+
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = RemoteIP
+	}
+
+	if cfg.Code == nil {
+		cfg.Code = httpx.ErrTooManyRequests
+	}
+
+	return &Limiter{cfg: cfg, buckets: map[string]*bucket{}}
+}
+
+// RemoteIP is the default KeyFunc, it returns the IP of the client trusting the forwarding headers of httpx.TrustedProxies
+func RemoteIP(r *http.Request) string {
+	return httpx.ClientIP(r, httpx.TrustedProxies...)
+}
+
+// Allow takes a token of the bucket of key. It returns whether the request is allowed, the remaining tokens
+// and the time until the bucket is full, or until the next token if it's not allowed.
+func (l *Limiter) Allow(key string) (bool, int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	rate := float64(l.cfg.Limit) / l.cfg.Period.Seconds()
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.cfg.Limit), last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(l.cfg.Limit), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, 0, seconds((1 - b.tokens) / rate)
+	}
+
+	b.tokens--
+	return true, int(b.tokens), seconds((float64(l.cfg.Limit) - b.tokens) / rate)
+}
+
+// sweep forgets the buckets that are full again, at most once per period
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.cfg.Period {
+		return
+	}
+
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= l.cfg.Period {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// Middleware rejects the requests over the limit with the configured code, setting the
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers on every response.
+// Example:
+//
+//	limiter := ratelimit.New(ratelimit.Config{Limit: 100, Period: time.Minute})
+//	mux.GET("/search", search, httpx.WithMiddleware(limiter.Middleware))
+func (l *Limiter) Middleware(next httpx.Handler) httpx.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		allowed, remaining, reset := l.Allow(l.cfg.KeyFunc(r))
+		headers := map[string]string{
+			"RateLimit-Limit":     strconv.Itoa(l.cfg.Limit),
+			"RateLimit-Remaining": strconv.Itoa(remaining),
+			"RateLimit-Reset":     strconv.FormatInt(int64(math.Ceil(reset.Seconds())), 10),
+		}
+
+		if !allowed {
+			return l.cfg.Code.JSON().Headers(headers).RetryAfter(reset)
+		}
+
+		for key, value := range headers {
+			w.Header().Set(key, value)
+		}
+
+		return next(w, r)
+	}
+}
@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabivlj/httpx"
+)
+
+func TestAllow(t *testing.T) {
+	l := New(Config{Limit: 3, Period: time.Minute})
+	for i := 2; i >= 0; i-- {
+		allowed, remaining, reset := l.Allow("a")
+		if !allowed || remaining != i {
+			t.Fatalf("expected the request to be allowed with %d remaining, got %v with %d", i, allowed, remaining)
+		}
+
+		if reset <= 0 || reset > time.Minute {
+			t.Fatalf("expected the reset within the period, got %v", reset)
+		}
+	}
+
+	allowed, remaining, retry := l.Allow("a")
+	if allowed || remaining != 0 || retry <= 0 || retry > 20*time.Second {
+		t.Fatalf("expected the request to be rejected until the next token, got %v, %d, %v", allowed, remaining, retry)
+	}
+
+	if allowed, _, _ := l.Allow("b"); !allowed {
+		t.Fatal("expected other keys to have their own bucket")
+	}
+
+	l.buckets["a"].last = l.buckets["a"].last.Add(-20 * time.Second)
+	if allowed, _, _ := l.Allow("a"); !allowed {
+		t.Fatal("expected a token to be refilled after Period/Limit")
+	}
+}
+
+func TestSweep(t *testing.T) {
+	l := New(Config{Limit: 1, Period: time.Minute})
+	l.Allow("a")
+	l.buckets["a"].last = time.Now().Add(-time.Minute)
+	l.lastSweep = time.Time{}
+	l.Allow("b")
+	if _, ok := l.buckets["a"]; ok {
+		t.Fatal("expected the full bucket to be forgotten")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	l := New(Config{Limit: 1, Period: time.Minute, KeyFunc: func(r *http.Request) string { return r.Header.Get("X-API-Key") }})
+	handler := httpx.H(l.Middleware(func(w http.ResponseWriter, r *http.Request) error {
+		return httpx.Code(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		key       string
+		status    int
+		remaining string
+	}{
+		{key: "a", status: http.StatusNoContent, remaining: "0"},
+		{key: "a", status: http.StatusTooManyRequests, remaining: "0"},
+		{key: "b", status: http.StatusNoContent, remaining: "0"},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-API-Key", tt.key)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != tt.status {
+			t.Fatalf("expected %d for %s, got %d", tt.status, tt.key, w.Code)
+		}
+
+		if w.Header().Get("RateLimit-Limit") != "1" || w.Header().Get("RateLimit-Remaining") != tt.remaining || w.Header().Get("RateLimit-Reset") == "" {
+			t.Fatalf("expected the RateLimit headers, got %v", w.Header())
+		}
+
+		if tt.status == http.StatusTooManyRequests {
+			if w.Header().Get("Retry-After") != "60" || !strings.Contains(w.Body.String(), httpx.ErrTooManyRequests.Code) {
+				t.Fatalf("expected a %s with Retry-After, got %v %s", httpx.ErrTooManyRequests.Code, w.Header(), w.Body.String())
+			}
+		}
+	}
+}
+
+func TestNewRequiresLimitAndPeriod(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"no limit":        {Period: time.Second},
+		"no period":       {Limit: 1},
+		"negative limit":  {Limit: -1, Period: time.Second},
+		"negative period": {Limit: 1, Period: -time.Second},
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected New to panic")
+				}
+			}()
+
+			New(cfg)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package httpx
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const downloadSize = 32 << 20
+
+// discardWriter is a http.ResponseWriter that discards the body, implementing io.ReaderFrom like the writers of net/http
+type discardWriter struct {
+	header http.Header
+}
+
+func (w *discardWriter) Header() http.Header                 { return w.header }
+func (w *discardWriter) WriteHeader(int)                     {}
+func (w *discardWriter) Write(p []byte) (int, error)         { return len(p), nil }
+func (w *discardWriter) ReadFrom(r io.Reader) (int64, error) { return io.Copy(io.Discard, r) }
+
+// onlyReader hides the WriterTo of a reader, so it's copied through the generic path
+type onlyReader struct {
+	io.Reader
+}
+
+func benchmarkDownload(b *testing.B, res func() *Response) {
+	req := httptest.NewRequest(http.MethodGet, "/download", nil)
+	b.SetBytes(downloadSize)
+	b.ReportAllocs()
+	for range b.N {
+		w := &discardWriter{header: http.Header{}}
+		if err := res().Write(w, req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func downloadFile(b *testing.B) string {
+	path := filepath.Join(b.TempDir(), "download")
+	if err := os.WriteFile(path, make([]byte, downloadSize), 0o600); err != nil {
+		b.Fatal(err)
+	}
+
+	return path
+}
+
+func BenchmarkDownloadFile(b *testing.B) {
+	path := downloadFile(b)
+	benchmarkDownload(b, func() *Response {
+		file, err := os.Open(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		return Code(http.StatusOK).ReadCloser(file)
+	})
+}
+
+// BenchmarkDownloadFileServer sends the file through a real connection, where the runtime can use sendfile
+func BenchmarkDownloadFileServer(b *testing.B) {
+	path := downloadFile(b)
+	srv := httptest.NewServer(H(func(w http.ResponseWriter, r *http.Request) error {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+
+		return Code(http.StatusOK).ReadCloser(file)
+	}))
+	defer srv.Close()
+
+	client := srv.Client()
+	b.SetBytes(downloadSize)
+	b.ReportAllocs()
+	for range b.N {
+		res, err := client.Get(srv.URL)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		_, err = io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDownloadWriterTo(b *testing.B) {
+	content := make([]byte, downloadSize)
+	benchmarkDownload(b, func() *Response {
+		return Code(http.StatusOK).Reader(bytes.NewReader(content))
+	})
+}
+
+func BenchmarkDownloadGenericReader(b *testing.B) {
+	content := make([]byte, downloadSize)
+	benchmarkDownload(b, func() *Response {
+		return Code(http.StatusOK).Reader(onlyReader{bytes.NewReader(content)})
+	})
+}
@@ -0,0 +1,46 @@
+package httpx
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// recorder is a http.ResponseWriter that keeps the response in memory
+type recorder struct {
+	header      http.Header
+	code        int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func newRecorder() *recorder {
+	return &recorder{header: http.Header{}, code: http.StatusOK}
+}
+
+func (rec *recorder) Header() http.Header { return rec.header }
+
+func (rec *recorder) Write(p []byte) (int, error) {
+	rec.wroteHeader = true
+	return rec.body.Write(p)
+}
+
+func (rec *recorder) WriteHeader(code int) {
+	if rec.wroteHeader {
+		return
+	}
+
+	rec.wroteHeader = true
+	rec.code = code
+}
+
+// replay writes the recorded response to w
+func (rec *recorder) replay(w http.ResponseWriter) error {
+	header := w.Header()
+	for key, values := range rec.header {
+		header[key] = values
+	}
+
+	w.WriteHeader(rec.code)
+	_, err := w.Write(rec.body.Bytes())
+	return err
+}
@@ -0,0 +1,193 @@
+package httpx
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MaxErrorExtraDepth is how deep the extra payloads of error codes are encoded when they can't be encoded as
+// they are, deeper values are replaced by "[truncated]"
+var MaxErrorExtraDepth = 32
+
+// encodeErrorBody encodes the body of an error code with DefaultJSONEncoder. If its extra payload can't be
+// encoded it's encoded again with a copy of it that can: NaN and infinite floats become strings, cycles are
+// cut at MaxErrorExtraDepth and values JSON can't encode, like channels or failing json.Marshaler, become
+// their description. Error responses must always render.
+func encodeErrorBody(w io.Writer, body map[string]any) error {
+	b := getBuffer()
+	defer putBuffer(b)
+	err := DefaultJSONEncoder.NewEncoder(b).Encode(body)
+	if extra, ok := body["extra"]; err != nil && ok {
+		b.Reset()
+		body = maps.Clone(body)
+		body["extra"] = safeValue(reflect.ValueOf(extra), 0)
+		err = DefaultJSONEncoder.NewEncoder(b).Encode(body)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b.Bytes())
+	return err
+}
+
+func safeValue(v reflect.Value, depth int) any {
+	if !v.IsValid() {
+		return nil
+	}
+
+	if depth > MaxErrorExtraDepth {
+		return "[truncated]"
+	}
+
+	if v.Kind() != reflect.Pointer && v.Kind() != reflect.Interface || !v.IsNil() {
+		if m, ok := v.Interface().(json.Marshaler); ok {
+			data, err := m.MarshalJSON()
+			if err != nil || !json.Valid(data) {
+				return fmt.Sprintf("[%T: unencodable]", m)
+			}
+
+			return json.RawMessage(data)
+		}
+
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+			text, err := m.MarshalText()
+			if err != nil {
+				return fmt.Sprintf("[%T: unencodable]", m)
+			}
+
+			return string(text)
+		}
+
+		if err, ok := v.Interface().(error); ok {
+			return err.Error()
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+
+		return safeValue(v.Elem(), depth+1)
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+
+		return f
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Interface()
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface()
+		}
+
+		items := make([]any, v.Len())
+		for i := range items {
+			items[i] = safeValue(v.Index(i), depth+1)
+		}
+
+		return items
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = safeValue(iter.Value(), depth+1)
+		}
+
+		return m
+	case reflect.Struct:
+		return safeStruct(v, depth)
+	}
+
+	// channels, functions, complex numbers...
+	return fmt.Sprintf("[%s]", v.Type())
+}
+
+// safeStruct encodes the exported fields of a struct following their json tags. Like encoding/json, the fields
+// of embedded structs without a name are promoted, unless two of them have the same name.
+func safeStruct(v reflect.Value, depth int) map[string]any {
+	m := map[string]any{}
+	promoted, conflicts := map[string]any{}, map[string]bool{}
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		value := v.Field(i)
+		if embedded := field.Type; field.Anonymous && name == "" {
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct {
+				if !field.IsExported() && field.Type.Kind() == reflect.Pointer {
+					continue
+				}
+
+				if value.Kind() == reflect.Pointer {
+					if value.IsNil() {
+						continue
+					}
+
+					value = value.Elem()
+				}
+
+				for key, fieldValue := range safeStruct(value, depth+1) {
+					if _, ok := promoted[key]; ok {
+						conflicts[key] = true
+					}
+
+					promoted[key] = fieldValue
+				}
+
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if strings.Contains(opts, "omitempty") && value.IsZero() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		m[name] = safeValue(value, depth+1)
+	}
+
+	for key, value := range promoted {
+		if _, ok := m[key]; !ok && !conflicts[key] {
+			m[key] = value
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,144 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"testing"
+)
+
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("can't")
+}
+
+type countingMarshaler struct {
+	calls *int
+}
+
+func (m countingMarshaler) MarshalJSON() ([]byte, error) {
+	*m.calls++
+	return []byte(`"counted"`), nil
+}
+
+type safeBase struct {
+	ID   int    `json:"id"`
+	Kind string `json:"kind"`
+}
+
+type safeOther struct {
+	Kind string
+	Tags []string `json:"tags,omitempty"`
+}
+
+type safeEmbedding struct {
+	safeBase
+	*safeOther
+	Kind  string `json:"-"`
+	Score float64
+	Named safeBase `json:"named"`
+}
+
+type safeNode struct {
+	Next *safeNode `json:"next"`
+}
+
+func TestErrorJSONCodeUnencodableExtra(t *testing.T) {
+	cycle := &safeNode{}
+	cycle.Next = cycle
+
+	tests := []struct {
+		name  string
+		extra any
+		want  string
+	}{
+		{name: "encodable", extra: map[string]int{"a": 1}, want: `{"a":1}`},
+		{name: "NaN", extra: math.NaN(), want: `"NaN"`},
+		{name: "infinite in a map", extra: map[string]float64{"a": math.Inf(1)}, want: `{"a":"+Inf"}`},
+		{name: "channel", extra: []any{1, make(chan int)}, want: `[1,"[chan int]"]`},
+		{name: "failing marshaler", extra: map[string]any{"a": failingMarshaler{}}, want: `{"a":"[httpx.failingMarshaler: unencodable]"}`},
+		{name: "error", extra: []any{errors.New("boom"), func() {}}, want: `["boom","[func()]"]`},
+		{name: "embedded structs are flattened", extra: safeEmbedding{
+			safeBase:  safeBase{ID: 1, Kind: "base"},
+			safeOther: &safeOther{Kind: "other"},
+			Kind:      "hidden",
+			Score:     math.NaN(),
+			Named:     safeBase{ID: 2},
+		}, want: `{"Score":"NaN","id":1,"kind":"base","named":{"id":2,"kind":""}}`},
+		{name: "nil embedded pointer", extra: safeEmbedding{Score: math.Inf(-1)}, want: `{"Score":"-Inf","id":0,"kind":"","named":{"id":0,"kind":""}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := ErrBadRequest.JSON(tt.extra).Body()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got struct {
+				Code  string          `json:"code"`
+				Extra json.RawMessage `json:"extra"`
+			}
+
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("expected valid JSON, got %s", body)
+			}
+
+			if got.Code != ErrBadRequest.Code || string(got.Extra) != tt.want {
+				t.Fatalf("expected extra %s, got %s", tt.want, body)
+			}
+		})
+	}
+
+	t.Run("cycle", func(t *testing.T) {
+		body, err := ErrBadRequest.JSON(cycle).Body()
+		if err != nil || !json.Valid(body) {
+			t.Fatalf("expected a valid body, got %s, %v", body, err)
+		}
+	})
+}
+
+func TestErrorJSONCodeEncodesExtraOnce(t *testing.T) {
+	calls := 0
+	res := ErrBadRequest.JSON(countingMarshaler{calls: &calls})
+	if calls != 0 {
+		t.Fatalf("expected the extra not to be encoded before rendering, it was encoded %d times", calls)
+	}
+
+	for range 2 {
+		if _, err := res.Body(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected the extra to be encoded once, it was encoded %d times", calls)
+	}
+}
+
+type recordingEncoder struct {
+	StdJSONEncoder
+	encoded *int
+}
+
+func (e recordingEncoder) NewEncoder(w io.Writer) JSONStreamEncoder {
+	*e.encoded++
+	return e.StdJSONEncoder.NewEncoder(w)
+}
+
+func TestErrorJSONCodeUsesDefaultJSONEncoder(t *testing.T) {
+	encoded := 0
+	defer func(enc JSONEncoder) { DefaultJSONEncoder = enc }(DefaultJSONEncoder)
+	DefaultJSONEncoder = recordingEncoder{StdJSONEncoder: StdJSONEncoder{NoTrailingNewline: true}, encoded: &encoded}
+
+	body, err := ErrBadRequest.JSON(math.NaN()).Body()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if encoded != 2 || string(body) != `{"code":"BAD_REQUEST","extra":"NaN"}` {
+		t.Fatalf("expected the default encoder to encode the body and its fallback, got %d encodings of %s", encoded, body)
+	}
+}
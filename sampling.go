@@ -0,0 +1,99 @@
+package httpx
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrorSampling configures SampleErrors
+type ErrorSampling struct {
+	// Rate is the fraction of the 5xx errors that reach the hook, between 0 and 1. 0 doesn't sample them.
+	Rate float64
+	// Window makes identical 5xx errors, the ones with the same code and route, reach the hook once per
+	// Window. 0 doesn't deduplicate them.
+	Window time.Duration
+}
+
+// SampleErrors wraps an after hook so bursts of 5xx errors don't flood it, like the ones that log or alert.
+// Other responses always reach it. The dropped errors are counted in the Suppressed field of the next error
+// with the same code and route that reaches it. Hooks that count totals, like metrics, shouldn't be wrapped.
+// Example:
+//
+//	httpx.OnAfter(metrics, httpx.SampleErrors(httpx.ErrorSampling{Rate: 0.1, Window: time.Minute}, logErrors))
+func SampleErrors(s ErrorSampling, fn AfterFunc) AfterFunc {
+	sampler := &errorSampler{ErrorSampling: s, seen: map[string]*sampledError{}}
+	return func(w http.ResponseWriter, r *http.Request, info AfterInfo) {
+		if info.Status < http.StatusInternalServerError {
+			fn(w, r, info)
+			return
+		}
+
+		suppressed, ok := sampler.allow(errorKey(r, info), time.Now())
+		if !ok {
+			return
+		}
+
+		info.Suppressed = suppressed
+		fn(w, r, info)
+	}
+}
+
+type sampledError struct {
+	until      time.Time
+	suppressed int64
+}
+
+type errorSampler struct {
+	ErrorSampling
+	mu        sync.Mutex
+	seen      map[string]*sampledError
+	lastSweep time.Time
+}
+
+// allow returns whether the error with key can reach the hook and how many were dropped before it
+func (s *errorSampler) allow(key string, now time.Time) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+	entry, ok := s.seen[key]
+	if !ok {
+		entry = &sampledError{}
+		s.seen[key] = entry
+	}
+
+	if now.Before(entry.until) || (s.Rate > 0 && rand.Float64() >= s.Rate) {
+		entry.suppressed++
+		return 0, false
+	}
+
+	suppressed := entry.suppressed
+	entry.suppressed, entry.until = 0, now.Add(s.Window)
+	return suppressed, true
+}
+
+// sweep forgets the keys that didn't have errors during the last minute
+func (s *errorSampler) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+
+	s.lastSweep = now
+	for key, entry := range s.seen {
+		if entry.suppressed == 0 && now.Sub(entry.until) > time.Minute {
+			delete(s.seen, key)
+		}
+	}
+}
+
+// errorKey identifies identical errors by their code and route
+func errorKey(r *http.Request, info AfterInfo) string {
+	code := strconv.Itoa(info.Status)
+	if info.Response != nil && info.Response.ErrorCode() != "" {
+		code = info.Response.ErrorCode()
+	}
+
+	return code + " " + r.Method + " " + Route(r)
+}
@@ -0,0 +1,92 @@
+package httpx
+
+import (
+	"net/http"
+)
+
+// SecureHeaders are the security headers set by the Secure middleware. Empty fields are left
+// unset by WithSecureHeaders, and "-" removes the header.
+type SecureHeaders struct {
+	// StrictTransportSecurity is the HSTS header, "max-age=63072000; includeSubDomains" by default
+	StrictTransportSecurity string
+	// ContentTypeOptions is the X-Content-Type-Options header, "nosniff" by default
+	ContentTypeOptions string
+	// FrameOptions is the X-Frame-Options header, "DENY" by default
+	FrameOptions string
+	// ReferrerPolicy is the Referrer-Policy header, "strict-origin-when-cross-origin" by default
+	ReferrerPolicy string
+	// ContentSecurityPolicy is the Content-Security-Policy header, it's not set by default
+	ContentSecurityPolicy string
+}
+
+// DefaultSecureHeaders are the headers set by the Secure middleware for the fields left empty
+var DefaultSecureHeaders = SecureHeaders{
+	StrictTransportSecurity: "max-age=63072000; includeSubDomains",
+	ContentTypeOptions:      "nosniff",
+	FrameOptions:            "DENY",
+	ReferrerPolicy:          "strict-origin-when-cross-origin",
+}
+
+// Secure returns a middleware that sets the security headers, using DefaultSecureHeaders for the
+// empty fields of h. Headers set by the handler or its response take priority.
+// Example:
+//
+//	mux := httpx.NewMux(httpx.WithMiddleware(httpx.Secure(httpx.SecureHeaders{
+//		ContentSecurityPolicy: "default-src 'self'",
+//	})))
+func Secure(h SecureHeaders) Middleware {
+	h = h.merge(DefaultSecureHeaders)
+	return h.middleware
+}
+
+// WithSecureHeaders overrides the security headers of a group or route, the fields left empty keep
+// the value of the outer Secure middleware and "-" removes the header.
+// Example:
+//
+//	mux.GET("/embed", embedHandler, httpx.WithSecureHeaders(httpx.SecureHeaders{FrameOptions: "-"}))
+func WithSecureHeaders(h SecureHeaders) Option {
+	return WithMiddleware(h.middleware)
+}
+
+// merge returns h with the empty fields set to the ones of defaults
+func (h SecureHeaders) merge(defaults SecureHeaders) SecureHeaders {
+	or := func(v, fallback string) string {
+		if v == "" {
+			return fallback
+		}
+
+		return v
+	}
+
+	return SecureHeaders{
+		StrictTransportSecurity: or(h.StrictTransportSecurity, defaults.StrictTransportSecurity),
+		ContentTypeOptions:      or(h.ContentTypeOptions, defaults.ContentTypeOptions),
+		FrameOptions:            or(h.FrameOptions, defaults.FrameOptions),
+		ReferrerPolicy:          or(h.ReferrerPolicy, defaults.ReferrerPolicy),
+		ContentSecurityPolicy:   or(h.ContentSecurityPolicy, defaults.ContentSecurityPolicy),
+	}
+}
+
+func (h SecureHeaders) middleware(next Handler) Handler {
+	headers := map[string]string{
+		"Strict-Transport-Security": h.StrictTransportSecurity,
+		"X-Content-Type-Options":    h.ContentTypeOptions,
+		"X-Frame-Options":           h.FrameOptions,
+		"Referrer-Policy":           h.ReferrerPolicy,
+		"Content-Security-Policy":   h.ContentSecurityPolicy,
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		for key, value := range headers {
+			switch value {
+			case "":
+			case "-":
+				w.Header().Del(key)
+			default:
+				w.Header().Set(key, value)
+			}
+		}
+
+		return next(w, r)
+	}
+}
@@ -0,0 +1,102 @@
+package httpx
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ErrShuttingDown is the code returned to the requests that arrive while a Server is shutting down
+var ErrShuttingDown = NewCode("SHUTTING_DOWN", http.StatusServiceUnavailable)
+
+// Server wraps a http.Server draining it on Shutdown: the requests in flight are left to finish, and
+// the requests that arrive in the meantime through open connections get a 503 Code with Retry-After
+// and the connection is closed. If the shutdown context expires, the contexts of the requests in flight
+// are canceled so they stop.
+type Server struct {
+	*http.Server
+	// Code is the code returned while draining, ErrShuttingDown by default
+	Code *ErrorJSONCode
+	// RetryAfter is sent in the Retry-After header while draining, it's not sent if it's 0
+	RetryAfter time.Duration
+
+	draining atomic.Bool
+	cancel   context.CancelFunc
+}
+
+// NewServer returns a Server wrapping srv, its Handler and BaseContext are wrapped to drain it.
+// Example:
+//
+//	srv := httpx.NewServer(&http.Server{Addr: ":8080", Handler: mux})
+//	srv.RetryAfter = 5 * time.Second
+//	go srv.ListenAndServe()
+//
+//	<-ctx.Done() // SIGTERM
+//	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	srv.Shutdown(shutdownCtx)
+func NewServer(srv *http.Server) *Server {
+	s := &Server{Server: srv, Code: ErrShuttingDown}
+	base, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	baseContext := srv.BaseContext
+	srv.BaseContext = func(l net.Listener) context.Context {
+		if baseContext == nil {
+			return base
+		}
+
+		ctx, stop := context.WithCancel(baseContext(l))
+		context.AfterFunc(base, stop)
+		return ctx
+	}
+
+	handler := srv.Handler
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+
+	srv.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.draining.Load() {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Connection", "close")
+		res := s.Code.JSON()
+		if s.RetryAfter > 0 {
+			res = res.RetryAfter(s.RetryAfter)
+		}
+
+		res.ServeHTTP(w, withValues(r))
+	})
+
+	return s
+}
+
+// Draining returns true once Shutdown was called, use it to fail readiness checks
+// so load balancers stop sending requests.
+func (s *Server) Draining() bool {
+	return s.draining.Load()
+}
+
+// Shutdown starts draining the server and shuts it down gracefully. If ctx expires before the requests
+// in flight finish, their contexts are canceled and the error of ctx is returned.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.draining.Store(true)
+	err := s.Server.Shutdown(ctx)
+	if err != nil {
+		s.cancel()
+	}
+
+	return err
+}
+
+// Close closes the server immediately, canceling the contexts of the requests in flight
+func (s *Server) Close() error {
+	s.draining.Store(true)
+	s.cancel()
+	return s.Server.Close()
+}
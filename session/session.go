@@ -0,0 +1,389 @@
+// package session has cookie based sessions for httpx handlers, with the values kept in the encrypted
+// cookie or in a server side Store like memory or Redis.
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gabivlj/httpx"
+)
+
+// Codes returned when sessions can't be loaded or saved
+var (
+	ErrStoreUnavailable = httpx.NewCode("SESSION_STORE_UNAVAILABLE", http.StatusServiceUnavailable)
+	ErrTooLarge         = httpx.NewCode("SESSION_TOO_LARGE", http.StatusInternalServerError)
+	ErrRequired         = httpx.NewCode("SESSION_REQUIRED", http.StatusUnauthorized)
+)
+
+// sessionKey is the request value key of the session
+const sessionKey = "session.session"
+
+// maxCookieSize is the size browsers limit cookies to
+const maxCookieSize = 4096
+
+// Config configures the sessions
+type Config struct {
+	// Keys encrypt and authenticate the cookies with AES-GCM, they must be 16, 24 or 32 bytes long.
+	// The first one encrypts new cookies, the rest are only used to decrypt them so keys can be rotated.
+	Keys [][]byte
+	// Store keeps the values of the sessions, they're kept in the cookie if it's nil
+	Store Store
+	// Cookie is the template of the session cookie. The name is "session", the path "/" and SameSite
+	// Lax by default, and it's always HttpOnly.
+	Cookie http.Cookie
+	// TTL is how long sessions last since they were last saved, 24 hours by default
+	TTL time.Duration
+}
+
+// Manager loads and saves the sessions of the requests
+type Manager struct {
+	cfg   Config
+	aeads []cipher.AEAD
+}
+
+// New returns a Manager for the config, it panics if a key isn't a valid AES key.
+func New(cfg Config) *Manager {
+	if len(cfg.Keys) == 0 {
+		panic("httpx/session: at least one key is required")
+	}
+
+	if cfg.Cookie.Name == "" {
+		cfg.Cookie.Name = "session"
+	}
+
+	if cfg.Cookie.Path == "" {
+		cfg.Cookie.Path = "/"
+	}
+
+	if cfg.Cookie.SameSite == 0 {
+		cfg.Cookie.SameSite = http.SameSiteLaxMode
+	}
+
+	cfg.Cookie.HttpOnly = true
+	if cfg.TTL <= 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+
+	m := &Manager{cfg: cfg}
+	for _, key := range cfg.Keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			panic(fmt.Sprintf("httpx/session: invalid key: %v", err))
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			panic(fmt.Sprintf("httpx/session: invalid key: %v", err))
+		}
+
+		m.aeads = append(m.aeads, aead)
+	}
+
+	return m
+}
+
+// Session is the session of a request, it's safe to use from multiple goroutines
+type Session struct {
+	mu        sync.Mutex
+	id        string
+	previous  string
+	values    map[string]any
+	isNew     bool
+	modified  bool
+	destroyed bool
+}
+
+// ID returns the id of the session, it's empty from a Set after Destroy until the new session is saved
+func (s *Session) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.id
+}
+
+// IsNew returns true if the client didn't have a session
+func (s *Session) IsNew() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.isNew
+}
+
+// Get returns the value of key, values kept in the cookie or in stores encoding them with JSON come
+// back with JSON types, like float64 for numbers.
+func (s *Session) Get(key string) (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// Set sets the value of key. After Destroy it starts a new session, with a new id once it's saved.
+func (s *Session) Set(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.destroyed {
+		if s.previous == "" && !s.isNew {
+			s.previous = s.id
+		}
+
+		s.id, s.destroyed = "", false
+	}
+
+	s.values[key] = value
+	s.modified = true
+}
+
+// Delete deletes key
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	s.modified = true
+}
+
+// Renew changes the id of the session keeping its values, call it when the user logs in to prevent session fixation
+func (s *Session) Renew() error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.previous == "" && !s.isNew {
+		s.previous = s.id
+	}
+
+	s.id = id
+	s.modified = true
+	return nil
+}
+
+// Destroy deletes the session and its cookie, unless values are set again with Set
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = map[string]any{}
+	s.destroyed = true
+}
+
+// Get returns the session of the request, or nil if it didn't go through Manager.Middleware
+func Get(r *http.Request) *Session {
+	s, _ := httpx.Get[*Session](r, sessionKey)
+	return s
+}
+
+// Require returns a middleware rejecting with ErrRequired the requests whose session doesn't have key, like the
+// id of the logged in user. It has to run after Manager.Middleware.
+// Example:
+//
+//	mux.Group("/account", httpx.WithMiddleware(sessions.Middleware, session.Require("user_id")))
+func Require(key string) httpx.Middleware {
+	return func(next httpx.Handler) httpx.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			s := Get(r)
+			if s == nil {
+				return ErrRequired.JSON()
+			}
+
+			if _, ok := s.Get(key); !ok {
+				return ErrRequired.JSON()
+			}
+
+			return next(w, r)
+		}
+	}
+}
+
+// Middleware loads the session of the request, available with Get, and saves it after the handler returns
+// if it was modified. The session is saved before the response is written, so handlers have to return their
+// response instead of writing it to the http.ResponseWriter for the cookie to be set.
+// Example:
+//
+//	sessions := session.New(session.Config{Keys: [][]byte{key}, Store: session.NewMemoryStore()})
+//	mux := httpx.NewMux(httpx.WithMiddleware(sessions.Middleware))
+//	mux.POST("/login", func(w http.ResponseWriter, r *http.Request) error {
+//		s := session.Get(r)
+//		if err := s.Renew(); err != nil {
+//			return err
+//		}
+//
+//		s.Set("user_id", user.ID)
+//		return httpx.NoContent()
+//	})
+func (m *Manager) Middleware(next httpx.Handler) httpx.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		s, err := m.load(r)
+		if err != nil {
+			return err
+		}
+
+		r = httpx.Set(r, sessionKey, s)
+		handlerErr := next(w, r)
+		if err := m.save(w, r, s); err != nil {
+			return err
+		}
+
+		return handlerErr
+	}
+}
+
+// cookiePayload is the encrypted content of the cookie
+type cookiePayload struct {
+	ID      string         `json:"id"`
+	Expires int64          `json:"exp"`
+	Values  map[string]any `json:"v,omitempty"`
+}
+
+func (m *Manager) load(r *http.Request) (*Session, error) {
+	if cookie, err := r.Cookie(m.cfg.Cookie.Name); err == nil {
+		if payload, ok := m.decode(cookie.Value); ok && time.Now().Unix() < payload.Expires {
+			values := payload.Values
+			if m.cfg.Store != nil {
+				var found bool
+				values, found, err = m.cfg.Store.Load(r.Context(), payload.ID)
+				if err != nil {
+					return nil, ErrStoreUnavailable.JSON()
+				}
+
+				if !found {
+					return m.newSession()
+				}
+			}
+
+			if values == nil {
+				values = map[string]any{}
+			}
+
+			return &Session{id: payload.ID, values: values}, nil
+		}
+	}
+
+	return m.newSession()
+}
+
+func (m *Manager) newSession() (*Session, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{id: id, values: map[string]any{}, isNew: true}, nil
+}
+
+func (m *Manager) save(w http.ResponseWriter, r *http.Request, s *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.modified && !s.destroyed {
+		return nil
+	}
+
+	cookie := m.cfg.Cookie
+	if s.destroyed {
+		if m.cfg.Store != nil {
+			if err := errors.Join(m.cfg.Store.Delete(r.Context(), s.id), m.deletePrevious(r, s)); err != nil {
+				return ErrStoreUnavailable.JSON()
+			}
+		}
+
+		cookie.MaxAge = -1
+		http.SetCookie(w, &cookie)
+		return nil
+	}
+
+	if s.id == "" {
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+
+		s.id = id
+	}
+
+	payload := cookiePayload{ID: s.id, Expires: time.Now().Add(m.cfg.TTL).Unix()}
+	if m.cfg.Store == nil {
+		payload.Values = maps.Clone(s.values)
+	} else if err := errors.Join(m.cfg.Store.Save(r.Context(), s.id, s.values, m.cfg.TTL), m.deletePrevious(r, s)); err != nil {
+		return ErrStoreUnavailable.JSON()
+	}
+
+	value, err := m.encode(payload)
+	if err != nil {
+		return err
+	}
+
+	if len(value) > maxCookieSize {
+		return ErrTooLarge.JSON()
+	}
+
+	cookie.Value = value
+	cookie.MaxAge = int(m.cfg.TTL / time.Second)
+	http.SetCookie(w, &cookie)
+	return nil
+}
+
+// deletePrevious deletes the session the current one was renewed from
+func (m *Manager) deletePrevious(r *http.Request, s *Session) error {
+	if s.previous == "" {
+		return nil
+	}
+
+	return m.cfg.Store.Delete(r.Context(), s.previous)
+}
+
+func (m *Manager) encode(payload cookiePayload) (string, error) {
+	plaintext, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	aead := m.aeads[0]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, []byte(m.cfg.Cookie.Name))), nil
+}
+
+func (m *Manager) decode(value string) (cookiePayload, bool) {
+	var payload cookiePayload
+	ciphertext, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return payload, false
+	}
+
+	for _, aead := range m.aeads {
+		if len(ciphertext) < aead.NonceSize() {
+			continue
+		}
+
+		nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+		plaintext, err := aead.Open(nil, nonce, sealed, []byte(m.cfg.Cookie.Name))
+		if err != nil {
+			continue
+		}
+
+		return payload, json.Unmarshal(plaintext, &payload) == nil
+	}
+
+	return payload, false
+}
+
+func newID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
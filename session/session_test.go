@@ -0,0 +1,288 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabivlj/httpx"
+)
+
+var (
+	key    = bytes.Repeat([]byte{1}, 32)
+	oldKey = bytes.Repeat([]byte{2}, 16)
+)
+
+// client keeps the session cookie between requests like a browser
+type client struct {
+	t       *testing.T
+	handler http.Handler
+	cookie  *http.Cookie
+}
+
+func newClient(t *testing.T, m *Manager, handler httpx.Handler) *client {
+	return &client{t: t, handler: httpx.H(m.Middleware(handler))}
+}
+
+func (c *client) do() *httptest.ResponseRecorder {
+	c.t.Helper()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if c.cookie != nil {
+		r.AddCookie(c.cookie)
+	}
+
+	w := httptest.NewRecorder()
+	c.handler.ServeHTTP(w, r)
+	for _, cookie := range w.Result().Cookies() {
+		c.cookie = cookie
+		if cookie.MaxAge < 0 {
+			c.cookie = nil
+		}
+	}
+
+	return w
+}
+
+// handler runs fn with the session of each request and answers with 204
+func handler(fn func(s *Session)) httpx.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		fn(Get(r))
+		return httpx.Code(http.StatusNoContent)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for name, store := range map[string]Store{"cookie": nil, "memory": NewMemoryStore()} {
+		t.Run(name, func(t *testing.T) {
+			m := New(Config{Keys: [][]byte{key}, Store: store})
+			var got *Session
+			c := newClient(t, m, handler(func(s *Session) {
+				got = s
+				if s.IsNew() {
+					s.Set("user_id", 42)
+				}
+			}))
+
+			c.do()
+			if c.cookie == nil || !c.cookie.HttpOnly || c.cookie.Path != "/" || c.cookie.SameSite != http.SameSiteLaxMode {
+				t.Fatalf("expected an HttpOnly session cookie, got %+v", c.cookie)
+			}
+
+			id := got.ID()
+			c.do()
+			if got.IsNew() || got.ID() != id {
+				t.Fatal("expected the session to be loaded from the cookie")
+			}
+
+			if v, ok := got.Get("user_id"); !ok || v != float64(42) && v != 42 {
+				t.Fatalf("expected user_id 42, got %v", v)
+			}
+
+			if store == nil && strings.Contains(c.cookie.Value, id) {
+				t.Fatal("expected the cookie to be encrypted")
+			}
+		})
+	}
+}
+
+func TestUnmodifiedSessionIsNotSaved(t *testing.T) {
+	m := New(Config{Keys: [][]byte{key}})
+	c := newClient(t, m, handler(func(s *Session) {}))
+	if c.do(); c.cookie != nil {
+		t.Fatal("expected no cookie for a session that wasn't modified")
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	var got *Session
+	setOld := handler(func(s *Session) { s.Set("a", "b") })
+	c := newClient(t, New(Config{Keys: [][]byte{oldKey}}), setOld)
+	c.do()
+	oldCookie := c.cookie
+
+	read := handler(func(s *Session) { got = s })
+	c.handler = httpx.H(New(Config{Keys: [][]byte{key, oldKey}}).Middleware(read))
+	c.do()
+	if got.IsNew() {
+		t.Fatal("expected the cookie of the previous key to be accepted")
+	}
+
+	c.handler = httpx.H(New(Config{Keys: [][]byte{key}}).Middleware(read))
+	c.cookie = oldCookie
+	c.do()
+	if !got.IsNew() {
+		t.Fatal("expected the cookie of a dropped key to be rejected")
+	}
+}
+
+func TestTamperedCookie(t *testing.T) {
+	m := New(Config{Keys: [][]byte{key}})
+	var got *Session
+	c := newClient(t, m, handler(func(s *Session) {
+		got = s
+		s.Set("admin", false)
+	}))
+
+	c.do()
+	value := []byte(c.cookie.Value)
+	value[len(value)/2] ^= 1
+	c.cookie.Value = string(value)
+	c.do()
+	if !got.IsNew() {
+		t.Fatal("expected a tampered cookie to start a new session")
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	m := New(Config{Keys: [][]byte{key}})
+	value, err := m.encode(cookiePayload{ID: "expired", Expires: time.Now().Add(-time.Second).Unix(), Values: map[string]any{"a": "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got *Session
+	c := newClient(t, m, handler(func(s *Session) { got = s }))
+	c.cookie = &http.Cookie{Name: "session", Value: value}
+	c.do()
+	if !got.IsNew() || got.ID() == "expired" {
+		t.Fatal("expected an expired cookie to start a new session")
+	}
+
+	store := NewMemoryStore()
+	m = New(Config{Keys: [][]byte{key}, Store: store, TTL: time.Hour})
+	c = newClient(t, m, handler(func(s *Session) {
+		got = s
+		s.Set("a", "b")
+	}))
+
+	c.do()
+	store.sessions[got.ID()] = memorySession{values: map[string]any{"a": "b"}, expires: time.Now().Add(-time.Second)}
+	c.do()
+	if !got.IsNew() {
+		t.Fatal("expected a session expired in the store to start a new session")
+	}
+}
+
+func TestRenewDeletesPrevious(t *testing.T) {
+	store := NewMemoryStore()
+	m := New(Config{Keys: [][]byte{key}, Store: store})
+	var got *Session
+	renew := false
+	c := newClient(t, m, handler(func(s *Session) {
+		got = s
+		if renew {
+			if err := s.Renew(); err != nil {
+				t.Fatal(err)
+			}
+
+			return
+		}
+
+		s.Set("cart", "1")
+	}))
+
+	c.do()
+	previous := got.ID()
+	renew = true
+	c.do()
+	if got.ID() == previous {
+		t.Fatal("expected Renew to change the id")
+	}
+
+	if _, found, _ := store.Load(context.Background(), previous); found {
+		t.Fatal("expected the previous session to be deleted from the store")
+	}
+
+	if values, found, _ := store.Load(context.Background(), got.ID()); !found || values["cart"] != "1" {
+		t.Fatalf("expected the values to be kept in the renewed session, got %v", values)
+	}
+}
+
+func TestDestroy(t *testing.T) {
+	store := NewMemoryStore()
+	m := New(Config{Keys: [][]byte{key}, Store: store})
+	var got *Session
+	action := "login"
+	c := newClient(t, m, handler(func(s *Session) {
+		got = s
+		switch action {
+		case "login":
+			s.Set("user_id", "1")
+		case "logout":
+			s.Destroy()
+		case "logout with flash":
+			s.Destroy()
+			s.Set("flash", "bye")
+		}
+	}))
+
+	c.do()
+	id := got.ID()
+	action = "logout"
+	w := c.do()
+	if c.cookie != nil || !strings.Contains(w.Header().Get("Set-Cookie"), "Max-Age=0") {
+		t.Fatalf("expected the cookie to be deleted, got %q", w.Header().Get("Set-Cookie"))
+	}
+
+	if _, found, _ := store.Load(context.Background(), id); found {
+		t.Fatal("expected the session to be deleted from the store")
+	}
+
+	action = "login"
+	c.do()
+	id = got.ID()
+	action = "logout with flash"
+	c.do()
+	if got.ID() == id || c.cookie == nil {
+		t.Fatal("expected Set after Destroy to start a new session")
+	}
+
+	if _, found, _ := store.Load(context.Background(), id); found {
+		t.Fatal("expected the destroyed session to be deleted from the store")
+	}
+
+	if values, _, _ := store.Load(context.Background(), got.ID()); values["flash"] != "bye" || values["user_id"] != nil {
+		t.Fatalf("expected only the values set after Destroy, got %v", values)
+	}
+}
+
+func TestTooLarge(t *testing.T) {
+	m := New(Config{Keys: [][]byte{key}})
+	c := newClient(t, m, handler(func(s *Session) { s.Set("big", strings.Repeat("x", maxCookieSize)) }))
+	w := c.do()
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), ErrTooLarge.Code) {
+		t.Fatalf("expected a 500 %s, got %d %s", ErrTooLarge.Code, w.Code, w.Body.String())
+	}
+
+	if c.cookie != nil {
+		t.Fatal("expected no cookie")
+	}
+}
+
+func TestRequire(t *testing.T) {
+	m := New(Config{Keys: [][]byte{key}})
+	h := httpx.H(m.Middleware(Require("user_id")(handler(func(s *Session) {}))))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected a 401, got %d", w.Code)
+	}
+}
+
+func TestNewPanicsWithInvalidKeys(t *testing.T) {
+	for name, keys := range map[string][][]byte{"no keys": nil, "short key": {[]byte("short")}} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected New to panic")
+				}
+			}()
+
+			New(Config{Keys: keys})
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"sync"
+	"time"
+)
+
+// Store keeps the values of the sessions server side, so only their id is kept in the cookie
+type Store interface {
+	// Load returns the values of the session id, found is false if it doesn't exist or it expired
+	Load(ctx context.Context, id string) (values map[string]any, found bool, err error)
+	// Save saves the values of the session id for ttl
+	Save(ctx context.Context, id string, values map[string]any, ttl time.Duration) error
+	// Delete deletes the session id
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryStore keeps the sessions in memory, they're lost when the process restarts
+type MemoryStore struct {
+	mu        sync.Mutex
+	sessions  map[string]memorySession
+	lastSweep time.Time
+}
+
+type memorySession struct {
+	values  map[string]any
+	expires time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: map[string]memorySession{}}
+}
+
+func (s *MemoryStore) Load(ctx context.Context, id string) (map[string]any, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	if !ok || time.Now().After(session.expires) {
+		return nil, false, nil
+	}
+
+	return maps.Clone(session.values), true, nil
+}
+
+func (s *MemoryStore) Save(ctx context.Context, id string, values map[string]any, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.sweep(now)
+	s.sessions[id] = memorySession{values: maps.Clone(values), expires: now.Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
+
+// sweep deletes the expired sessions at most once a minute
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+
+	s.lastSweep = now
+	for id, session := range s.sessions {
+		if now.After(session.expires) {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+// RedisClient is the subset of a Redis client RedisStore needs, adapt the client of your Redis library to it.
+type RedisClient interface {
+	// Get returns the value of key, or nil without error if it doesn't exist
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set sets the value of key expiring after ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Del deletes key
+	Del(ctx context.Context, key string) error
+}
+
+// RedisStore keeps the sessions in Redis encoded as JSON
+type RedisStore struct {
+	Client RedisClient
+	// Prefix is prepended to the session ids to build the keys, like "session:"
+	Prefix string
+}
+
+func (s *RedisStore) Load(ctx context.Context, id string) (map[string]any, bool, error) {
+	b, err := s.Client.Get(ctx, s.Prefix+id)
+	if err != nil || b == nil {
+		return nil, false, err
+	}
+
+	values := map[string]any{}
+	if err := json.Unmarshal(b, &values); err != nil {
+		return nil, false, err
+	}
+
+	return values, true, nil
+}
+
+func (s *RedisStore) Save(ctx context.Context, id string, values map[string]any, ttl time.Duration) error {
+	b, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	return s.Client.Set(ctx, s.Prefix+id, b, ttl)
+}
+
+func (s *RedisStore) Delete(ctx context.Context, id string) error {
+	return s.Client.Del(ctx, s.Prefix+id)
+}
@@ -0,0 +1,155 @@
+// package sign has signed URLs for httpx handlers, like download links that expire.
+package sign
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gabivlj/httpx"
+)
+
+// Codes returned by the middleware
+var (
+	// ErrInvalidSignature is returned when the signature is missing or doesn't match the request
+	ErrInvalidSignature = httpx.NewCode("INVALID_SIGNATURE", http.StatusUnauthorized)
+	// ErrExpiredSignature is returned when the signature is valid but the URL expired
+	ErrExpiredSignature = httpx.NewCode("SIGNATURE_EXPIRED", http.StatusGone)
+)
+
+// Config configures a Signer
+type Config struct {
+	// Key is the HMAC-SHA256 key URLs are signed with
+	Key []byte
+	// PreviousKeys are still accepted by the middleware, so keys can be rotated without breaking the URLs
+	// already given out
+	PreviousKeys [][]byte
+	// SignatureParam is the query parameter with the signature, "signature" by default
+	SignatureParam string
+	// ExpiresParam is the query parameter with the expiry as a unix timestamp, "expires" by default
+	ExpiresParam string
+	// Now returns the current time, time.Now by default
+	Now func() time.Time
+}
+
+// Signer signs URLs and verifies the requests to them. The signature covers the method, the path and
+// the expiry, other query parameters can be changed by the client.
+type Signer struct {
+	cfg Config
+}
+
+// New returns a Signer for the config, it panics if Key or one of PreviousKeys is empty because anyone could
+// forge their signatures.
+func New(cfg Config) *Signer {
+	if len(cfg.Key) == 0 {
+		panic("httpx/sign: Config.Key is required")
+	}
+
+	for _, key := range cfg.PreviousKeys {
+		if len(key) == 0 {
+			panic("httpx/sign: Config.PreviousKeys can't have empty keys")
+		}
+	}
+
+	if cfg.SignatureParam == "" {
+		cfg.SignatureParam = "signature"
+	}
+
+	if cfg.ExpiresParam == "" {
+		cfg.ExpiresParam = "expires"
+	}
+
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	return &Signer{cfg: cfg}
+}
+
+// Sign returns a copy of u that can be requested with method until expires.
+// Example:
+//
+//	signer := sign.New(sign.Config{Key: key})
+//	link := signer.Sign(http.MethodGet, &url.URL{Path: "/downloads/report.pdf"}, time.Now().Add(time.Hour))
+func (s *Signer) Sign(method string, u *url.URL, expires time.Time) *url.URL {
+	signed := *u
+	expiry := strconv.FormatInt(expires.Unix(), 10)
+	query := signed.Query()
+	query.Set(s.cfg.ExpiresParam, expiry)
+	query.Set(s.cfg.SignatureParam, signature(s.cfg.Key, method, signed.EscapedPath(), expiry))
+	signed.RawQuery = query.Encode()
+	return &signed
+}
+
+// Verify returns nil if r was signed by the Signer and didn't expire, a 401 ErrInvalidSignature or
+// a 410 ErrExpiredSignature otherwise. HEAD requests are accepted with the signature of GET.
+func (s *Signer) Verify(r *http.Request) error {
+	query := r.URL.Query()
+	if len(query[s.cfg.ExpiresParam]) != 1 || len(query[s.cfg.SignatureParam]) != 1 {
+		return ErrInvalidSignature.JSON()
+	}
+
+	expiry, sig := query.Get(s.cfg.ExpiresParam), query.Get(s.cfg.SignatureParam)
+	expires, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature.JSON()
+	}
+
+	method := r.Method
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+
+	if !s.valid(sig, method, r.URL.EscapedPath(), expiry) {
+		return ErrInvalidSignature.JSON()
+	}
+
+	if !s.cfg.Now().Before(time.Unix(expires, 0)) {
+		return ErrExpiredSignature.JSON()
+	}
+
+	return nil
+}
+
+// Middleware rejects the requests that Verify doesn't accept.
+// Example:
+//
+//	mux.GET("/downloads/{file}", download, httpx.WithMiddleware(signer.Middleware))
+func (s *Signer) Middleware(next httpx.Handler) httpx.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if err := s.Verify(r); err != nil {
+			return err
+		}
+
+		return next(w, r)
+	}
+}
+
+// valid compares sig with the signatures of every key in constant time
+func (s *Signer) valid(sig, method, path, expiry string) bool {
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+
+	ok := hmac.Equal(got, mac(s.cfg.Key, method, path, expiry))
+	for _, key := range s.cfg.PreviousKeys {
+		ok = hmac.Equal(got, mac(key, method, path, expiry)) || ok
+	}
+
+	return ok
+}
+
+func signature(key []byte, method, path, expiry string) string {
+	return base64.RawURLEncoding.EncodeToString(mac(key, method, path, expiry))
+}
+
+func mac(key []byte, method, path, expiry string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(method + "\n" + path + "\n" + expiry))
+	return h.Sum(nil)
+}
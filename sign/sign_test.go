@@ -0,0 +1,137 @@
+package sign
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gabivlj/httpx"
+)
+
+var now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func newSigner(key string, previous ...string) *Signer {
+	cfg := Config{Key: []byte(key), Now: func() time.Time { return now }}
+	for _, p := range previous {
+		cfg.PreviousKeys = append(cfg.PreviousKeys, []byte(p))
+	}
+
+	return New(cfg)
+}
+
+func TestVerify(t *testing.T) {
+	signer := newSigner("key", "old")
+	path := &url.URL{Path: "/downloads/report.pdf"}
+	valid := signer.Sign(http.MethodGet, path, now.Add(time.Hour))
+
+	tests := []struct {
+		name   string
+		method string
+		url    func() string
+		code   *httpx.ErrorJSONCode
+	}{
+		{name: "valid", method: http.MethodGet, url: valid.String},
+		{name: "head with the get signature", method: http.MethodHead, url: valid.String},
+		{name: "other query parameters", method: http.MethodGet, url: func() string {
+			u := *valid
+			q := u.Query()
+			q.Set("download", "1")
+			u.RawQuery = q.Encode()
+			return u.String()
+		}},
+		{name: "previous key", method: http.MethodGet, url: func() string {
+			return newSigner("old").Sign(http.MethodGet, path, now.Add(time.Hour)).String()
+		}},
+		{name: "other method", method: http.MethodDelete, url: valid.String, code: ErrInvalidSignature},
+		{name: "other path", method: http.MethodGet, url: func() string {
+			u := *valid
+			u.Path = "/downloads/secrets.pdf"
+			return u.String()
+		}, code: ErrInvalidSignature},
+		{name: "extended expiry", method: http.MethodGet, url: func() string {
+			u := *valid
+			q := u.Query()
+			q.Set("expires", "99999999999")
+			u.RawQuery = q.Encode()
+			return u.String()
+		}, code: ErrInvalidSignature},
+		{name: "forged with another key", method: http.MethodGet, url: func() string {
+			return newSigner("attacker").Sign(http.MethodGet, path, now.Add(time.Hour)).String()
+		}, code: ErrInvalidSignature},
+		{name: "missing signature", method: http.MethodGet, url: func() string { return path.String() }, code: ErrInvalidSignature},
+		{name: "repeated signature", method: http.MethodGet, url: func() string {
+			return valid.String() + "&signature=x"
+		}, code: ErrInvalidSignature},
+		{name: "expired", method: http.MethodGet, url: func() string {
+			return signer.Sign(http.MethodGet, path, now.Add(-time.Second)).String()
+		}, code: ErrExpiredSignature},
+		{name: "expires now", method: http.MethodGet, url: func() string {
+			return signer.Sign(http.MethodGet, path, now).String()
+		}, code: ErrExpiredSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := signer.Verify(httptest.NewRequest(tt.method, tt.url(), nil))
+			if tt.code == nil {
+				if err != nil {
+					t.Fatalf("expected the request to be accepted, got %v", err)
+				}
+
+				return
+			}
+
+			res, ok := err.(*httpx.Response)
+			if !ok || res.ErrorCode() != tt.code.Code {
+				t.Fatalf("expected %s, got %v", tt.code.Code, err)
+			}
+		})
+	}
+}
+
+func TestRotatedKeyIsRejectedOnceDropped(t *testing.T) {
+	link := newSigner("old").Sign(http.MethodGet, &url.URL{Path: "/a"}, now.Add(time.Hour))
+	if err := newSigner("key").Verify(httptest.NewRequest(http.MethodGet, link.String(), nil)); err == nil {
+		t.Fatal("expected a signature of a key that was dropped to be rejected")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	signer := newSigner("key")
+	handler := httpx.H(signer.Middleware(func(w http.ResponseWriter, r *http.Request) error {
+		return httpx.Code(http.StatusOK).Text("report")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected a 401, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	link := signer.Sign(http.MethodGet, &url.URL{Path: "/a"}, now.Add(time.Hour))
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, link.String(), nil))
+	if w.Code != http.StatusOK || w.Body.String() != "report" {
+		t.Fatalf("expected the report, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestNewRequiresKey(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"nil key":            {},
+		"empty key":          {Key: []byte{}},
+		"empty previous key": {Key: []byte("key"), PreviousKeys: [][]byte{nil}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected New to panic")
+				}
+			}()
+
+			New(cfg)
+		})
+	}
+}
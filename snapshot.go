@@ -0,0 +1,76 @@
+package httpx
+
+import (
+	"bytes"
+	"maps"
+	"net/http"
+)
+
+// Snapshot is a response rendered in memory, it can be stored and replayed later with Response.
+// Caches and idempotency stores keep them.
+type Snapshot struct {
+	Status    int
+	Header    http.Header
+	Body      []byte
+	ErrorCode string
+}
+
+// Capture runs h with r and renders what it responds, written to the http.ResponseWriter or returned,
+// into a Snapshot. The error is the error rendering the body of the returned response, the snapshot
+// shouldn't be stored when it's not nil. h can't flush or hijack the connection.
+// Example:
+//
+//	snap, err := httpx.Capture(next, r)
+//	if err == nil {
+//		cache.Store(key, snap)
+//	}
+//
+//	return snap.Response()
+func Capture(h Handler, r *http.Request) (*Snapshot, error) {
+	rec := newRecorder()
+	var copyErr error
+	errorCode := ""
+	if err := h(rec, r); err != nil {
+		res := responseOf(err)
+		errorCode = res.ErrorCode()
+		if !rec.wroteHeader {
+			copyErr = res.Write(rec, r)
+		}
+	}
+
+	return &Snapshot{Status: rec.code, Header: rec.header, Body: bytes.Clone(rec.body.Bytes()), ErrorCode: errorCode}, copyErr
+}
+
+// Response returns a response replaying the snapshot, repeated headers like Set-Cookie keep all their values.
+func (s *Snapshot) Response() *Response {
+	res := Code(s.Status).Bytes(s.Body)
+	res.headers = make(map[string]string, len(s.Header))
+	for key, values := range s.Header {
+		switch len(values) {
+		case 0:
+		case 1:
+			res.headers[key] = values[0]
+		default:
+			if res.multiHeaders == nil {
+				res.multiHeaders = http.Header{}
+			}
+
+			res.multiHeaders[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+		}
+	}
+
+	res.errorCode = s.ErrorCode
+	return res
+}
+
+// Clone returns a deep copy of the snapshot
+func (s *Snapshot) Clone() *Snapshot {
+	clone := *s
+	clone.Header = maps.Clone(s.Header)
+	for key, values := range clone.Header {
+		clone.Header[key] = append([]string(nil), values...)
+	}
+
+	clone.Body = bytes.Clone(s.Body)
+	return &clone
+}
@@ -0,0 +1,153 @@
+package httpx
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Codes returned by the status constructors, you can change them to customize the responses.
+var (
+	ErrBadRequest           = NewCode("BAD_REQUEST", http.StatusBadRequest)
+	ErrUnauthorized         = NewCode("UNAUTHORIZED", http.StatusUnauthorized)
+	ErrForbidden            = NewCode("FORBIDDEN", http.StatusForbidden)
+	ErrNotFound             = NewCode("NOT_FOUND", http.StatusNotFound)
+	ErrConflict             = NewCode("CONFLICT", http.StatusConflict)
+	ErrUnprocessableEntity  = NewCode("UNPROCESSABLE_ENTITY", http.StatusUnprocessableEntity)
+	ErrPreconditionFailed   = NewCode("PRECONDITION_FAILED", http.StatusPreconditionFailed)
+	ErrPreconditionRequired = NewCode("PRECONDITION_REQUIRED", http.StatusPreconditionRequired)
+	ErrTooManyRequests      = NewCode("TOO_MANY_REQUESTS", http.StatusTooManyRequests)
+	ErrInternal             = NewCode("INTERNAL_SERVER_ERROR", http.StatusInternalServerError)
+	ErrServiceUnavailable   = NewCode("SERVICE_UNAVAILABLE", http.StatusServiceUnavailable)
+)
+
+// Codes of the canonical RPC errors without an HTTP equivalent, the gRPC and Connect mappings share them.
+var (
+	ErrCanceled           = NewCode("CANCELED", StatusClientClosedRequest)
+	ErrUnknown            = NewCode("UNKNOWN", http.StatusInternalServerError)
+	ErrAlreadyExists      = NewCode("ALREADY_EXISTS", http.StatusConflict)
+	ErrFailedPrecondition = NewCode("FAILED_PRECONDITION", http.StatusBadRequest)
+	ErrAborted            = NewCode("ABORTED", http.StatusConflict)
+	ErrOutOfRange         = NewCode("OUT_OF_RANGE", http.StatusBadRequest)
+	ErrUnimplemented      = NewCode("UNIMPLEMENTED", http.StatusNotImplemented)
+	ErrDataLoss           = NewCode("DATA_LOSS", http.StatusInternalServerError)
+)
+
+// OK returns a 200 response with v as JSON
+func OK(v any) *Response {
+	return Code(http.StatusOK).JSON(v)
+}
+
+// Created returns a 201 response with the Location of the new resource and v as JSON, if it's not nil.
+func Created(location string, v any) *Response {
+	res := Code(http.StatusCreated).Location(location)
+	if v == nil {
+		return res
+	}
+
+	return res.JSON(v)
+}
+
+// Location sets the Location header, pointing to a created resource or the target of a redirect.
+func (r *Response) Location(url string) *Response {
+	r = r.mutable()
+	r.setHeader("Location", url)
+	return r
+}
+
+// Accepted returns a 202 response with the Location where the client can poll the status of the operation,
+// if it's not empty, and v as JSON, if it's not nil. See JobStatus for the status handler.
+func Accepted(statusURL string, v any) *Response {
+	res := Code(http.StatusAccepted)
+	if statusURL != "" {
+		res = res.Location(statusURL)
+	}
+
+	if v == nil {
+		return res
+	}
+
+	return res.JSON(v)
+}
+
+// NoContent returns an empty 204 response
+func NoContent() *Response {
+	return Code(http.StatusNoContent)
+}
+
+// BadRequest returns ErrBadRequest as JSON with the optional extra payload
+func BadRequest(extra ...any) *Response {
+	return ErrBadRequest.JSON(extra...)
+}
+
+// Unauthorized returns ErrUnauthorized as JSON with the optional extra payload
+func Unauthorized(extra ...any) *Response {
+	return ErrUnauthorized.JSON(extra...)
+}
+
+// Forbidden returns ErrForbidden as JSON with the optional extra payload
+func Forbidden(extra ...any) *Response {
+	return ErrForbidden.JSON(extra...)
+}
+
+// NotFound returns ErrNotFound as JSON with the optional extra payload
+func NotFound(extra ...any) *Response {
+	return ErrNotFound.JSON(extra...)
+}
+
+// Conflict returns ErrConflict as JSON with the optional extra payload
+func Conflict(extra ...any) *Response {
+	return ErrConflict.JSON(extra...)
+}
+
+// UnprocessableEntity returns ErrUnprocessableEntity as JSON with the optional extra payload
+func UnprocessableEntity(extra ...any) *Response {
+	return ErrUnprocessableEntity.JSON(extra...)
+}
+
+// PreconditionFailed returns ErrPreconditionFailed as JSON with the optional extra payload
+func PreconditionFailed(extra ...any) *Response {
+	return ErrPreconditionFailed.JSON(extra...)
+}
+
+// PreconditionRequired returns ErrPreconditionRequired as JSON with the optional extra payload
+func PreconditionRequired(extra ...any) *Response {
+	return ErrPreconditionRequired.JSON(extra...)
+}
+
+// TooManyRequests returns ErrTooManyRequests as JSON with the optional extra payload,
+// telling the client to retry after retryAfter.
+func TooManyRequests(retryAfter time.Duration, extra ...any) *Response {
+	return ErrTooManyRequests.JSON(extra...).RetryAfter(retryAfter)
+}
+
+// ServiceUnavailable returns ErrServiceUnavailable as JSON with the optional extra payload,
+// telling the client to retry after retryAfter.
+func ServiceUnavailable(retryAfter time.Duration, extra ...any) *Response {
+	return ErrServiceUnavailable.JSON(extra...).RetryAfter(retryAfter)
+}
+
+// RetryAfter sets the Retry-After header in seconds, rounded up
+func (r *Response) RetryAfter(d time.Duration) *Response {
+	r = r.mutable()
+	seconds := int64(math.Ceil(d.Seconds()))
+	if seconds < 0 {
+		seconds = 0
+	}
+
+	r.setHeader("Retry-After", strconv.FormatInt(seconds, 10))
+	return r
+}
+
+// RetryAt sets the Retry-After header to the date t, for example when a maintenance window ends
+func (r *Response) RetryAt(t time.Time) *Response {
+	r = r.mutable()
+	r.setHeader("Retry-After", t.UTC().Format(http.TimeFormat))
+	return r
+}
+
+// InternalServerError returns ErrInternal as JSON with the optional extra payload
+func InternalServerError(extra ...any) *Response {
+	return ErrInternal.JSON(extra...)
+}
@@ -0,0 +1,148 @@
+package httpx
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// StreamWriter is the writer passed to Stream functions. Everything written to it is
+// sent to the client in chunks each time Flush is called, or periodically if the response
+// has a FlushInterval. It's safe to use from multiple goroutines.
+type StreamWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	flush  func() error
+	header http.Header
+	dirty  bool
+}
+
+// Write writes p to the client, it won't be sent until the next Flush.
+func (s *StreamWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dirty = true
+	return s.w.Write(p)
+}
+
+// Flush sends the written data to the client.
+func (s *StreamWriter) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dirty = false
+	return s.flush()
+}
+
+// Trailer sets the value of a trailer declared with Response.Trailer, it's sent after the body.
+// Use it to publish values computed while streaming, like checksums or counts.
+func (s *StreamWriter) Trailer(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.header != nil {
+		s.header[http.TrailerPrefix+http.CanonicalHeaderKey(key)] = []string{value}
+	}
+}
+
+// flushDirty flushes only if there is data written since the last flush
+func (s *StreamWriter) flushDirty() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty {
+		return nil
+	}
+
+	s.dirty = false
+	return s.flush()
+}
+
+// Stream sets fn as the body of the response, letting it send the body in chunks with StreamWriter.Flush.
+// Example:
+//
+//	return httpx.Code(http.StatusOK).FlushInterval(time.Second).Stream(func(w *httpx.StreamWriter) error {
+//		for event := range events {
+//			if _, err := fmt.Fprintln(w, event); err != nil {
+//				return err
+//			}
+//		}
+//
+//		return nil
+//	})
+func (r *Response) Stream(fn func(w *StreamWriter) error) *Response {
+	r = r.mutable()
+	r.resetBody(-1)
+	r.stream = fn
+	r.Copy = r.copyStream
+	return r
+}
+
+// copyStream runs the Stream function of r with the flush interval r has when it's written
+func (r *Response) copyStream(w io.Writer) error {
+	stream := &StreamWriter{w: w, flush: flusherOf(w), header: headerOf(w)}
+	if r.flushInterval <= 0 {
+		return r.stream(stream)
+	}
+
+	// the flusher is joined before returning, the writer can't be used once Copy returns
+	done, exited := make(chan struct{}), make(chan struct{})
+	defer func() {
+		close(done)
+		<-exited
+	}()
+
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(r.flushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if stream.flushDirty() != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return r.stream(stream)
+}
+
+// FlushInterval makes Stream responses flush automatically every d when there's unflushed data.
+func (r *Response) FlushInterval(d time.Duration) *Response {
+	r = r.mutable()
+	r.flushInterval = d
+	return r
+}
+
+// flusherOf returns a function that flushes w, or a no-op if w can't be flushed
+func flusherOf(w io.Writer) func() error {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush
+	case http.ResponseWriter:
+		rc := http.NewResponseController(f)
+		return func() error {
+			if err := rc.Flush(); !errors.Is(err, http.ErrNotSupported) {
+				return err
+			}
+
+			return nil
+		}
+	case http.Flusher:
+		return func() error { f.Flush(); return nil }
+	}
+
+	return func() error { return nil }
+}
+
+// headerOf returns the header of w, or nil if w doesn't have one
+func headerOf(w io.Writer) http.Header {
+	if h, ok := w.(interface{ Header() http.Header }); ok {
+		return h.Header()
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package httpx
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStreamFlushIntervalJoinsFlusher(t *testing.T) {
+	for i := 0; i < 2000; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		Code(http.StatusOK).Compress().FlushInterval(time.Microsecond).Stream(func(w *StreamWriter) error {
+			for j := 0; j < 3; j++ {
+				if _, err := fmt.Fprintln(w, j); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}).ServeHTTP(w, req)
+		// the handler is done with the writer, a late tick of the flusher would race with this
+		w.Flush()
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("got status %d", w.Code)
+		}
+	}
+}
+
+// countingFlusher counts the flushes of a Stream
+type countingFlusher struct {
+	bytes.Buffer
+	flushes atomic.Int32
+}
+
+func (c *countingFlusher) Flush() error {
+	c.flushes.Add(1)
+	return nil
+}
+
+func TestStreamUsesFlushIntervalOfClone(t *testing.T) {
+	// the stream waits a bit for the periodic flush of the data it wrote
+	proto := Code(http.StatusOK).Stream(func(sw *StreamWriter) error {
+		fmt.Fprint(sw, "event")
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}).Freeze()
+
+	tests := []struct {
+		name    string
+		res     *Response
+		flushed bool
+	}{
+		{name: "clone with an interval", res: proto.FlushInterval(time.Millisecond), flushed: true},
+		{name: "prototype", res: proto},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &countingFlusher{}
+			if err := tt.res.Copy(w); err != nil {
+				t.Fatal(err)
+			}
+
+			if flushed := w.flushes.Load() > 0; flushed != tt.flushed {
+				t.Fatalf("expected flushed to be %v", tt.flushed)
+			}
+		})
+	}
+}
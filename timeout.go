@@ -0,0 +1,105 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrTimeout is the code returned by WithTimeout handlers that exceeded their deadline, you can
+// change it to customize the response.
+var ErrTimeout = NewCode("TIMEOUT", http.StatusGatewayTimeout)
+
+// WithTimeout runs h with a request context that is canceled after d. If h didn't finish by then,
+// the client receives ErrTimeout and every later write of h fails with http.ErrHandlerTimeout.
+// What h writes to the http.ResponseWriter is buffered until it returns, so it's not suited for streaming.
+func WithTimeout(d time.Duration, h Handler) Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+
+		r = r.WithContext(ctx)
+		tw := &timeoutWriter{header: make(http.Header), code: http.StatusOK}
+		done := make(chan error, 1)
+		panicked := make(chan any, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicked <- p
+				}
+			}()
+
+			done <- h(tw, r)
+		}()
+
+		select {
+		case p := <-panicked:
+			panic(p)
+		case err := <-done:
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			dst := w.Header()
+			for key, values := range tw.header {
+				dst[key] = values
+			}
+
+			if tw.wroteHeader {
+				w.WriteHeader(tw.code)
+			}
+
+			if tw.body.Len() > 0 {
+				if _, copyErr := w.Write(tw.body.Bytes()); copyErr != nil {
+					handleCopyError(r, copyErr)
+				}
+			}
+
+			return err
+		case <-ctx.Done():
+			tw.mu.Lock()
+			tw.timedOut = true
+			tw.mu.Unlock()
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return ErrTimeout.JSON()
+			}
+
+			return ctx.Err()
+		}
+	}
+}
+
+// timeoutWriter buffers the response of a handler until it finishes or times out
+type timeoutWriter struct {
+	mu          sync.Mutex
+	header      http.Header
+	body        bytes.Buffer
+	code        int
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header { return tw.header }
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	tw.wroteHeader = true
+	return tw.body.Write(p)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+
+	tw.wroteHeader = true
+	tw.code = code
+}
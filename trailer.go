@@ -0,0 +1,53 @@
+package httpx
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Trailer declares the trailer key in the Trailer header and sends it with value after the body.
+// Responses with trailers are always sent chunked. Stream responses can change the value while
+// streaming with StreamWriter.Trailer.
+// Example:
+//
+//	return httpx.Code(http.StatusOK).Trailer("X-Checksum", "").Stream(func(w *httpx.StreamWriter) error {
+//		h := sha256.New()
+//		if _, err := io.Copy(io.MultiWriter(w, h), export); err != nil {
+//			return err
+//		}
+//
+//		w.Trailer("X-Checksum", hex.EncodeToString(h.Sum(nil)))
+//		return nil
+//	})
+func (r *Response) Trailer(key, value string) *Response {
+	r = r.mutable()
+	if r.trailers == nil {
+		r.trailers = map[string]string{}
+	}
+
+	r.trailers[http.CanonicalHeaderKey(key)] = value
+	return r
+}
+
+// trailerNames returns the value of the Trailer header
+func (r *Response) trailerNames() string {
+	names := make([]string, 0, len(r.trailers))
+	for key := range r.trailers {
+		names = append(names, key)
+	}
+
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+// writeTrailers sets the trailer values that weren't set while writing the body
+func (r *Response) writeTrailers(w http.ResponseWriter) {
+	for key, value := range r.trailers {
+		if _, ok := w.Header()[http.TrailerPrefix+key]; ok {
+			continue
+		}
+
+		w.Header().Set(key, value)
+	}
+}
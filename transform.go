@@ -0,0 +1,59 @@
+package httpx
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+const transformersKey = "httpx.transformers"
+
+// Transformer rewrites the response of a request before it's written, returning the response to write.
+// Modify it with its methods, frozen responses are cloned by them.
+type Transformer func(res *Response, r *http.Request) *Response
+
+var transformers atomic.Pointer[[]Transformer]
+
+// Transform adds transformers that every response returned by the httpx handlers goes through before BeforeWrite, in the
+// order they were added. They're meant for cross-cutting rewrites, like turning 500s into 503s during a deploy.
+// Example:
+//
+//	httpx.Transform(func(res *httpx.Response, r *http.Request) *httpx.Response {
+//		if res.Code == http.StatusInternalServerError && deploying.Load() {
+//			return httpx.ServiceUnavailable(30 * time.Second)
+//		}
+//
+//		return res
+//	})
+func Transform(fns ...Transformer) {
+	addHooks(&transformers, fns)
+}
+
+// WithTransformer adds transformers that only apply to the responses of a group or route, after the Transform ones.
+// Example:
+//
+//	v1 := mux.Group("/v1", httpx.WithTransformer(deprecated))
+func WithTransformer(fns ...Transformer) Option {
+	return WithMiddleware(func(next Handler) Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			local, _ := Get[[]Transformer](r, transformersKey)
+			return next(w, Set(r, transformersKey, append(local[:len(local):len(local)], fns...)))
+		}
+	})
+}
+
+// transform runs the transformers of the request on res
+func transform(res *Response, r *http.Request) *Response {
+	global := transformers.Load()
+	local, _ := Get[[]Transformer](r, transformersKey)
+	if global != nil {
+		for _, fn := range *global {
+			res = fn(res, r)
+		}
+	}
+
+	for _, fn := range local {
+		res = fn(res, r)
+	}
+
+	return res
+}
@@ -0,0 +1,62 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	defer transformers.Store(nil)
+	Transform(func(res *Response, r *http.Request) *Response {
+		return res.Headers(map[string]string{"X-Order": res.HeaderMap()["X-Order"] + "global,"})
+	})
+
+	mux := NewMux(WithTransformer(func(res *Response, r *http.Request) *Response {
+		return res.Headers(map[string]string{"X-Order": res.HeaderMap()["X-Order"] + "mux,"})
+	}))
+
+	mux.GET("/teapot", func(w http.ResponseWriter, r *http.Request) error {
+		return ErrInternal.JSON()
+	}, WithTransformer(func(res *Response, r *http.Request) *Response {
+		if res.Code == http.StatusInternalServerError {
+			return ServiceUnavailable(0).Headers(map[string]string{"X-Order": res.HeaderMap()["X-Order"] + "route"})
+		}
+
+		return res
+	}))
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/teapot", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected the route transformer to turn the 500 into a 503, got %d", w.Code)
+	}
+
+	if order := w.Header().Get("X-Order"); order != "global,mux,route" {
+		t.Fatalf("expected the global transformers first, got %q", order)
+	}
+}
+
+func TestTransformConcurrently(t *testing.T) {
+	defer transformers.Store(nil)
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Transform(func(res *Response, r *http.Request) *Response {
+				return res.Headers(map[string]string{"X-" + strconv.Itoa(i): "1"})
+			})
+
+			transform(Code(http.StatusOK), httptest.NewRequest(http.MethodGet, "/", nil))
+		}()
+	}
+
+	wg.Wait()
+	res := transform(Code(http.StatusOK), httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := len(res.HeaderMap()); got != 50 {
+		t.Fatalf("expected the 50 transformers to run, got %d", got)
+	}
+}
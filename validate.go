@@ -0,0 +1,70 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// StatusPolicy is what happens with responses that have an invalid status, see StatusValidation
+type StatusPolicy int
+
+const (
+	// StatusUnchecked writes the responses as they are
+	StatusUnchecked StatusPolicy = iota
+	// StatusPanic panics when the response is built with an invalid status or written with a body its
+	// status can't have, meant for development and tests
+	StatusPanic
+	// StatusError sends an ErrInternal response instead and passes the error to InvalidResponseHandler
+	StatusError
+)
+
+// StatusValidation validates the status of the responses: it must be between 200 and 999, and 204 and 304
+// responses can't have a body. Statuses under 100 panic inside net/http and informational ones can't
+// be the final response.
+// Example:
+//
+//	httpx.StatusValidation = httpx.StatusPanic // in tests
+var StatusValidation = StatusUnchecked
+
+// InvalidResponseHandler is fired with the error of the responses replaced by ErrInternal with StatusError
+var InvalidResponseHandler = func(r *http.Request, res *Response, err error) {}
+
+// validateCode panics building a response with an invalid status when StatusValidation is StatusPanic
+func validateCode(code int) {
+	if StatusValidation == StatusPanic && (code < 100 || code > 999) {
+		panic(fmt.Sprintf("httpx: invalid status code %d", code))
+	}
+}
+
+// validate returns why the response can't be written, or nil
+func (r *Response) validate() error {
+	switch {
+	case r.Code < 100 || r.Code > 999:
+		return fmt.Errorf("httpx: invalid status code %d", r.Code)
+	case r.Code < http.StatusOK:
+		return fmt.Errorf("httpx: informational status %d can't be the final response", r.Code)
+	case r.Copy != nil && !bodyAllowed(r.Code):
+		return fmt.Errorf("httpx: status %d can't have a body", r.Code)
+	}
+
+	return nil
+}
+
+// checkStatus applies StatusValidation to the response, it returns the response to write instead or nil
+func (r *Response) checkStatus(req *http.Request) *Response {
+	if StatusValidation == StatusUnchecked {
+		return nil
+	}
+
+	err := r.validate()
+	if err == nil {
+		return nil
+	}
+
+	if StatusValidation == StatusPanic {
+		panic(err)
+	}
+
+	InvalidResponseHandler(req, r, err)
+	return ErrInternal.JSON()
+}
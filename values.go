@@ -0,0 +1,71 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+type valuesKey struct{}
+
+// values are the request scoped values set with Set
+type values struct {
+	mu sync.RWMutex
+	m  map[string]any
+}
+
+// Set stores a request scoped value under key, use the returned request for the next handlers.
+// Values are stored once per request in its context, so setting more values doesn't keep wrapping it.
+// Example:
+//
+//	func Tenant(next httpx.Handler) httpx.Handler {
+//		return func(w http.ResponseWriter, r *http.Request) error {
+//			tenant, err := tenants.Find(r.Host)
+//			if err != nil {
+//				return httpx.NotFound()
+//			}
+//
+//			return next(w, httpx.Set(r, "tenant", tenant))
+//		}
+//	}
+func Set(r *http.Request, key string, value any) *http.Request {
+	if v, ok := r.Context().Value(valuesKey{}).(*values); ok {
+		v.mu.Lock()
+		v.m[key] = value
+		v.mu.Unlock()
+		return r
+	}
+
+	v := &values{m: map[string]any{key: value}}
+	return r.WithContext(context.WithValue(r.Context(), valuesKey{}, v))
+}
+
+// Get returns the value stored with Set under key, and false if there's none or it's not a T.
+// Example:
+//
+//	tenant, ok := httpx.Get[*Tenant](r, "tenant")
+func Get[T any](r *http.Request, key string) (T, bool) {
+	var zero T
+	v, ok := r.Context().Value(valuesKey{}).(*values)
+	if !ok {
+		return zero, false
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	value, ok := v.m[key].(T)
+	if !ok {
+		return zero, false
+	}
+
+	return value, true
+}
+
+// withValues makes sure the request has values, so the values set by inner handlers are seen by the outer ones
+func withValues(r *http.Request) *http.Request {
+	if _, ok := r.Context().Value(valuesKey{}).(*values); ok {
+		return r
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), valuesKey{}, &values{m: map[string]any{}}))
+}
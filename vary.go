@@ -0,0 +1,46 @@
+package httpx
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Vary adds the request headers that were used to choose the representation of the response to its
+// Vary header, so caches don't serve it to requests that would get a different one. Values are
+// deduplicated and merged with the Vary header already set on the http.ResponseWriter.
+// Example:
+//
+//	lang := pickLanguage(r.Header.Get("Accept-Language"))
+//	return httpx.Code(http.StatusOK).Vary("Accept-Language").JSON(messages[lang])
+func (r *Response) Vary(headers ...string) *Response {
+	r = r.mutable()
+	r.setHeader("Vary", mergeVary(r.headers["Vary"], headers...))
+	return r
+}
+
+// addVary merges the headers into the Vary header of h
+func addVary(h http.Header, headers ...string) {
+	h.Set("Vary", mergeVary(strings.Join(h.Values("Vary"), ", "), headers...))
+}
+
+// mergeVary returns the Vary value with the headers added, any "*" makes it "*"
+func mergeVary(vary string, headers ...string) string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, header := range append(strings.Split(vary, ","), headers...) {
+		header = http.CanonicalHeaderKey(strings.TrimSpace(header))
+		switch {
+		case header == "":
+			continue
+		case header == "*":
+			return "*"
+		case seen[header]:
+			continue
+		}
+
+		seen[header] = true
+		names = append(names, header)
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,123 @@
+package httpx
+
+import (
+	"mime"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrUnsupportedVersion is the code returned by Versioned when the request asks for a version that isn't supported,
+// its extra payload are the supported versions.
+var ErrUnsupportedVersion = NewCode("UNSUPPORTED_VERSION", http.StatusBadRequest)
+
+const versionKey = "httpx.version"
+
+// VersionSource returns the API version a request asks for, or "" if it doesn't say
+type VersionSource func(r *http.Request) string
+
+var pathVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// VersionFromPath reads the version from the first segment of the path, like "v2" in "/v2/users".
+func VersionFromPath(r *http.Request) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if pathVersion.MatchString(segment) {
+		return segment
+	}
+
+	return ""
+}
+
+// VersionFromHeader reads the version from a header, like "Api-Version: 2".
+func VersionFromHeader(name string) VersionSource {
+	return func(r *http.Request) string {
+		return strings.TrimSpace(r.Header.Get(name))
+	}
+}
+
+// VersionFromAccept reads the version from a parameter of the media types of the Accept header,
+// like version in "Accept: application/json; version=2" or profile.
+func VersionFromAccept(param string) VersionSource {
+	return func(r *http.Request) string {
+		for _, mediaType := range strings.Split(r.Header.Get("Accept"), ",") {
+			if _, params, err := mime.ParseMediaType(mediaType); err == nil && params[param] != "" {
+				return params[param]
+			}
+		}
+
+		return ""
+	}
+}
+
+// Versioning configures Versioned
+type Versioning struct {
+	// Sources are tried in order until one finds the version
+	Sources []VersionSource
+	// Supported are the versions served, any version is accepted when it's empty
+	Supported []string
+	// Default is the version of the requests that don't ask for one
+	Default string
+}
+
+// Versioned returns a middleware that resolves the API version of the requests, returned by Version.
+// Requests asking for an unsupported version get ErrUnsupportedVersion. Transformers can use the version
+// to render errors in the format each version expects, see ByVersion.
+// Example:
+//
+//	mux := httpx.NewMux(httpx.WithMiddleware(httpx.Versioned(httpx.Versioning{
+//		Sources:   []httpx.VersionSource{httpx.VersionFromHeader("Api-Version")},
+//		Supported: []string{"1", "2"},
+//		Default:   "2",
+//	})))
+func Versioned(v Versioning) Middleware {
+	return func(next Handler) Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			version := ""
+			for _, source := range v.Sources {
+				if version = source(r); version != "" {
+					break
+				}
+			}
+
+			if version == "" {
+				version = v.Default
+			}
+
+			if len(v.Supported) > 0 && !slices.Contains(v.Supported, version) {
+				return ErrUnsupportedVersion.JSON(v.Supported)
+			}
+
+			return next(w, Set(r, versionKey, version))
+		}
+	}
+}
+
+// Version returns the API version resolved by Versioned, or "" if there's none
+func Version(r *http.Request) string {
+	version, _ := Get[string](r, versionKey)
+	return version
+}
+
+// ByVersion returns a transformer that applies the one of the Version of the request, if there's one.
+// It lets versions keep their error format while newer ones evolve it.
+// Example:
+//
+//	httpx.Transform(httpx.ByVersion(map[string]httpx.Transformer{
+//		"1": func(res *httpx.Response, r *http.Request) *httpx.Response {
+//			if res.ErrorCode() == "" {
+//				return res
+//			}
+//
+//			return res.JSON(map[string]any{"error": res.ErrorCode(), "details": res.ErrorExtra()})
+//		},
+//	}))
+func ByVersion(transformers map[string]Transformer) Transformer {
+	return func(res *Response, r *http.Request) *Response {
+		if fn, ok := transformers[Version(r)]; ok {
+			return fn(res, r)
+		}
+
+		return res
+	}
+}
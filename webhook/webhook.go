@@ -0,0 +1,189 @@
+// package webhook verifies the signatures of webhooks received by httpx handlers.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabivlj/httpx"
+)
+
+// Codes returned by the middleware
+var (
+	// ErrMalformed is returned when the signature or timestamp headers are missing or can't be parsed
+	ErrMalformed = httpx.NewCode("MALFORMED_WEBHOOK", http.StatusBadRequest)
+	// ErrInvalidSignature is returned when no signature matches the body
+	ErrInvalidSignature = httpx.NewCode("INVALID_WEBHOOK_SIGNATURE", http.StatusUnauthorized)
+	// ErrStale is returned when the timestamp is outside of the tolerance, its extra payload is the tolerance in seconds
+	ErrStale = httpx.NewCode("STALE_WEBHOOK", http.StatusUnauthorized)
+)
+
+// Config configures a Verifier
+type Config struct {
+	// Secrets are the HMAC-SHA256 keys the webhooks can be signed with, several can be given to rotate them
+	Secrets [][]byte
+	// SignatureHeader is the header with the signatures, "X-Signature" by default. It can have several
+	// comma separated signatures, in hex or base64 and optionally prefixed like "sha256=" or "v1=".
+	SignatureHeader string
+	// TimestampHeader is the header with the unix timestamp of the webhook. When it's set the signed
+	// payload is the timestamp, a dot and the body, and webhooks older than Tolerance are rejected
+	// so they can't be replayed.
+	TimestampHeader string
+	// Tolerance is how far the timestamp can be from now, 5 minutes by default
+	Tolerance time.Duration
+	// MaxBodySize is the maximum size of the body, 1MB by default
+	MaxBodySize int64
+	// Now returns the current time, time.Now by default
+	Now func() time.Time
+}
+
+// Verifier verifies webhooks
+type Verifier struct {
+	cfg Config
+}
+
+// New returns a Verifier for the config, it panics without Secrets or with an empty one
+func New(cfg Config) *Verifier {
+	if len(cfg.Secrets) == 0 {
+		panic("httpx/webhook: Config.Secrets is required")
+	}
+
+	for _, secret := range cfg.Secrets {
+		if len(secret) == 0 {
+			panic("httpx/webhook: Config.Secrets can't have empty secrets")
+		}
+	}
+
+	if cfg.SignatureHeader == "" {
+		cfg.SignatureHeader = "X-Signature"
+	}
+
+	if cfg.Tolerance <= 0 {
+		cfg.Tolerance = 5 * time.Minute
+	}
+
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = 1 << 20
+	}
+
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	return &Verifier{cfg: cfg}
+}
+
+// Sign returns the hex signature of body sent at timestamp with the first secret, for example to test
+// the handlers. The timestamp is ignored without a TimestampHeader.
+func (v *Verifier) Sign(body []byte, timestamp time.Time) string {
+	return hex.EncodeToString(v.mac(v.cfg.Secrets[0], strconv.FormatInt(timestamp.Unix(), 10), body))
+}
+
+// Verify reads the body of r and checks its signature. The body is replaced with the bytes read,
+// so handlers can read it again after Verify.
+func (v *Verifier) Verify(r *http.Request) error {
+	body, err := io.ReadAll(io.LimitReader(r.Body, v.cfg.MaxBodySize+1))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return httpx.ErrRequestTooLarge.JSON(maxBytesErr.Limit)
+		}
+
+		return err
+	}
+
+	if int64(len(body)) > v.cfg.MaxBodySize {
+		return httpx.ErrRequestTooLarge.JSON(v.cfg.MaxBodySize)
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var timestamp string
+	if v.cfg.TimestampHeader != "" {
+		timestamp = strings.TrimSpace(r.Header.Get(v.cfg.TimestampHeader))
+		sent, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return ErrMalformed.JSON("invalid " + v.cfg.TimestampHeader)
+		}
+
+		if age := v.cfg.Now().Sub(time.Unix(sent, 0)); age > v.cfg.Tolerance || age < -v.cfg.Tolerance {
+			return ErrStale.JSON(int64(v.cfg.Tolerance.Seconds()))
+		}
+	}
+
+	values := r.Header.Values(v.cfg.SignatureHeader)
+	if len(values) == 0 {
+		return ErrMalformed.JSON("missing " + v.cfg.SignatureHeader)
+	}
+
+	signatures := signatures(values)
+	if len(signatures) == 0 {
+		return ErrMalformed.JSON("invalid " + v.cfg.SignatureHeader)
+	}
+
+	for _, secret := range v.cfg.Secrets {
+		expected := v.mac(secret, timestamp, body)
+		for _, sig := range signatures {
+			if hmac.Equal(sig, expected) {
+				return nil
+			}
+		}
+	}
+
+	return ErrInvalidSignature.JSON()
+}
+
+// Middleware rejects the webhooks that Verify doesn't accept.
+// Example:
+//
+//	hooks := webhook.New(webhook.Config{Secrets: [][]byte{secret}, TimestampHeader: "X-Timestamp"})
+//	mux.POST("/webhooks/payments", payments, httpx.WithMiddleware(hooks.Middleware))
+func (v *Verifier) Middleware(next httpx.Handler) httpx.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if err := v.Verify(r); err != nil {
+			return err
+		}
+
+		return next(w, r)
+	}
+}
+
+func (v *Verifier) mac(secret []byte, timestamp string, body []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	if v.cfg.TimestampHeader != "" {
+		h.Write([]byte(timestamp + "."))
+	}
+
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// signatures decodes the signatures of the header values, the ones that can't be decoded are skipped
+func signatures(values []string) [][]byte {
+	var sigs [][]byte
+	for _, value := range values {
+		for _, sig := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+			// base64 only has = as padding at the end, so anything before another = is a prefix
+			if _, after, ok := strings.Cut(sig, "="); ok && after != "" && after[0] != '=' {
+				sig = after
+			}
+
+			if decoded, err := hex.DecodeString(sig); err == nil && len(decoded) == sha256.Size {
+				sigs = append(sigs, decoded)
+			} else if decoded, err := base64.StdEncoding.DecodeString(sig); err == nil && len(decoded) == sha256.Size {
+				sigs = append(sigs, decoded)
+			}
+		}
+	}
+
+	return sigs
+}
@@ -0,0 +1,150 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabivlj/httpx"
+)
+
+var now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestSignatures(t *testing.T) {
+	sum := sha256.Sum256([]byte("body"))
+	hexSig := hex.EncodeToString(sum[:])
+	b64Sig := base64.StdEncoding.EncodeToString(sum[:])
+
+	tests := []struct {
+		name   string
+		values []string
+		want   int
+	}{
+		{name: "hex", values: []string{hexSig}, want: 1},
+		{name: "base64 with padding", values: []string{b64Sig}, want: 1},
+		{name: "prefixed hex", values: []string{"sha256=" + hexSig}, want: 1},
+		{name: "prefixed base64 with padding", values: []string{"v1=" + b64Sig}, want: 1},
+		{name: "comma separated", values: []string{"v1=" + hexSig + ", v1=" + b64Sig}, want: 2},
+		{name: "several headers", values: []string{hexSig, b64Sig}, want: 2},
+		{name: "wrong size", values: []string{hex.EncodeToString(sum[:16])}, want: 0},
+		{name: "not encoded", values: []string{"sha256=zz"}, want: 0},
+		{name: "empty", values: []string{""}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sigs := signatures(tt.values)
+			if len(sigs) != tt.want {
+				t.Fatalf("expected %d signatures, got %d", tt.want, len(sigs))
+			}
+
+			for _, sig := range sigs {
+				if !bytes.Equal(sig, sum[:]) {
+					t.Fatalf("expected %x, got %x", sum, sig)
+				}
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	v := New(Config{Secrets: [][]byte{[]byte("new"), []byte("old")}, TimestampHeader: "X-Timestamp", Now: func() time.Time { return now }})
+	old := New(Config{Secrets: [][]byte{[]byte("old")}, TimestampHeader: "X-Timestamp"})
+	dropped := New(Config{Secrets: [][]byte{[]byte("dropped")}, TimestampHeader: "X-Timestamp"})
+	body := []byte(`{"event": "paid"}`)
+
+	tests := []struct {
+		name      string
+		signature string
+		timestamp time.Time
+		body      []byte
+		code      *httpx.ErrorJSONCode
+	}{
+		{name: "valid", signature: v.Sign(body, now), timestamp: now},
+		{name: "rotated secret", signature: "sha256=" + old.Sign(body, now), timestamp: now},
+		{name: "within the tolerance", signature: v.Sign(body, now.Add(-4*time.Minute)), timestamp: now.Add(-4 * time.Minute)},
+		{name: "future within the tolerance", signature: v.Sign(body, now.Add(4*time.Minute)), timestamp: now.Add(4 * time.Minute)},
+		{name: "one of several signatures", signature: dropped.Sign(body, now) + "," + v.Sign(body, now), timestamp: now},
+		{name: "dropped secret", signature: dropped.Sign(body, now), timestamp: now, code: ErrInvalidSignature},
+		{name: "other body", signature: v.Sign(body, now), timestamp: now, body: []byte(`{"event": "refunded"}`), code: ErrInvalidSignature},
+		{name: "replayed with another timestamp", signature: v.Sign(body, now.Add(-time.Hour)), timestamp: now, code: ErrInvalidSignature},
+		{name: "too old", signature: v.Sign(body, now.Add(-6*time.Minute)), timestamp: now.Add(-6 * time.Minute), code: ErrStale},
+		{name: "too far in the future", signature: v.Sign(body, now.Add(6*time.Minute)), timestamp: now.Add(6 * time.Minute), code: ErrStale},
+		{name: "missing signature", timestamp: now, code: ErrMalformed},
+		{name: "undecodable signature", signature: "sha256=nope", timestamp: now, code: ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent := body
+			if tt.body != nil {
+				sent = tt.body
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(sent))
+			r.Header.Set("X-Timestamp", strconv.FormatInt(tt.timestamp.Unix(), 10))
+			if tt.signature != "" {
+				r.Header.Set("X-Signature", tt.signature)
+			}
+
+			err := v.Verify(r)
+			if tt.code == nil {
+				if err != nil {
+					t.Fatalf("expected the webhook to be accepted, got %v", err)
+				}
+
+				if got, _ := io.ReadAll(r.Body); !bytes.Equal(got, sent) {
+					t.Fatalf("expected the body to be readable again, got %q", got)
+				}
+
+				return
+			}
+
+			res, ok := err.(*httpx.Response)
+			if !ok || res.ErrorCode() != tt.code.Code {
+				t.Fatalf("expected %s, got %v", tt.code.Code, err)
+			}
+		})
+	}
+}
+
+func TestVerifyMalformedTimestamp(t *testing.T) {
+	v := New(Config{Secrets: [][]byte{[]byte("secret")}, TimestampHeader: "X-Timestamp"})
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("body"))
+	r.Header.Set("X-Timestamp", "yesterday")
+	r.Header.Set("X-Signature", v.Sign([]byte("body"), now))
+	if res, ok := v.Verify(r).(*httpx.Response); !ok || res.ErrorCode() != ErrMalformed.Code {
+		t.Fatalf("expected %s", ErrMalformed.Code)
+	}
+}
+
+func TestVerifyTooLarge(t *testing.T) {
+	v := New(Config{Secrets: [][]byte{[]byte("secret")}, MaxBodySize: 4})
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("12345"))
+	r.Header.Set("X-Signature", v.Sign([]byte("12345"), now))
+	if res, ok := v.Verify(r).(*httpx.Response); !ok || res.Status() != http.StatusRequestEntityTooLarge {
+		t.Fatal("expected a 413")
+	}
+}
+
+func TestNewRequiresSecrets(t *testing.T) {
+	for name, secrets := range map[string][][]byte{"no secrets": nil, "empty secret": {[]byte("a"), nil}} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected New to panic")
+				}
+			}()
+
+			New(Config{Secrets: secrets})
+		})
+	}
+}
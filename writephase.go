@@ -0,0 +1,79 @@
+package httpx
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// WriteStartFunc is a hook that runs when httpx starts writing a response, see OnWriteStart
+type WriteStartFunc func(r *http.Request, status int)
+
+// WriteEndFunc is a hook that runs when httpx finished writing a response, see OnWriteEnd.
+// bytes is the length of the body written, d how long writing took and err the error copying the body.
+type WriteEndFunc func(r *http.Request, bytes int64, d time.Duration, err error)
+
+var (
+	writeStartHooks atomic.Pointer[[]WriteStartFunc]
+	writeEndHooks   atomic.Pointer[[]WriteEndFunc]
+)
+
+// OnWriteStart adds hooks that run once the handler returned its response and before it's written,
+// with the status that will be sent. With OnWriteEnd, tracing can tell the time spent in the handler
+// from the time spent encoding the body and sending it.
+// Example:
+//
+//	httpx.OnWriteStart(func(r *http.Request, status int) {
+//		trace.SpanFromContext(r.Context()).AddEvent("write start")
+//	})
+func OnWriteStart(fns ...WriteStartFunc) {
+	addHooks(&writeStartHooks, fns)
+}
+
+// OnWriteEnd adds hooks that run once the response is written, before the after middleware and hooks
+func OnWriteEnd(fns ...WriteEndFunc) {
+	addHooks(&writeEndHooks, fns)
+}
+
+// addHooks appends fns to a copy of the hooks in p and stores it, retrying if other hooks were added meanwhile,
+// so the requests reading p never see a slice that's being modified
+func addHooks[T any](p *atomic.Pointer[[]T], fns []T) {
+	for {
+		current := p.Load()
+		var hooks []T
+		if current != nil {
+			hooks = append(hooks, *current...)
+		}
+
+		hooks = append(hooks, fns...)
+		if p.CompareAndSwap(current, &hooks) {
+			return
+		}
+	}
+}
+
+// tracingWrites returns true if there are write hooks
+func tracingWrites() bool {
+	return writeStartHooks.Load() != nil || writeEndHooks.Load() != nil
+}
+
+// commit writes prepared firing the write hooks around it
+func commit(prepared *Prepared, sw *statusWriter, r *http.Request) error {
+	if hooks := writeStartHooks.Load(); hooks != nil {
+		status := prepared.Status()
+		for _, fn := range *hooks {
+			fn(r, status)
+		}
+	}
+
+	start, written := time.Now(), sw.written
+	err := prepared.Commit()
+	if hooks := writeEndHooks.Load(); hooks != nil {
+		d := time.Since(start)
+		for _, fn := range *hooks {
+			fn(r, sw.written-written, d, err)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,97 @@
+package httpx
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// statusWriter is a http.ResponseWriter that captures the status and the number of bytes written
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	written     int64
+	wroteHeader bool
+}
+
+func newStatusWriter(w http.ResponseWriter) *statusWriter {
+	return &statusWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+// wrapWriter returns w if it's already a statusWriter, so wrapping many times doesn't stack writers
+func wrapWriter(w http.ResponseWriter) *statusWriter {
+	if sw, ok := w.(*statusWriter); ok {
+		return sw
+	}
+
+	return newStatusWriter(w)
+}
+
+// WriteHeader drops the calls after the header was sent instead of letting net/http log superfluous calls
+func (sw *statusWriter) WriteHeader(code int) {
+	if sw.wroteHeader {
+		return
+	}
+
+	if code >= 200 {
+		sw.wroteHeader = true
+		sw.status = code
+	}
+
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+func (sw *statusWriter) Write(p []byte) (int, error) {
+	sw.wroteHeader = true
+	n, err := sw.ResponseWriter.Write(p)
+	sw.written += int64(n)
+	return n, err
+}
+
+// ReadFrom keeps the io.ReaderFrom of the underlying writer reachable, so files are still sent with sendfile
+func (sw *statusWriter) ReadFrom(src io.Reader) (int64, error) {
+	sw.wroteHeader = true
+	var n int64
+	var err error
+	if rf, ok := sw.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(src)
+	} else {
+		n, err = io.Copy(sw.ResponseWriter, src)
+	}
+
+	sw.written += n
+	return n, err
+}
+
+// Flush keeps streaming working through the writer
+func (sw *statusWriter) Flush() {
+	sw.wroteHeader = true
+	_ = http.NewResponseController(sw.ResponseWriter).Flush()
+}
+
+// Hijack keeps websocket upgrades working through the writer, the connection is considered written
+func (sw *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(sw.ResponseWriter).Hijack()
+	if err == nil {
+		sw.wroteHeader = true
+		sw.status = http.StatusSwitchingProtocols
+	}
+
+	return conn, rw, err
+}
+
+// Push keeps HTTP/2 server push working through the writer
+func (sw *statusWriter) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := sw.ResponseWriter.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+
+	return http.ErrNotSupported
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, for SetReadDeadline, SetWriteDeadline
+// and EnableFullDuplex
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
@@ -0,0 +1,15 @@
+module github.com/gabivlj/httpx/xcbor
+
+go 1.23
+
+require (
+	github.com/fxamacker/cbor/v2 v2.9.4
+	github.com/gabivlj/httpx v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/julienschmidt/httprouter v1.3.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+)
+
+replace github.com/gabivlj/httpx => ../
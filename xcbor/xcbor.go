@@ -0,0 +1,30 @@
+// package xcbor encodes httpx responses with CBOR.
+package xcbor
+
+import (
+	"io"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/gabivlj/httpx"
+)
+
+// ContentType is the content type of CBOR bodies
+const ContentType = "application/cbor"
+
+// CBOR returns a body encoder for v, use it with httpx.Response.Encode.
+// Example:
+//
+//	return httpx.Code(http.StatusOK).Encode(xcbor.CBOR(v))
+func CBOR(v any) httpx.BodyEncoder {
+	return encoder{v}
+}
+
+type encoder struct {
+	v any
+}
+
+func (e encoder) ContentType() string { return ContentType }
+
+func (e encoder) Encode(w io.Writer) error {
+	return cbor.NewEncoder(w).Encode(e.v)
+}
@@ -0,0 +1,180 @@
+// package xconnect maps httpx error codes to the canonical error codes of Connect and Twirp and back,
+// so services exposing both protocols return the same errors.
+package xconnect
+
+import (
+	"net/http"
+
+	"github.com/gabivlj/httpx"
+)
+
+// Code is a Connect error code. Twirp uses the same codes, plus Malformed and BadRoute.
+type Code string
+
+const (
+	Canceled           Code = "canceled"
+	Unknown            Code = "unknown"
+	InvalidArgument    Code = "invalid_argument"
+	DeadlineExceeded   Code = "deadline_exceeded"
+	NotFound           Code = "not_found"
+	AlreadyExists      Code = "already_exists"
+	PermissionDenied   Code = "permission_denied"
+	ResourceExhausted  Code = "resource_exhausted"
+	FailedPrecondition Code = "failed_precondition"
+	Aborted            Code = "aborted"
+	OutOfRange         Code = "out_of_range"
+	Unimplemented      Code = "unimplemented"
+	Internal           Code = "internal"
+	Unavailable        Code = "unavailable"
+	DataLoss           Code = "data_loss"
+	Unauthenticated    Code = "unauthenticated"
+	// Malformed and BadRoute are only used by Twirp
+	Malformed Code = "malformed"
+	BadRoute  Code = "bad_route"
+)
+
+// Codes maps the codes of httpx error codes to Connect codes, it can be modified to add the codes of
+// your service. Codes missing from the map are mapped from their HTTP status.
+var Codes = map[string]Code{
+	httpx.ErrBadRequest.Code:           InvalidArgument,
+	httpx.ErrUnauthorized.Code:         Unauthenticated,
+	httpx.ErrForbidden.Code:            PermissionDenied,
+	httpx.ErrNotFound.Code:             NotFound,
+	httpx.ErrConflict.Code:             Aborted,
+	httpx.ErrUnprocessableEntity.Code:  InvalidArgument,
+	httpx.ErrTooManyRequests.Code:      ResourceExhausted,
+	httpx.ErrInternal.Code:             Internal,
+	httpx.ErrServiceUnavailable.Code:   Unavailable,
+	httpx.ErrTimeout.Code:              DeadlineExceeded,
+	httpx.ErrMethodNotAllowed.Code:     BadRoute,
+	httpx.ErrRequestTooLarge.Code:      ResourceExhausted,
+	httpx.ErrUnsupportedMediaType.Code: Malformed,
+	httpx.ErrInvalidMultipart.Code:     Malformed,
+	httpx.ErrMissingFile.Code:          InvalidArgument,
+	httpx.ErrInvalidParameters.Code:    InvalidArgument,
+	httpx.ErrUpstreamUnreachable.Code:  Unavailable,
+	httpx.ErrCanceled.Code:             Canceled,
+	httpx.ErrUnknown.Code:              Unknown,
+	httpx.ErrAlreadyExists.Code:        AlreadyExists,
+	httpx.ErrFailedPrecondition.Code:   FailedPrecondition,
+	httpx.ErrAborted.Code:              Aborted,
+	httpx.ErrOutOfRange.Code:           OutOfRange,
+	httpx.ErrUnimplemented.Code:        Unimplemented,
+	httpx.ErrDataLoss.Code:             DataLoss,
+}
+
+// ErrorCodes maps Connect codes to httpx error codes, it's used by Code.ErrorCode.
+var ErrorCodes = map[Code]*httpx.ErrorJSONCode{
+	Canceled:           httpx.ErrCanceled,
+	Unknown:            httpx.ErrUnknown,
+	InvalidArgument:    httpx.ErrBadRequest,
+	DeadlineExceeded:   httpx.ErrTimeout,
+	NotFound:           httpx.ErrNotFound,
+	AlreadyExists:      httpx.ErrAlreadyExists,
+	PermissionDenied:   httpx.ErrForbidden,
+	ResourceExhausted:  httpx.ErrTooManyRequests,
+	FailedPrecondition: httpx.ErrFailedPrecondition,
+	Aborted:            httpx.ErrAborted,
+	OutOfRange:         httpx.ErrOutOfRange,
+	Unimplemented:      httpx.ErrUnimplemented,
+	Internal:           httpx.ErrInternal,
+	Unavailable:        httpx.ErrServiceUnavailable,
+	DataLoss:           httpx.ErrDataLoss,
+	Unauthenticated:    httpx.ErrUnauthorized,
+	Malformed:          httpx.ErrBadRequest,
+	BadRoute:           httpx.ErrNotFound,
+}
+
+// FromCode returns the Connect code of an httpx error code.
+// Example:
+//
+//	xconnect.FromCode(httpx.ErrNotFound) // not_found
+func FromCode(code *httpx.ErrorJSONCode) Code {
+	return fromCode(code.Code, code.Status)
+}
+
+// FromResponse returns the Connect code of a response, from its error code if it was created
+// from one or from its status if it wasn't.
+func FromResponse(res *httpx.Response) Code {
+	return fromCode(res.ErrorCode(), res.Code)
+}
+
+func fromCode(code string, status int) Code {
+	if c, ok := Codes[code]; ok {
+		return c
+	}
+
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return InvalidArgument
+	case http.StatusUnauthorized:
+		return Unauthenticated
+	case http.StatusForbidden:
+		return PermissionDenied
+	case http.StatusNotFound:
+		return NotFound
+	case http.StatusConflict:
+		return Aborted
+	case http.StatusPreconditionFailed, http.StatusPreconditionRequired:
+		return FailedPrecondition
+	case http.StatusRequestedRangeNotSatisfiable:
+		return OutOfRange
+	case http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		return ResourceExhausted
+	case httpx.StatusClientClosedRequest:
+		return Canceled
+	case http.StatusNotImplemented:
+		return Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return Unavailable
+	case http.StatusGatewayTimeout:
+		return DeadlineExceeded
+	}
+
+	if status >= 500 {
+		return Internal
+	}
+
+	return Unknown
+}
+
+// ErrorCode returns the httpx error code of c, httpx.ErrUnknown if c isn't a known code.
+// Example:
+//
+//	return xconnect.Code(twirpErr.Code()).ErrorCode().JSON(twirpErr.Msg())
+func (c Code) ErrorCode() *httpx.ErrorJSONCode {
+	if code, ok := ErrorCodes[c]; ok {
+		return code
+	}
+
+	return httpx.ErrUnknown
+}
+
+// Status returns the HTTP status Connect uses for c. Twirp uses the same ones except for
+// FailedPrecondition, that it sends with 412.
+func (c Code) Status() int {
+	switch c {
+	case Canceled:
+		return httpx.StatusClientClosedRequest
+	case InvalidArgument, OutOfRange, FailedPrecondition, Malformed:
+		return http.StatusBadRequest
+	case DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case NotFound, BadRoute:
+		return http.StatusNotFound
+	case AlreadyExists, Aborted:
+		return http.StatusConflict
+	case PermissionDenied:
+		return http.StatusForbidden
+	case ResourceExhausted:
+		return http.StatusTooManyRequests
+	case Unimplemented:
+		return http.StatusNotImplemented
+	case Unavailable:
+		return http.StatusServiceUnavailable
+	case Unauthenticated:
+		return http.StatusUnauthorized
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,95 @@
+package xconnect
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gabivlj/httpx"
+)
+
+func TestFromCode(t *testing.T) {
+	tests := []struct {
+		code *httpx.ErrorJSONCode
+		want Code
+	}{
+		{code: httpx.ErrNotFound, want: NotFound},
+		{code: httpx.ErrUnauthorized, want: Unauthenticated},
+		{code: httpx.ErrCanceled, want: Canceled},
+		{code: httpx.ErrDataLoss, want: DataLoss},
+		{code: &httpx.ErrorJSONCode{Code: "UNMAPPED", Status: http.StatusPreconditionFailed}, want: FailedPrecondition},
+		{code: &httpx.ErrorJSONCode{Code: "UNMAPPED", Status: httpx.StatusClientClosedRequest}, want: Canceled},
+		{code: &httpx.ErrorJSONCode{Code: "UNMAPPED", Status: http.StatusBadGateway}, want: Unavailable},
+		{code: &httpx.ErrorJSONCode{Code: "UNMAPPED", Status: http.StatusInsufficientStorage}, want: Internal},
+		{code: &httpx.ErrorJSONCode{Code: "UNMAPPED", Status: http.StatusTeapot}, want: Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code.Code, func(t *testing.T) {
+			if got := FromCode(tt.code); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFromResponse(t *testing.T) {
+	if got := FromResponse(httpx.ErrAlreadyExists.JSON()); got != AlreadyExists {
+		t.Fatalf("expected already_exists, got %s", got)
+	}
+
+	if got := FromResponse(httpx.Code(http.StatusGatewayTimeout)); got != DeadlineExceeded {
+		t.Fatalf("expected deadline_exceeded, got %s", got)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := map[Code]*httpx.ErrorJSONCode{
+		Canceled:         httpx.ErrCanceled,
+		NotFound:         httpx.ErrNotFound,
+		AlreadyExists:    httpx.ErrAlreadyExists,
+		DeadlineExceeded: httpx.ErrTimeout,
+		Malformed:        httpx.ErrBadRequest,
+		BadRoute:         httpx.ErrNotFound,
+	}
+
+	for c, want := range tests {
+		if got := c.ErrorCode(); !errors.Is(got, want) {
+			t.Errorf("expected %s to be %s, got %s", c, want.Code, got.Code)
+		}
+
+		if c != Malformed && c != BadRoute && FromCode(want) != c {
+			t.Errorf("expected %s to map back to %s, got %s", want.Code, c, FromCode(want))
+		}
+	}
+
+	if Code("made_up").ErrorCode() != httpx.ErrUnknown {
+		t.Fatal("expected unknown codes to be httpx.ErrUnknown")
+	}
+}
+
+func TestStatus(t *testing.T) {
+	tests := map[Code]int{
+		Canceled:           httpx.StatusClientClosedRequest,
+		FailedPrecondition: http.StatusBadRequest,
+		DeadlineExceeded:   http.StatusGatewayTimeout,
+		BadRoute:           http.StatusNotFound,
+		Unauthenticated:    http.StatusUnauthorized,
+		DataLoss:           http.StatusInternalServerError,
+		Unknown:            http.StatusInternalServerError,
+	}
+
+	for c, want := range tests {
+		if got := c.Status(); got != want {
+			t.Errorf("expected %s to be %d, got %d", c, want, got)
+		}
+	}
+}
+
+func TestSharedCodes(t *testing.T) {
+	for _, code := range []*httpx.ErrorJSONCode{httpx.ErrCanceled, httpx.ErrUnknown, httpx.ErrAlreadyExists, httpx.ErrDataLoss} {
+		if registered, ok := httpx.LookupCode(code.Code); !ok || registered != code {
+			t.Fatalf("expected %s to be registered once by httpx", code.Code)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+module github.com/gabivlj/httpx/xgrpc
+
+go 1.23
+
+require (
+	github.com/gabivlj/httpx v0.0.0-00010101000000-000000000000
+	google.golang.org/grpc v1.70.0
+)
+
+require (
+	github.com/julienschmidt/httprouter v1.3.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20241202173237-19429a94021a // indirect
+	google.golang.org/protobuf v1.36.12 // indirect
+)
+
+replace github.com/gabivlj/httpx => ../
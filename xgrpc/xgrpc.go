@@ -0,0 +1,85 @@
+// package xgrpc converts the errors of downstream gRPC calls into httpx responses.
+package xgrpc
+
+import (
+	"errors"
+
+	"github.com/gabivlj/httpx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Debug makes the responses include the message of the gRPC status as extra. Messages
+// of downstream services can leak internal details, so it's disabled by default.
+var Debug = false
+
+// Codes maps each gRPC code to the code of its response, it can be modified to change the mapping.
+// Codes missing from the map are converted with httpx.ErrInternal.
+var Codes = map[codes.Code]*httpx.ErrorJSONCode{
+	codes.Canceled:           httpx.ErrCanceled,
+	codes.Unknown:            httpx.ErrUnknown,
+	codes.InvalidArgument:    httpx.ErrBadRequest,
+	codes.DeadlineExceeded:   httpx.ErrTimeout,
+	codes.NotFound:           httpx.ErrNotFound,
+	codes.AlreadyExists:      httpx.ErrAlreadyExists,
+	codes.PermissionDenied:   httpx.ErrForbidden,
+	codes.ResourceExhausted:  httpx.ErrTooManyRequests,
+	codes.FailedPrecondition: httpx.ErrFailedPrecondition,
+	codes.Aborted:            httpx.ErrAborted,
+	codes.OutOfRange:         httpx.ErrOutOfRange,
+	codes.Unimplemented:      httpx.ErrUnimplemented,
+	codes.Internal:           httpx.ErrInternal,
+	codes.Unavailable:        httpx.ErrServiceUnavailable,
+	codes.DataLoss:           httpx.ErrDataLoss,
+	codes.Unauthenticated:    httpx.ErrUnauthorized,
+}
+
+// Status returns the response of a gRPC status, or nil if the status is OK.
+func Status(s *status.Status) *httpx.Response {
+	if s.Code() == codes.OK {
+		return nil
+	}
+
+	code, ok := Codes[s.Code()]
+	if !ok {
+		code = httpx.ErrInternal
+	}
+
+	if Debug && s.Message() != "" {
+		return code.JSON(s.Message())
+	}
+
+	return code.JSON()
+}
+
+// FromError returns the response of err if it's a gRPC status error, even if it's wrapped.
+// Example:
+//
+//	user, err := users.GetUser(r.Context(), &pb.GetUserRequest{Id: id})
+//	if res, ok := xgrpc.FromError(err); ok {
+//		return res // 404 {"code": "NOT_FOUND"} when the user doesn't exist
+//	}
+func FromError(err error) (*httpx.Response, bool) {
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if err == nil || !errors.As(err, &grpcErr) {
+		return nil, false
+	}
+
+	res := Status(grpcErr.GRPCStatus())
+	return res, res != nil
+}
+
+// ErrorHandler returns a httpx.DefaultErrorHandler that converts gRPC errors returned by the
+// handlers and calls next with any other error.
+// Example:
+//
+//	httpx.DefaultErrorHandler = xgrpc.ErrorHandler(httpx.DefaultErrorHandler)
+func ErrorHandler(next func(err error) *httpx.Response) func(err error) *httpx.Response {
+	return func(err error) *httpx.Response {
+		if res, ok := FromError(err); ok {
+			return res
+		}
+
+		return next(err)
+	}
+}
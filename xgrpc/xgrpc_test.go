@@ -0,0 +1,73 @@
+package xgrpc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gabivlj/httpx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFromError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		ok     bool
+		code   string
+		status int
+	}{
+		{name: "nil", err: nil},
+		{name: "not a status", err: errors.New("boom")},
+		{name: "ok status", err: status.Error(codes.OK, "")},
+		{name: "not found", err: status.Error(codes.NotFound, "user 1"), ok: true, code: httpx.ErrNotFound.Code, status: http.StatusNotFound},
+		{name: "wrapped", err: fmt.Errorf("getting user: %w", status.Error(codes.AlreadyExists, "")), ok: true, code: httpx.ErrAlreadyExists.Code, status: http.StatusConflict},
+		{name: "canceled", err: status.Error(codes.Canceled, ""), ok: true, code: httpx.ErrCanceled.Code, status: httpx.StatusClientClosedRequest},
+		{name: "unmapped", err: status.Error(codes.Code(100), ""), ok: true, code: httpx.ErrInternal.Code, status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, ok := FromError(tt.err)
+			if ok != tt.ok {
+				t.Fatalf("expected ok to be %v", tt.ok)
+			}
+
+			if !ok {
+				return
+			}
+
+			if res.ErrorCode() != tt.code || res.Status() != tt.status {
+				t.Fatalf("expected %d %s, got %d %s", tt.status, tt.code, res.Status(), res.ErrorCode())
+			}
+
+			if res.ErrorExtra() != nil {
+				t.Fatalf("expected no extra without Debug, got %v", res.ErrorExtra())
+			}
+		})
+	}
+}
+
+func TestDebug(t *testing.T) {
+	Debug = true
+	defer func() { Debug = false }()
+
+	res := Status(status.New(codes.PermissionDenied, "not an admin"))
+	if res.ErrorCode() != httpx.ErrForbidden.Code || res.ErrorExtra() != "not an admin" {
+		t.Fatalf("expected a FORBIDDEN with the message, got %s %v", res.ErrorCode(), res.ErrorExtra())
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	next := func(err error) *httpx.Response { return httpx.ErrBadRequest.JSON() }
+	handler := ErrorHandler(next)
+	if res := handler(status.Error(codes.Unavailable, "")); res.ErrorCode() != httpx.ErrServiceUnavailable.Code {
+		t.Fatalf("expected SERVICE_UNAVAILABLE, got %s", res.ErrorCode())
+	}
+
+	if res := handler(errors.New("boom")); res.ErrorCode() != httpx.ErrBadRequest.Code {
+		t.Fatalf("expected other errors to reach next, got %s", res.ErrorCode())
+	}
+}
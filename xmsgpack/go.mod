@@ -0,0 +1,15 @@
+module github.com/gabivlj/httpx/xmsgpack
+
+go 1.23
+
+require (
+	github.com/gabivlj/httpx v0.0.0-00010101000000-000000000000
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+)
+
+require (
+	github.com/julienschmidt/httprouter v1.3.0 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+)
+
+replace github.com/gabivlj/httpx => ../
@@ -0,0 +1,30 @@
+// package xmsgpack encodes httpx responses with MessagePack.
+package xmsgpack
+
+import (
+	"io"
+
+	"github.com/gabivlj/httpx"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ContentType is the content type of MessagePack bodies
+const ContentType = "application/msgpack"
+
+// MsgPack returns a body encoder for v, use it with httpx.Response.Encode.
+// Example:
+//
+//	return httpx.Code(http.StatusOK).Encode(xmsgpack.MsgPack(v))
+func MsgPack(v any) httpx.BodyEncoder {
+	return encoder{v}
+}
+
+type encoder struct {
+	v any
+}
+
+func (e encoder) ContentType() string { return ContentType }
+
+func (e encoder) Encode(w io.Writer) error {
+	return msgpack.NewEncoder(w).Encode(e.v)
+}
@@ -0,0 +1,53 @@
+package xmsgpack
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gabivlj/httpx"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type user struct {
+	ID   int      `msgpack:"id"`
+	Name string   `msgpack:"name"`
+	Tags []string `msgpack:"tags"`
+}
+
+func TestMsgPack(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		into any
+	}{
+		{name: "struct", v: user{ID: 1, Name: "al", Tags: []string{"a"}}, into: &user{}},
+		{name: "map", v: map[string]int{"a": 1}, into: &map[string]int{}},
+		{name: "bytes", v: []byte{0, 1, 2}, into: &[]byte{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpx.Code(http.StatusOK).Encode(MsgPack(tt.v)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != http.StatusOK || w.Header().Get("Content-Type") != ContentType {
+				t.Fatalf("expected a 200 %s, got %d %s", ContentType, w.Code, w.Header().Get("Content-Type"))
+			}
+
+			if err := msgpack.Unmarshal(w.Body.Bytes(), tt.into); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := reflect.ValueOf(tt.into).Elem().Interface(); !reflect.DeepEqual(got, tt.v) {
+				t.Fatalf("expected %v, got %v", tt.v, got)
+			}
+		})
+	}
+}
+
+func TestMsgPackUnencodable(t *testing.T) {
+	if _, err := httpx.Code(http.StatusOK).Encode(MsgPack(make(chan int))).Body(); err == nil {
+		t.Fatal("expected an error")
+	}
+}
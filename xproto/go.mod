@@ -0,0 +1,12 @@
+module github.com/gabivlj/httpx/xproto
+
+go 1.23
+
+require (
+	github.com/gabivlj/httpx v0.0.0-00010101000000-000000000000
+	google.golang.org/protobuf v1.36.12
+)
+
+require github.com/julienschmidt/httprouter v1.3.0 // indirect
+
+replace github.com/gabivlj/httpx => ../
@@ -0,0 +1,77 @@
+// package xproto encodes httpx responses with Protocol Buffers.
+package xproto
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gabivlj/httpx"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ContentType is the content type of protobuf bodies
+const ContentType = "application/x-protobuf"
+
+// Proto returns a body encoder for m in the protobuf binary format, use it with httpx.Response.Encode.
+// Example:
+//
+//	return httpx.Code(http.StatusOK).Encode(xproto.Proto(user))
+func Proto(m proto.Message) httpx.BodyEncoder {
+	return protoEncoder{m}
+}
+
+// JSON returns a body encoder for m in the protobuf JSON format
+func JSON(m proto.Message) httpx.BodyEncoder {
+	return jsonEncoder{m}
+}
+
+// Negotiate returns the encoder for m that the Accept header of the request prefers, protobuf
+// or JSON, so gateways can serve both. JSON is used when the client accepts neither.
+// Responses encoded with it get Accept added to their Vary header.
+func Negotiate(r *http.Request, m proto.Message) httpx.BodyEncoder {
+	if httpx.Negotiate(r, "application/json", ContentType) == ContentType {
+		return negotiated{Proto(m)}
+	}
+
+	return negotiated{JSON(m)}
+}
+
+// negotiated is an encoder chosen from the Accept header
+type negotiated struct {
+	httpx.BodyEncoder
+}
+
+func (negotiated) Vary() []string { return []string{"Accept"} }
+
+type protoEncoder struct {
+	m proto.Message
+}
+
+func (e protoEncoder) ContentType() string { return ContentType }
+
+func (e protoEncoder) Encode(w io.Writer) error {
+	b, err := proto.Marshal(e.m)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+type jsonEncoder struct {
+	m proto.Message
+}
+
+func (e jsonEncoder) ContentType() string { return "application/json" }
+
+func (e jsonEncoder) Encode(w io.Writer) error {
+	b, err := protojson.Marshal(e.m)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
@@ -0,0 +1,71 @@
+package xproto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gabivlj/httpx"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestNegotiate(t *testing.T) {
+	msg := wrapperspb.String("al")
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+	}{
+		{name: "protobuf", accept: ContentType, contentType: ContentType},
+		{name: "json", accept: "application/json", contentType: "application/json"},
+		{name: "preferred protobuf", accept: "application/json;q=0.5, application/x-protobuf", contentType: ContentType},
+		{name: "no accept", contentType: "application/json"},
+		{name: "neither", accept: "text/html", contentType: "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+
+			w := httptest.NewRecorder()
+			httpx.Code(http.StatusOK).Encode(Negotiate(r, msg)).ServeHTTP(w, r)
+			if w.Header().Get("Content-Type") != tt.contentType || w.Header().Get("Vary") != "Accept" {
+				t.Fatalf("expected %s varying by Accept, got %v", tt.contentType, w.Header())
+			}
+
+			got := &wrapperspb.StringValue{}
+			unmarshal := protojson.Unmarshal
+			if tt.contentType == ContentType {
+				unmarshal = proto.Unmarshal
+			}
+
+			if err := unmarshal(w.Body.Bytes(), got); err != nil || !proto.Equal(got, msg) {
+				t.Fatalf("expected %v, got %v, %v", msg, got, err)
+			}
+		})
+	}
+}
+
+func TestEncoders(t *testing.T) {
+	msg := wrapperspb.Int64(42)
+	for name, tt := range map[string]struct {
+		enc         httpx.BodyEncoder
+		contentType string
+		body        string
+	}{
+		"proto": {enc: Proto(msg), contentType: ContentType, body: "\x08\x2a"},
+		"json":  {enc: JSON(msg), contentType: "application/json", body: `"42"`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			body, err := httpx.Code(http.StatusOK).Encode(tt.enc).Body()
+			if err != nil || string(body) != tt.body || tt.enc.ContentType() != tt.contentType {
+				t.Fatalf("expected %q as %s, got %q, %v", tt.body, tt.contentType, body, err)
+			}
+		})
+	}
+}
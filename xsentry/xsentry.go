@@ -0,0 +1,151 @@
+// package xsentry reports the 5xx responses and panics of httpx handlers to error trackers like Sentry.
+package xsentry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gabivlj/httpx"
+)
+
+const panicKey = "xsentry.panic"
+
+// Report is a 5xx response or a panic of a handler
+type Report struct {
+	Request   *http.Request
+	Route     string
+	RequestID string
+	Status    int
+	// Code is the error code of the response, if it has one
+	Code string
+	// Err is the error returned by the handler, nil for panics
+	Err error
+	// Causes is the chain of errors wrapped by Err, starting with Err
+	Causes []error
+	// Panic is the value the handler panicked with and Stack where, set when Recover caught a panic
+	Panic any
+	Stack []byte
+}
+
+// Reporter sends reports to an error tracker, it shouldn't block the request for long.
+type Reporter interface {
+	Report(ctx context.Context, report Report)
+}
+
+// ReporterFunc adapts a function to a Reporter.
+// Example:
+//
+//	xsentry.ReporterFunc(func(ctx context.Context, report xsentry.Report) {
+//		hub := sentry.GetHubFromContext(ctx)
+//		hub.WithScope(func(scope *sentry.Scope) {
+//			scope.SetRequest(report.Request)
+//			scope.SetTag("route", report.Route)
+//			scope.SetTag("code", report.Code)
+//			if report.Panic != nil {
+//				hub.RecoverWithContext(ctx, report.Panic)
+//				return
+//			}
+//
+//			hub.CaptureException(report.Err)
+//		})
+//	})
+type ReporterFunc func(ctx context.Context, report Report)
+
+// Report calls f
+func (f ReporterFunc) Report(ctx context.Context, report Report) {
+	f(ctx, report)
+}
+
+// Hook returns an after hook that reports the 5xx responses to rep, including the ones of the panics
+// caught by Recover.
+// Example:
+//
+//	httpx.OnAfter(xsentry.Hook(reporter))
+//	mux := httpx.NewMux(httpx.WithMiddleware(xsentry.Recover))
+func Hook(rep Reporter) httpx.AfterFunc {
+	return func(w http.ResponseWriter, r *http.Request, info httpx.AfterInfo) {
+		if info.Status < http.StatusInternalServerError {
+			return
+		}
+
+		report := Report{
+			Request:   r,
+			Route:     httpx.Route(r),
+			RequestID: httpx.RequestID(r),
+			Status:    info.Status,
+			Err:       info.Err,
+			Causes:    causes(info.Err),
+		}
+
+		if info.Response != nil {
+			report.Code = info.Response.ErrorCode()
+		}
+
+		if p, ok := httpx.Get[*recovered](r, panicKey); ok {
+			report.Err, report.Causes = nil, nil
+			report.Panic, report.Stack = p.value, p.stack
+		}
+
+		rep.Report(r.Context(), report)
+	}
+}
+
+type recovered struct {
+	value any
+	stack []byte
+}
+
+// Recover is a middleware that turns the panics of the handlers into httpx.ErrInternal responses,
+// keeping the panic for Hook. http.ErrAbortHandler is still panicked, to abort the response.
+func Recover(next httpx.Handler) httpx.Handler {
+	return func(w http.ResponseWriter, r *http.Request) (err error) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+
+			httpx.Set(r, panicKey, &recovered{value: p, stack: debug.Stack()})
+			err = httpx.InternalServerError()
+		}()
+
+		return next(w, r)
+	}
+}
+
+// causes returns err and the errors it wraps, depth first
+func causes(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	chain := []error{err}
+	switch wrapped := err.(type) {
+	case interface{ Unwrap() error }:
+		chain = append(chain, causes(wrapped.Unwrap())...)
+	case interface{ Unwrap() []error }:
+		for _, e := range wrapped.Unwrap() {
+			chain = append(chain, causes(e)...)
+		}
+	}
+
+	return chain
+}
+
+// String describes the report in a line, for reporters that log it
+func (r Report) String() string {
+	switch {
+	case r.Panic != nil:
+		return fmt.Sprintf("%s %s: panic: %v", r.Request.Method, r.Route, r.Panic)
+	case r.Err != nil:
+		return fmt.Sprintf("%s %s: %d %s: %v", r.Request.Method, r.Route, r.Status, r.Code, r.Err)
+	}
+
+	return fmt.Sprintf("%s %s: %d %s", r.Request.Method, r.Route, r.Status, r.Code)
+}
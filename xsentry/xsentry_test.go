@@ -0,0 +1,116 @@
+package xsentry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gabivlj/httpx"
+)
+
+var errDB = errors.New("connection refused")
+
+func TestHook(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler httpx.Handler
+		status  int
+		report  bool
+		code    string
+		causes  int
+		panic   any
+	}{
+		{name: "success", handler: func(w http.ResponseWriter, r *http.Request) error {
+			return httpx.Code(http.StatusOK)
+		}, status: http.StatusOK},
+		{name: "client error", handler: func(w http.ResponseWriter, r *http.Request) error {
+			return httpx.ErrNotFound.JSON()
+		}, status: http.StatusNotFound},
+		{name: "server error", handler: func(w http.ResponseWriter, r *http.Request) error {
+			return httpx.ErrServiceUnavailable.JSON()
+		}, status: http.StatusServiceUnavailable, report: true, code: httpx.ErrServiceUnavailable.Code, causes: 1},
+		{name: "wrapped errors", handler: func(w http.ResponseWriter, r *http.Request) error {
+			return fmt.Errorf("saving the user: %w", errors.Join(httpx.ErrInternal, errDB))
+		}, status: http.StatusInternalServerError, report: true, code: httpx.ErrMultipleErrors.Code, causes: 4},
+		{name: "panic", handler: func(w http.ResponseWriter, r *http.Request) error {
+			panic("boom")
+		}, status: http.StatusInternalServerError, report: true, code: httpx.ErrInternal.Code, panic: "boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reports []Report
+			rep := ReporterFunc(func(ctx context.Context, report Report) { reports = append(reports, report) })
+			mux := httpx.NewMux(httpx.WithAfter(Hook(rep)), httpx.WithMiddleware(Recover))
+			mux.GET("/users/{id}", tt.handler)
+
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+			if w.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, w.Code)
+			}
+
+			if !tt.report {
+				if len(reports) != 0 {
+					t.Fatalf("expected no report, got %+v", reports)
+				}
+
+				return
+			}
+
+			if len(reports) != 1 {
+				t.Fatalf("expected a report, got %d", len(reports))
+			}
+
+			report := reports[0]
+			if report.Route != "/users/{id}" || report.Status != tt.status || report.Code != tt.code || len(report.Causes) != tt.causes {
+				t.Fatalf("expected %d %s with %d causes, got %+v", tt.status, tt.code, tt.causes, report)
+			}
+
+			if tt.panic != nil {
+				if report.Panic != tt.panic || len(report.Stack) == 0 || report.Err != nil {
+					t.Fatalf("expected the panic and its stack, got %+v", report)
+				}
+
+				if !strings.Contains(report.String(), "panic: boom") {
+					t.Fatalf("expected the panic in the description, got %q", report.String())
+				}
+
+				return
+			}
+
+			if !errors.Is(report.Err, report.Causes[len(report.Causes)-1]) || !strings.Contains(report.String(), tt.code) {
+				t.Fatalf("expected the error and its causes, got %+v", report)
+			}
+		})
+	}
+}
+
+func TestRecoverRepanicsAbort(t *testing.T) {
+	defer func() {
+		if p := recover(); p != http.ErrAbortHandler {
+			t.Fatalf("expected http.ErrAbortHandler to be panicked again, got %v", p)
+		}
+	}()
+
+	Recover(func(w http.ResponseWriter, r *http.Request) error {
+		panic(http.ErrAbortHandler)
+	})(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestCauses(t *testing.T) {
+	inner := errors.New("inner")
+	err := fmt.Errorf("outer: %w", errors.Join(inner, errDB))
+	got := causes(err)
+	if len(got) != 4 || got[0] != err || got[2] != inner || got[3] != errDB {
+		t.Fatalf("expected the chain depth first, got %v", got)
+	}
+
+	if causes(nil) != nil {
+		t.Fatal("expected no causes for nil")
+	}
+}
@@ -0,0 +1,61 @@
+// package xws adapts websocket upgrades to httpx handlers.
+package xws
+
+import (
+	"net/http"
+
+	"github.com/gabivlj/httpx"
+)
+
+// Upgrader upgrades a request to a websocket connection C, like the *websocket.Upgrader of gorilla/websocket.
+type Upgrader[C any] interface {
+	Upgrade(w http.ResponseWriter, r *http.Request, responseHeader http.Header) (C, error)
+}
+
+// UpgradeFunc adapts a function to an Upgrader, for libraries that upgrade with a function.
+// Example:
+//
+//	xws.UpgradeFunc[*websocket.Conn](func(w http.ResponseWriter, r *http.Request, _ http.Header) (*websocket.Conn, error) {
+//		return websocket.Accept(w, r, nil)
+//	})
+type UpgradeFunc[C any] func(w http.ResponseWriter, r *http.Request, responseHeader http.Header) (C, error)
+
+// Upgrade calls f
+func (f UpgradeFunc[C]) Upgrade(w http.ResponseWriter, r *http.Request, responseHeader http.Header) (C, error) {
+	return f(w, r, responseHeader)
+}
+
+// Config configures a websocket handler
+type Config[C any] struct {
+	// Upgrader upgrades the requests that pass Check
+	Upgrader Upgrader[C]
+	// Check validates the request before the upgrade, like its authentication. Its errors are
+	// returned as normal httpx errors, so the client gets them as JSON.
+	Check func(r *http.Request) error
+	// Header is sent with the response of the upgrade
+	Header http.Header
+}
+
+// Handler returns an httpx handler that upgrades the request and calls handle with the connection.
+// Errors before the upgrade go through the normal pipeline, errors of handle can't be written to
+// the hijacked connection, so they're passed to httpx.AlreadyWrittenHandler and the after middleware.
+// Failed upgrades are answered by the Upgrader itself.
+// Example:
+//
+//	mux.GET("/ws", xws.Handler(xws.Config[*websocket.Conn]{Upgrader: &websocket.Upgrader{}, Check: auth}, chat))
+func Handler[C any](cfg Config[C], handle func(r *http.Request, conn C) error) httpx.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if cfg.Check != nil {
+			if err := cfg.Check(r); err != nil {
+				return err
+			}
+		}
+
+		conn, err := cfg.Upgrader.Upgrade(w, r, cfg.Header)
+		if err != nil {
+			return err
+		}
+
+		return handle(r, conn)
+	}
+}
@@ -0,0 +1,81 @@
+package xws
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gabivlj/httpx"
+)
+
+var errClosed = errors.New("connection closed")
+
+// upgrader switches protocols like a websocket library, the connection is the request path
+var upgrader = UpgradeFunc[string](func(w http.ResponseWriter, r *http.Request, header http.Header) (string, error) {
+	if r.Header.Get("Upgrade") != "websocket" {
+		return "", httpx.ErrBadRequest.JSON("not a websocket handshake")
+	}
+
+	for key, values := range header {
+		w.Header()[key] = values
+	}
+
+	w.WriteHeader(http.StatusSwitchingProtocols)
+	return r.URL.Path, nil
+})
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		check    func(r *http.Request) error
+		upgrade  bool
+		err      error
+		status   int
+		conn     string
+		afterErr error
+	}{
+		{name: "upgraded", upgrade: true, status: http.StatusSwitchingProtocols, conn: "/ws"},
+		{name: "rejected by check", check: func(r *http.Request) error { return httpx.ErrUnauthorized.JSON() }, upgrade: true, status: http.StatusUnauthorized},
+		{name: "failed upgrade", status: http.StatusBadRequest},
+		{name: "error after the upgrade", upgrade: true, err: errClosed, status: http.StatusSwitchingProtocols, conn: "/ws", afterErr: errClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var conn string
+			var afterErr error
+			cfg := Config[string]{Upgrader: upgrader, Check: tt.check, Header: http.Header{"Sec-Websocket-Protocol": {"chat"}}}
+			mux := httpx.NewMux(httpx.WithAfter(func(w http.ResponseWriter, r *http.Request, info httpx.AfterInfo) {
+				afterErr = info.Err
+			}))
+
+			mux.GET("/ws", Handler(cfg, func(r *http.Request, c string) error {
+				conn = c
+				return tt.err
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+			if tt.upgrade {
+				r.Header.Set("Upgrade", "websocket")
+			}
+
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+			if w.Code != tt.status || conn != tt.conn {
+				t.Fatalf("expected %d with connection %q, got %d with %q", tt.status, tt.conn, w.Code, conn)
+			}
+
+			if tt.status == http.StatusSwitchingProtocols {
+				if w.Header().Get("Sec-Websocket-Protocol") != "chat" || strings.Contains(w.Body.String(), "code") {
+					t.Fatalf("expected the upgrade header and no error body, got %v %q", w.Header(), w.Body.String())
+				}
+
+				if afterErr != tt.afterErr {
+					t.Fatalf("expected the after hooks to get %v, got %v", tt.afterErr, afterErr)
+				}
+			}
+		})
+	}
+}
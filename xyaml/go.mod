@@ -0,0 +1,12 @@
+module github.com/gabivlj/httpx/xyaml
+
+go 1.23
+
+require (
+	github.com/gabivlj/httpx v0.0.0-00010101000000-000000000000
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require github.com/julienschmidt/httprouter v1.3.0 // indirect
+
+replace github.com/gabivlj/httpx => ../
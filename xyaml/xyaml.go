@@ -0,0 +1,44 @@
+// package xyaml encodes httpx responses with YAML.
+package xyaml
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gabivlj/httpx"
+	"gopkg.in/yaml.v3"
+)
+
+// ContentType is the content type of YAML bodies
+const ContentType = "application/yaml"
+
+// YAML returns a body encoder for v, use it with httpx.Response.Encode.
+// Like JSON, v is encoded when the body is copied.
+// Example:
+//
+//	return httpx.Code(http.StatusOK).Encode(xyaml.YAML(config))
+func YAML(v any) httpx.BodyEncoder {
+	return encoder{v}
+}
+
+type encoder struct {
+	v any
+}
+
+func (e encoder) ContentType() string { return ContentType }
+
+func (e encoder) Encode(w io.Writer) (err error) {
+	// yaml.v3 panics instead of failing with the values it can't encode, like funcs and channels
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("xyaml: %v", p)
+		}
+	}()
+
+	enc := yaml.NewEncoder(w)
+	if err := enc.Encode(e.v); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
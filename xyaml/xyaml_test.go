@@ -0,0 +1,48 @@
+package xyaml
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gabivlj/httpx"
+)
+
+type config struct {
+	Name    string   `yaml:"name"`
+	Ports   []int    `yaml:"ports"`
+	Debug   bool     `yaml:"debug,omitempty"`
+	Aliases []string `yaml:"aliases,omitempty"`
+}
+
+func TestYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{name: "struct", v: config{Name: "api", Ports: []int{80, 443}}, want: "name: api\nports:\n    - 80\n    - 443\n"},
+		{name: "map", v: map[string]string{"b": "2", "a": "1"}, want: "a: \"1\"\nb: \"2\"\n"},
+		{name: "multiline string", v: "a\nb", want: "|-\n    a\n    b\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpx.Code(http.StatusOK).Encode(YAML(tt.v)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != http.StatusOK || w.Header().Get("Content-Type") != ContentType {
+				t.Fatalf("expected a 200 %s, got %d %s", ContentType, w.Code, w.Header().Get("Content-Type"))
+			}
+
+			if w.Body.String() != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestYAMLUnencodable(t *testing.T) {
+	if _, err := httpx.Code(http.StatusOK).Encode(YAML(map[string]any{"a": make(chan int)})).Body(); err == nil {
+		t.Fatal("expected an error")
+	}
+}